@@ -0,0 +1,80 @@
+package apikey
+
+import "time"
+
+type Builder struct {
+	key *Entity
+}
+
+func NewApiKeyBuilder() *Builder {
+	return &Builder{
+		key: &Entity{
+			createdAt: time.Now(),
+			mode:      ModeTest,
+		},
+	}
+}
+
+func (b *Builder) WithId(id int64) *Builder {
+	b.key.SetId(id)
+	return b
+}
+
+func (b *Builder) WithMerchantId(merchantId int64) *Builder {
+	b.key.SetMerchantId(merchantId)
+	return b
+}
+
+func (b *Builder) WithName(name string) *Builder {
+	b.key.SetName(name)
+	return b
+}
+
+func (b *Builder) WithPrefix(prefix string) *Builder {
+	b.key.SetPrefix(prefix)
+	return b
+}
+
+func (b *Builder) WithHash(hash string) *Builder {
+	b.key.SetHash(hash)
+	return b
+}
+
+func (b *Builder) WithSigningSecret(secret string) *Builder {
+	b.key.SetSigningSecret(secret)
+	return b
+}
+
+func (b *Builder) WithScopes(scopes ...string) *Builder {
+	b.key.SetScopes(scopes)
+	return b
+}
+
+func (b *Builder) WithMode(mode string) *Builder {
+	b.key.SetMode(mode)
+	return b
+}
+
+func (b *Builder) WithLastUsedAt(at time.Time) *Builder {
+	b.key.SetLastUsedAt(at)
+	return b
+}
+
+func (b *Builder) WithRevokedAt(at time.Time) *Builder {
+	b.key.SetRevokedAt(at)
+	return b
+}
+
+func (b *Builder) WithCreatedAt(at time.Time) *Builder {
+	b.key.SetCreatedAt(at)
+	return b
+}
+
+func (b *Builder) WithUpdatedAt(at time.Time) *Builder {
+	b.key.SetUpdatedAt(at)
+	return b
+}
+
+func (b *Builder) Build() *Entity {
+	return b.key
+}
@@ -0,0 +1,52 @@
+package apikey_test
+
+import (
+	"payment-gateway/cmd/domain/apikey"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApiKeyBuilder(t *testing.T) {
+	t.Run("should create new builder with empty api key", func(t *testing.T) {
+		b := apikey.NewApiKeyBuilder()
+		assert.NotNil(t, b)
+		assert.NotNil(t, b.Build())
+		assert.Equal(t, "test", b.Build().Mode())
+	})
+}
+
+func TestBuilderMethods(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should build api key with all fields set", func(t *testing.T) {
+		k := apikey.NewApiKeyBuilder().
+			WithId(1).
+			WithMerchantId(2).
+			WithName("backend").
+			WithPrefix("abc123").
+			WithHash("hash").
+			WithSigningSecret("secret").
+			WithScopes("read", "write").
+			WithMode("live").
+			WithLastUsedAt(now).
+			WithRevokedAt(now).
+			WithCreatedAt(now).
+			WithUpdatedAt(now).
+			Build()
+
+		assert.Equal(t, int64(1), k.Id())
+		assert.Equal(t, int64(2), k.MerchantId())
+		assert.Equal(t, "backend", k.Name())
+		assert.Equal(t, "abc123", k.Prefix())
+		assert.Equal(t, "hash", k.Hash())
+		assert.Equal(t, "secret", k.SigningSecret())
+		assert.Equal(t, []string{"read", "write"}, k.Scopes())
+		assert.Equal(t, "live", k.Mode())
+		assert.Equal(t, now, k.LastUsedAt())
+		assert.Equal(t, now, k.RevokedAt())
+		assert.Equal(t, now, k.CreatedAt())
+		assert.Equal(t, now, k.UpdatedAt())
+	})
+}
@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"context"
+	exceptions "payment-gateway/cmd/domain/err"
+	"time"
+)
+
+var ErrNotFound = exceptions.NewDomainError(exceptions.CodeApiKeyNotFound, "Api key not found")
+
+type Dao interface {
+	Insert(ctx context.Context, key *Entity) (*Entity, error)
+	FindById(ctx context.Context, id int64) (*Entity, error)
+	FindByHash(ctx context.Context, hash string) (*Entity, error)
+	FindByPrefix(ctx context.Context, prefix string) (*Entity, error)
+	FindByMerchantId(ctx context.Context, merchantId int64) ([]Entity, error)
+	Update(ctx context.Context, key *Entity) (*Entity, error)
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+type NonceDao interface {
+	Register(ctx context.Context, keyId int64, nonce string, expiresAt time.Time) (bool, error)
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
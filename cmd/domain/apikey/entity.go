@@ -0,0 +1,275 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"payment-gateway/cmd/domain/err"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+
+	ModeLive = "live"
+	ModeTest = "test"
+
+	tokenPrefix         = "pgw_"
+	signingSecretPrefix = "pgw_sig_"
+	secretBytes         = 24
+	publicPrefixLen     = 12
+
+	// lastUsedPrecision is how stale last_used_at may get before a request
+	// records its use again, so busy keys do not write on every request.
+	lastUsedPrecision = time.Minute
+
+	errInvalidScope = "Invalid api key scope"
+	errInvalidMode  = "Invalid api key mode"
+	errEmptyScopes  = "Api key must have at least one scope"
+	errKeyRevoked   = "Api key is already revoked"
+)
+
+var validScopes = map[string]bool{
+	ScopeRead:  true,
+	ScopeWrite: true,
+	ScopeAdmin: true,
+}
+
+var validModes = map[string]bool{
+	ModeLive: true,
+	ModeTest: true,
+}
+
+type Entity struct {
+	id            int64
+	merchantId    int64
+	name          string
+	prefix        string
+	hash          string
+	signingSecret string
+	scopes        []string
+	mode          string
+
+	lastUsedAt time.Time
+	revokedAt  time.Time
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+func NewApiKey(merchantId int64, name string, scopes []string, mode string) (*Entity, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", exceptions.NewDomainError(exceptions.CodeInvalidScope, errEmptyScopes)
+	}
+
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			return nil, "", exceptions.NewDomainError(exceptions.CodeInvalidScope, errInvalidScope)
+		}
+	}
+
+	if !validModes[mode] {
+		return nil, "", exceptions.NewDomainError(exceptions.CodeInvalidMode, errInvalidMode)
+	}
+
+	key := &Entity{
+		merchantId: merchantId,
+		name:       name,
+		scopes:     scopes,
+		mode:       mode,
+		createdAt:  time.Now(),
+		updatedAt:  time.Now(),
+	}
+
+	token, err := key.generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
+
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *Entity) generate() (string, error) {
+	encoded, err := randomHex()
+	if err != nil {
+		return "", err
+	}
+
+	signing, err := randomHex()
+	if err != nil {
+		return "", err
+	}
+
+	token := tokenPrefix + k.mode + "_" + encoded
+
+	k.prefix = encoded[:publicPrefixLen]
+	k.hash = Hash(token)
+	k.signingSecret = signingSecretPrefix + signing
+
+	return token, nil
+}
+
+func randomHex() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func (k *Entity) Rotate() (string, error) {
+	if k.IsRevoked() {
+		return "", exceptions.NewDomainError(exceptions.CodeApiKeyRevoked, errKeyRevoked)
+	}
+
+	token, err := k.generate()
+	if err != nil {
+		return "", err
+	}
+	k.updatedAt = time.Now()
+
+	return token, nil
+}
+
+func (k *Entity) Revoke() error {
+	if k.IsRevoked() {
+		return exceptions.NewDomainError(exceptions.CodeApiKeyRevoked, errKeyRevoked)
+	}
+
+	k.revokedAt = time.Now()
+	k.updatedAt = time.Now()
+
+	return nil
+}
+
+func (k *Entity) IsRevoked() bool {
+	return !k.revokedAt.IsZero()
+}
+
+func (k *Entity) HasScope(scope string) bool {
+	for _, s := range k.scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UseIsStale reports whether last_used_at, at now, is older than the
+// precision it is kept at and should be recorded again.
+func (k *Entity) UseIsStale(now time.Time) bool {
+	return now.Sub(k.lastUsedAt) >= lastUsedPrecision
+}
+
+func (k *Entity) Id() int64 {
+	return k.id
+}
+
+func (k *Entity) MerchantId() int64 {
+	return k.merchantId
+}
+
+func (k *Entity) Name() string {
+	return k.name
+}
+
+func (k *Entity) Prefix() string {
+	return k.prefix
+}
+
+func (k *Entity) Hash() string {
+	return k.hash
+}
+
+func (k *Entity) SigningSecret() string {
+	return k.signingSecret
+}
+
+func (k *Entity) Scopes() []string {
+	return k.scopes
+}
+
+func (k *Entity) Mode() string {
+	return k.mode
+}
+
+func (k *Entity) LastUsedAt() time.Time {
+	return k.lastUsedAt
+}
+
+func (k *Entity) RevokedAt() time.Time {
+	return k.revokedAt
+}
+
+func (k *Entity) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+func (k *Entity) UpdatedAt() time.Time {
+	return k.updatedAt
+}
+
+func (k *Entity) SetId(id int64) {
+	k.id = id
+}
+
+func (k *Entity) SetMerchantId(merchantId int64) {
+	k.merchantId = merchantId
+	k.updatedAt = time.Now()
+}
+
+func (k *Entity) SetName(name string) {
+	k.name = name
+	k.updatedAt = time.Now()
+}
+
+func (k *Entity) SetPrefix(prefix string) {
+	k.prefix = prefix
+}
+
+func (k *Entity) SetHash(hash string) {
+	k.hash = hash
+}
+
+func (k *Entity) SetSigningSecret(secret string) {
+	k.signingSecret = secret
+}
+
+func (k *Entity) SetScopes(scopes []string) {
+	k.scopes = scopes
+	k.updatedAt = time.Now()
+}
+
+func (k *Entity) ScopesString() string {
+	return strings.Join(k.scopes, ",")
+}
+
+func (k *Entity) SetMode(mode string) {
+	k.mode = mode
+	k.updatedAt = time.Now()
+}
+
+func (k *Entity) SetLastUsedAt(at time.Time) {
+	k.lastUsedAt = at
+}
+
+func (k *Entity) SetRevokedAt(at time.Time) {
+	k.revokedAt = at
+}
+
+func (k *Entity) SetCreatedAt(at time.Time) {
+	k.createdAt = at
+}
+
+func (k *Entity) SetUpdatedAt(at time.Time) {
+	k.updatedAt = at
+}
@@ -0,0 +1,160 @@
+package apikey_test
+
+import (
+	"payment-gateway/cmd/domain/apikey"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApiKey(t *testing.T) {
+	t.Run("should create api key with hashed token", func(t *testing.T) {
+		key, token, err := apikey.NewApiKey(1, "backend", []string{"read", "write"}, "live")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, "pgw_live_"))
+		assert.Equal(t, apikey.Hash(token), key.Hash())
+		assert.NotContains(t, key.Hash(), token)
+		assert.Len(t, key.Prefix(), 12)
+		assert.True(t, strings.HasPrefix(key.SigningSecret(), "pgw_sig_"))
+		assert.NotContains(t, key.SigningSecret(), token)
+		assert.True(t, strings.HasPrefix(token, "pgw_live_"+key.Prefix()))
+		assert.Equal(t, int64(1), key.MerchantId())
+		assert.Equal(t, "backend", key.Name())
+		assert.Equal(t, "live", key.Mode())
+		assert.Equal(t, "read,write", key.ScopesString())
+		assert.False(t, key.IsRevoked())
+	})
+
+	t.Run("should generate different tokens for each key", func(t *testing.T) {
+		_, first, _ := apikey.NewApiKey(1, "first", []string{"read"}, "test")
+		_, second, _ := apikey.NewApiKey(1, "second", []string{"read"}, "test")
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("should not create api key without scopes", func(t *testing.T) {
+		key, token, err := apikey.NewApiKey(1, "backend", nil, "live")
+
+		assert.Equal(t, "Api key must have at least one scope", err.Error())
+		assert.Nil(t, key)
+		assert.Empty(t, token)
+	})
+
+	t.Run("should not create api key with invalid scope", func(t *testing.T) {
+		key, _, err := apikey.NewApiKey(1, "backend", []string{"root"}, "live")
+
+		assert.Equal(t, "Invalid api key scope", err.Error())
+		assert.Nil(t, key)
+	})
+
+	t.Run("should not create api key with invalid mode", func(t *testing.T) {
+		key, _, err := apikey.NewApiKey(1, "backend", []string{"read"}, "sandbox")
+
+		assert.Equal(t, "Invalid api key mode", err.Error())
+		assert.Nil(t, key)
+	})
+}
+
+func TestEntityRotate(t *testing.T) {
+	t.Run("should replace hash and prefix", func(t *testing.T) {
+		key, token, _ := apikey.NewApiKey(1, "backend", []string{"read"}, "test")
+		oldHash := key.Hash()
+		oldSecret := key.SigningSecret()
+
+		newToken, err := key.Rotate()
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, oldSecret, key.SigningSecret())
+		assert.NotEqual(t, token, newToken)
+		assert.NotEqual(t, oldHash, key.Hash())
+		assert.Equal(t, apikey.Hash(newToken), key.Hash())
+	})
+
+	t.Run("should not rotate revoked key", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().WithRevokedAt(time.Now()).Build()
+
+		token, err := key.Rotate()
+
+		assert.Equal(t, "Api key is already revoked", err.Error())
+		assert.Empty(t, token)
+	})
+}
+
+func TestEntityRevoke(t *testing.T) {
+	key := apikey.NewApiKeyBuilder().WithId(1).Build()
+
+	t.Run("should revoke key", func(t *testing.T) {
+		err := key.Revoke()
+
+		assert.NoError(t, err)
+		assert.True(t, key.IsRevoked())
+		assert.NotZero(t, key.RevokedAt())
+	})
+
+	t.Run("should not revoke key twice", func(t *testing.T) {
+		err := key.Revoke()
+
+		assert.Equal(t, "Api key is already revoked", err.Error())
+	})
+}
+
+func TestEntityHasScope(t *testing.T) {
+	t.Run("should have granted scopes only", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().WithScopes("read").Build()
+
+		assert.True(t, key.HasScope("read"))
+		assert.False(t, key.HasScope("write"))
+		assert.False(t, key.HasScope("admin"))
+	})
+
+	t.Run("should grant every scope to admin keys", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().WithScopes("admin").Build()
+
+		assert.True(t, key.HasScope("read"))
+		assert.True(t, key.HasScope("write"))
+		assert.True(t, key.HasScope("admin"))
+	})
+}
+
+func TestEntityUseIsStale(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should be stale for a key never used", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().Build()
+
+		assert.True(t, key.UseIsStale(now))
+	})
+
+	t.Run("should not be stale within a minute of the last use", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().WithLastUsedAt(now.Add(-59 * time.Second)).Build()
+
+		assert.False(t, key.UseIsStale(now))
+	})
+
+	t.Run("should be stale a minute after the last use", func(t *testing.T) {
+		key := apikey.NewApiKeyBuilder().WithLastUsedAt(now.Add(-time.Minute)).Build()
+
+		assert.True(t, key.UseIsStale(now))
+	})
+}
+
+func TestEntitySetters(t *testing.T) {
+	key := apikey.NewApiKeyBuilder().Build()
+
+	t.Run("should set scopes", func(t *testing.T) {
+		key.SetScopes([]string{"read", "write"})
+
+		assert.Equal(t, []string{"read", "write"}, key.Scopes())
+		assert.Equal(t, "read,write", key.ScopesString())
+	})
+
+	t.Run("should set last used at", func(t *testing.T) {
+		now := time.Now()
+		key.SetLastUsedAt(now)
+
+		assert.Equal(t, now, key.LastUsedAt())
+	})
+}
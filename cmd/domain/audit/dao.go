@@ -0,0 +1,21 @@
+package audit
+
+import "context"
+
+type Dao interface {
+	// Append links the entry to the head of the chain, saves it and moves
+	// the head to it. Run inside a transaction, it holds every other append
+	// off until the transaction ends.
+	Append(ctx context.Context, entry *Entry) (*Entry, error)
+	// FindAfter returns up to limit entries following seq, in chain order.
+	FindAfter(ctx context.Context, seq int64, limit int) ([]Entry, error)
+	// Head returns the seq and hash of the last entry appended, or 0 and
+	// Genesis before the first one.
+	Head(ctx context.Context) (int64, string, error)
+}
+
+// StateReader reads the state an entity is in now, as JSON in the form its
+// entries record it. It reports false when the entity no longer exists.
+type StateReader interface {
+	State(ctx context.Context, entity string, entityId int64) (string, bool, error)
+}
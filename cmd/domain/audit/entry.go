@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EntityPayment = "payment"
+	EntityOrder   = "order"
+	EntityCharge  = "charge"
+	EntityApiKey  = "api_key"
+
+	ActionInsert = "insert"
+	ActionUpdate = "update"
+)
+
+// Genesis is the previous hash of the first entry of the chain.
+var Genesis = strings.Repeat("0", sha256.Size*2)
+
+// Entry is a change made to an entity: its state before and after as JSON,
+// Before being empty on insert, and who made it. Entries form a chain where
+// each one hashes the previous, so editing, removing or reordering any of
+// them breaks every link after it. The hashes are keyed with a secret kept
+// outside the database, so rewriting the chain takes more than write access
+// to it.
+type Entry struct {
+	Seq       int64
+	Entity    string
+	EntityId  int64
+	Action    string
+	Before    string
+	After     string
+	Actor     string
+	RequestId string
+	CreatedAt time.Time
+	PrevHash  string
+	Hash      string
+}
+
+// Link makes the entry follow the one with prevSeq and prevHash and seals it
+// with key. CreatedAt is kept to the second, the precision every database
+// stores.
+func (e *Entry) Link(prevSeq int64, prevHash string, key []byte) {
+	e.Seq = prevSeq + 1
+	e.PrevHash = prevHash
+	e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Second)
+	e.Hash = e.ComputeHash(key)
+}
+
+// ComputeHash is the HMAC-SHA256, under key, of the previous hash along with
+// every field of the entry. Each field is prefixed with its length so no two
+// entries encode the same.
+func (e Entry) ComputeHash(key []byte) string {
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.Seq, 10),
+		e.Entity,
+		strconv.FormatInt(e.EntityId, 10),
+		e.Action,
+		e.Before,
+		e.After,
+		e.Actor,
+		e.RequestId,
+		e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+
+	h := hmac.New(sha256.New, key)
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Follows reports why the entry cannot come after the one with prevSeq and
+// prevHash, or an empty string when it links to it and was sealed with key.
+func (e Entry) Follows(prevSeq int64, prevHash string, key []byte) string {
+	switch {
+	case e.Seq != prevSeq+1:
+		return fmt.Sprintf("expected entry %d after %d, found %d", prevSeq+1, prevSeq, e.Seq)
+	case e.PrevHash != prevHash:
+		return fmt.Sprintf("previous hash does not match entry %d", prevSeq)
+	case !hmac.Equal([]byte(e.Hash), []byte(e.ComputeHash(key))):
+		return "hash does not match the contents of the entry"
+	}
+
+	return ""
+}
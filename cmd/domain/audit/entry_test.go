@@ -0,0 +1,225 @@
+package audit_test
+
+import (
+	"payment-gateway/cmd/domain/audit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+// chain links n payment entries from the genesis.
+func chain(n int) []audit.Entry {
+	entries := make([]audit.Entry, n)
+	prevSeq, prevHash := int64(0), audit.Genesis
+	for i := range entries {
+		entries[i] = audit.Entry{
+			Entity:    audit.EntityPayment,
+			EntityId:  int64(i + 1),
+			Action:    audit.ActionInsert,
+			After:     `{"status":"pending"}`,
+			Actor:     "api_key:abc123",
+			RequestId: "req-1",
+			CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 500, time.FixedZone("BRT", -3*3600)),
+		}
+		entries[i].Link(prevSeq, prevHash, key)
+		prevSeq, prevHash = entries[i].Seq, entries[i].Hash
+	}
+
+	return entries
+}
+
+func TestLink(t *testing.T) {
+	t.Run("should number the entry after the previous one and hash it", func(t *testing.T) {
+		entries := chain(2)
+
+		assert.Equal(t, int64(1), entries[0].Seq)
+		assert.Equal(t, audit.Genesis, entries[0].PrevHash)
+		assert.Len(t, entries[0].Hash, 64)
+		assert.Equal(t, int64(2), entries[1].Seq)
+		assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+		assert.NotEqual(t, entries[0].Hash, entries[1].Hash)
+	})
+
+	t.Run("should keep the creation time in UTC to the second", func(t *testing.T) {
+		entry := chain(1)[0]
+
+		assert.Equal(t, time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC), entry.CreatedAt)
+	})
+
+	t.Run("should hash the same entry read back in another zone alike", func(t *testing.T) {
+		entry := chain(1)[0]
+		entry.CreatedAt = entry.CreatedAt.In(time.FixedZone("JST", 9*3600))
+
+		assert.Equal(t, entry.Hash, entry.ComputeHash(key))
+	})
+
+	t.Run("should not let a field spill into the next one", func(t *testing.T) {
+		entry := chain(1)[0]
+		moved := entry
+		moved.Actor, moved.RequestId = entry.Actor+entry.RequestId, ""
+
+		assert.NotEqual(t, entry.ComputeHash(key), moved.ComputeHash(key))
+	})
+}
+
+func TestFollows(t *testing.T) {
+	t.Run("should accept an entry linked to the previous one", func(t *testing.T) {
+		entries := chain(2)
+
+		assert.Empty(t, entries[1].Follows(entries[0].Seq, entries[0].Hash, key))
+	})
+
+	t.Run("should reject an entry after a gap", func(t *testing.T) {
+		entries := chain(3)
+
+		assert.Contains(t, entries[2].Follows(entries[0].Seq, entries[0].Hash, key), "expected entry 2")
+	})
+
+	t.Run("should reject an entry whose contents changed", func(t *testing.T) {
+		entry := chain(1)[0]
+		entry.After = `{"status":"approved"}`
+
+		assert.Contains(t, entry.Follows(0, audit.Genesis, key), "hash does not match")
+	})
+
+	t.Run("should reject an entry sealed with another key", func(t *testing.T) {
+		entry := chain(1)[0]
+
+		assert.Contains(t, entry.Follows(0, audit.Genesis, []byte("another key")), "hash does not match")
+	})
+}
+
+func TestVerification(t *testing.T) {
+	t.Run("should walk an intact chain up to its head", func(t *testing.T) {
+		entries := chain(3)
+		v := audit.NewVerification(key)
+
+		for _, entry := range entries {
+			require.True(t, v.Add(entry))
+		}
+		v.End(3, entries[2].Hash)
+
+		assert.False(t, v.Broken())
+		assert.Equal(t, int64(3), v.Entries)
+		assert.Equal(t, entries[2].Hash, v.LastHash)
+	})
+
+	t.Run("should point at the entry after one rewritten with a fresh hash", func(t *testing.T) {
+		entries := chain(3)
+		entries[1].After = `{"status":"approved"}`
+		entries[1].Hash = entries[1].ComputeHash(key)
+		v := audit.NewVerification(key)
+
+		assert.True(t, v.Add(entries[0]))
+		assert.True(t, v.Add(entries[1]))
+		assert.False(t, v.Add(entries[2]))
+		assert.Equal(t, int64(3), v.BrokenAt)
+		assert.Contains(t, v.Reason, "entry 2")
+	})
+
+	t.Run("should catch entries removed from the end", func(t *testing.T) {
+		entries := chain(3)
+		v := audit.NewVerification(key)
+
+		v.Add(entries[0])
+		v.Add(entries[1])
+		v.End(3, entries[2].Hash)
+
+		assert.True(t, v.Broken())
+		assert.Equal(t, int64(3), v.BrokenAt)
+	})
+
+	t.Run("should accept an empty log at the genesis", func(t *testing.T) {
+		v := audit.NewVerification(key)
+
+		v.End(0, audit.Genesis)
+
+		assert.False(t, v.Broken())
+	})
+}
+
+func TestVerificationDrifts(t *testing.T) {
+	t.Run("should compare each entity with its last entry only", func(t *testing.T) {
+		entries := chain(3)
+		entries[2].EntityId = 1
+		entries[2].Link(entries[1].Seq, entries[1].Hash, key)
+		v := audit.NewVerification(key)
+		for _, entry := range entries {
+			require.True(t, v.Add(entry))
+		}
+
+		latest := v.Latest()
+
+		require.Len(t, latest, 2)
+		assert.Equal(t, int64(2), latest[0].Seq)
+		assert.Equal(t, int64(3), latest[1].Seq)
+	})
+
+	t.Run("should record a row that differs from its last entry or is gone", func(t *testing.T) {
+		entries := chain(2)
+		v := audit.NewVerification(key)
+
+		require.NoError(t, v.Compare(entries[0], `{"status":"pending"}`, true))
+		require.NoError(t, v.Compare(entries[1], `{"status":"approved"}`, true))
+		assert.Equal(t, []audit.Drift{{Entity: audit.EntityPayment, EntityId: 2, Seq: 2, Reason: "status differs from entry 2"}}, v.Drifts)
+
+		require.NoError(t, v.Compare(entries[0], "", false))
+		assert.Equal(t, "no longer exists", v.Drifts[1].Reason)
+	})
+
+	t.Run("should drop the drift of an entity changed after the head", func(t *testing.T) {
+		entries := chain(2)
+		v := audit.NewVerification(key)
+		require.NoError(t, v.Compare(entries[0], `{"status":"approved"}`, true))
+		require.NoError(t, v.Compare(entries[1], `{"status":"approved"}`, true))
+
+		v.Superseded(audit.Entry{Entity: audit.EntityPayment, EntityId: 1})
+
+		require.Len(t, v.Drifts, 1)
+		assert.Equal(t, int64(2), v.Drifts[0].EntityId)
+		assert.True(t, v.Drifted())
+	})
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("should match the same state", func(t *testing.T) {
+		field, err := audit.Diff(`{"id":1,"amount":10.5,"status":"paid"}`, `{"status":"paid","amount":10.5,"id":1}`)
+
+		require.NoError(t, err)
+		assert.Empty(t, field)
+	})
+
+	t.Run("should match times within a second in any zone", func(t *testing.T) {
+		field, err := audit.Diff(`{"updated_at":"2024-01-01T12:00:00.7-03:00"}`, `{"updated_at":"2024-01-01T15:00:01Z"}`)
+
+		require.NoError(t, err)
+		assert.Empty(t, field)
+	})
+
+	t.Run("should name the first field that differs", func(t *testing.T) {
+		field, err := audit.Diff(`{"amount":10,"status":"paid"}`, `{"amount":1000,"status":"canceled"}`)
+
+		require.NoError(t, err)
+		assert.Equal(t, "amount", field)
+	})
+
+	t.Run("should catch times further apart and fields missing on either side", func(t *testing.T) {
+		field, err := audit.Diff(`{"updated_at":"2024-01-01T12:00:00Z"}`, `{"updated_at":"2024-01-01T12:00:02Z"}`)
+		require.NoError(t, err)
+		assert.Equal(t, "updated_at", field)
+
+		field, err = audit.Diff(`{"id":1}`, `{"id":1,"details":"x"}`)
+		require.NoError(t, err)
+		assert.Equal(t, "details", field)
+	})
+
+	t.Run("should fail on a state that is not JSON", func(t *testing.T) {
+		_, err := audit.Diff(`{"id":1}`, `not json`)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Diff returns the first field, in name order, where the recorded and current
+// states differ, or an empty string when they match. Times match within a
+// second, since databases keep them to the second and round or truncate the
+// fraction as they see fit.
+func Diff(recorded, current string) (string, error) {
+	var before, after map[string]any
+	if err := json.Unmarshal([]byte(recorded), &before); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal([]byte(current), &after); err != nil {
+		return "", err
+	}
+
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if !sameValue(before[field], after[field]) {
+			return field, nil
+		}
+	}
+
+	return "", nil
+}
+
+func sameValue(recorded, current any) bool {
+	if a, ok := recorded.(string); ok {
+		if b, ok := current.(string); ok {
+			at, aErr := time.Parse(time.RFC3339Nano, a)
+			bt, bErr := time.Parse(time.RFC3339Nano, b)
+			if aErr == nil && bErr == nil {
+				return math.Abs(float64(at.Sub(bt))) < float64(time.Second)
+			}
+		}
+	}
+
+	return reflect.DeepEqual(recorded, current)
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Verification is the outcome of walking the chain from its first entry.
+// BrokenAt is the first entry that does not link to the one before it, zero
+// when every link holds. Drifts are the entities whose current state is not
+// the one their last entry left them in.
+type Verification struct {
+	Entries  int64
+	LastSeq  int64
+	LastHash string
+	BrokenAt int64
+	Reason   string
+	Drifts   []Drift
+
+	key    []byte
+	latest map[entityRef]Entry
+}
+
+// Drift is an entity changed outside the gateway since Seq, the last entry
+// recorded for it.
+type Drift struct {
+	Entity   string
+	EntityId int64
+	Seq      int64
+	Reason   string
+}
+
+type entityRef struct {
+	entity string
+	id     int64
+}
+
+// NewVerification checks the chain against the key it was sealed with.
+func NewVerification(key []byte) *Verification {
+	return &Verification{LastHash: Genesis, key: key, latest: map[entityRef]Entry{}}
+}
+
+func (v *Verification) Broken() bool {
+	return v.BrokenAt != 0
+}
+
+func (v *Verification) Drifted() bool {
+	return len(v.Drifts) > 0
+}
+
+// Add checks the next entry of the chain. It reports false, recording where
+// and why, once the chain breaks.
+func (v *Verification) Add(entry Entry) bool {
+	if reason := entry.Follows(v.LastSeq, v.LastHash, v.key); reason != "" {
+		v.BrokenAt, v.Reason = v.LastSeq+1, reason
+		return false
+	}
+
+	v.Entries++
+	v.LastSeq, v.LastHash = entry.Seq, entry.Hash
+	v.latest[entityRef{entry.Entity, entry.EntityId}] = entry
+	return true
+}
+
+// End compares the last entry walked with the head of the chain, which only
+// moves forward, so entries removed from the end are caught too.
+func (v *Verification) End(headSeq int64, headHash string) {
+	switch {
+	case headSeq > v.LastSeq:
+		v.BrokenAt = v.LastSeq + 1
+		v.Reason = fmt.Sprintf("the chain head is at entry %d but the log ends at %d", headSeq, v.LastSeq)
+	case headSeq < v.LastSeq:
+		v.BrokenAt = headSeq + 1
+		v.Reason = fmt.Sprintf("entries were added after the chain head at %d", headSeq)
+	case headHash != v.LastHash:
+		v.BrokenAt = v.LastSeq
+		v.Reason = "the last entry does not match the chain head"
+	}
+}
+
+// Latest returns the last entry walked for each entity, in chain order.
+func (v *Verification) Latest() []Entry {
+	entries := make([]Entry, 0, len(v.latest))
+	for _, entry := range v.latest {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+
+	return entries
+}
+
+// Compare records a drift when current, the state the entity of entry is in
+// now, differs from the one entry left it in. found is false when the entity
+// no longer exists.
+func (v *Verification) Compare(entry Entry, current string, found bool) error {
+	if !found {
+		v.drift(entry, "no longer exists")
+		return nil
+	}
+
+	field, err := Diff(entry.After, current)
+	if err != nil {
+		return err
+	}
+	if field != "" {
+		v.drift(entry, fmt.Sprintf("%s differs from entry %d", field, entry.Seq))
+	}
+
+	return nil
+}
+
+// Superseded drops the drift of the entity entry changed. Entries appended
+// after the head that was walked are expected to leave it in a later state.
+func (v *Verification) Superseded(entry Entry) {
+	drifts := v.Drifts[:0]
+	for _, drift := range v.Drifts {
+		if drift.Entity != entry.Entity || drift.EntityId != entry.EntityId {
+			drifts = append(drifts, drift)
+		}
+	}
+	v.Drifts = drifts
+}
+
+func (v *Verification) drift(entry Entry, reason string) {
+	v.Drifts = append(v.Drifts, Drift{Entity: entry.Entity, EntityId: entry.EntityId, Seq: entry.Seq, Reason: reason})
+}
@@ -1,6 +1,21 @@
 package charge
 
+import (
+	"context"
+	exceptions "payment-gateway/cmd/domain/err"
+	"time"
+)
+
+var ErrNotFound = exceptions.NewDomainError(exceptions.CodeChargeNotFound, "Charge not found")
+
 type Dao interface {
-	Insert(charge *Entity) (*Entity, error)
-	FindByOrderId(id int64) ([]Entity, error)
+	Insert(ctx context.Context, charge *Entity) (*Entity, error)
+	FindById(ctx context.Context, id int64) (*Entity, error)
+	FindByOrderId(ctx context.Context, id int64) ([]Entity, error)
+	// SumChargesByOrder returns the charges of the order's payments summed
+	// by category.
+	SumChargesByOrder(ctx context.Context, orderId int64) (map[string]float64, error)
+	// SumChargesByOrderAsOf sums by category only the charges created up to
+	// asOf.
+	SumChargesByOrderAsOf(ctx context.Context, orderId int64, asOf time.Time) (map[string]float64, error)
 }
@@ -1,21 +1,12 @@
 package charge
 
 import (
+	"math"
 	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/domain/paymentmethod"
 	"time"
 )
 
-var typeByCategory = map[string]string{
-	"CreditCard": "financial_fee",
-	"CashSlip":   "process_fee",
-	"Cash":       "free",
-}
-var taxByType = map[string]float64{
-	"financial_fee": 0.1,
-	"process_fee":   0.2,
-	"free":          0,
-}
-
 type Entity struct {
 	amount    float64
 	id        int64
@@ -26,7 +17,18 @@ type Entity struct {
 	updatedAt time.Time
 }
 
-func NewCharge(entity payment.Entity) (*Entity, bool) {
+type Option func(*Entity)
+
+// WithCreatedAt stamps the charge with at instead of the current time, so it
+// lines up with the payment transition that produced it.
+func WithCreatedAt(at time.Time) Option {
+	return func(c *Entity) {
+		c.createdAt = at
+		c.updatedAt = at
+	}
+}
+
+func NewCharge(entity payment.Entity, options ...Option) (*Entity, bool) {
 	if !entity.IsValid() {
 		return nil, false
 	}
@@ -35,29 +37,42 @@ func NewCharge(entity payment.Entity) (*Entity, bool) {
 	category := getCategory(entity)
 	paymentId := entity.Id()
 
-	return &Entity{
+	charge := &Entity{
 		amount:    amount,
 		category:  category,
 		paymentId: paymentId,
 		createdAt: time.Now(),
 		updatedAt: time.Now(),
-	}, true
+	}
+	for _, option := range options {
+		option(charge)
+	}
+
+	return charge, true
 }
 
 func getAmount(entity payment.Entity) float64 {
-	category := getCategory(entity)
+	method, err := paymentmethod.Find(entity.Type())
+	if err != nil {
+		return 0
+	}
+
+	return cents(method.Fee(entity.Amount()))
+}
 
-	return entity.Amount() * taxByType[category]
+// cents rounds the fee to the two decimals amounts are stored with, so the
+// charges of an order add up to the charges amount it keeps.
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
 }
 
 func getCategory(entity payment.Entity) string {
-	category := entity.Type()
-	t, ok := typeByCategory[category]
-	if !ok {
-		return "free"
+	method, err := paymentmethod.Find(entity.Type())
+	if err != nil {
+		return paymentmethod.FeeCategoryFree
 	}
 
-	return t
+	return method.FeeCategory()
 }
 
 func (c *Entity) Amount() float64 {
@@ -22,6 +22,25 @@ func TestNewCharge(t *testing.T) {
 		assert.NotZero(t, chargeEntity.UpdatedAt())
 	})
 
+	t.Run("should stamp the charge with the given creation time", func(t *testing.T) {
+		processedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		chargeEntity, ok := charge.NewCharge(*paymentEntity, charge.WithCreatedAt(processedAt))
+
+		assert.True(t, ok)
+		assert.Equal(t, processedAt, chargeEntity.CreatedAt())
+		assert.Equal(t, processedAt, chargeEntity.UpdatedAt())
+	})
+
+	t.Run("should round a fractional-cent fee to cents", func(t *testing.T) {
+		fractional := payment.NewPaymentBuilder().WithId(2).WithStatus("approved").WithType("CreditCard").WithAmount(3.33).Build()
+
+		chargeEntity, ok := charge.NewCharge(*fractional)
+
+		assert.True(t, ok)
+		assert.Equal(t, 0.33, chargeEntity.Amount())
+	})
+
 	t.Run("should create charge with correct values for process_fee", func(t *testing.T) {
 		paymentEntity.SetType("CashSlip")
 		chargeEntity, ok := charge.NewCharge(*paymentEntity)
@@ -0,0 +1,32 @@
+package exceptions
+
+// Stable, machine-readable error codes exposed to API clients. Codes must
+// never be renamed once published.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeRouteNotFound    = "route_not_found"
+	CodeRequestTooLarge  = "request_too_large"
+	CodeInternalError    = "internal_error"
+	CodeRequestTimeout   = "request_timeout"
+	CodeRequestCanceled  = "request_canceled"
+
+	CodeOrderNotFound      = "order_not_found"
+	CodePaymentNotFound    = "payment_not_found"
+	CodeChargeNotFound     = "charge_not_found"
+	CodePaymentExceedsDebt = "payment_exceeds_debt"
+	CodeInvalidTransition  = "invalid_transition"
+
+	CodePaymentMethodDisabled    = "payment_method_disabled"
+	CodePaymentMethodNotEligible = "payment_method_not_eligible"
+
+	CodeApiKeyNotFound    = "api_key_not_found"
+	CodeApiKeyRevoked     = "api_key_revoked"
+	CodeInvalidScope      = "invalid_scope"
+	CodeInvalidMode       = "invalid_mode"
+	CodeMissingApiKey     = "missing_api_key"
+	CodeInvalidApiKey     = "invalid_api_key"
+	CodeInsufficientScope = "insufficient_scope"
+	CodeInvalidSignature  = "invalid_signature"
+	CodeRateLimited       = "rate_limited"
+)
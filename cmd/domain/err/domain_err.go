@@ -1,16 +1,43 @@
 package exceptions
 
+const errValidationFailed = "Request validation failed"
+
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
 type DomainError struct {
 	error
+	code   string
 	reason string
+	fields []FieldError
 }
 
-func NewDomainError(reason string) *DomainError {
+func NewDomainError(code string, reason string) *DomainError {
 	return &DomainError{
+		code:   code,
 		reason: reason,
 	}
 }
 
+func NewValidationError(fields ...FieldError) *DomainError {
+	return &DomainError{
+		code:   CodeValidationFailed,
+		reason: errValidationFailed,
+		fields: fields,
+	}
+}
+
 func (e *DomainError) Error() string {
 	return e.reason
 }
+
+func (e *DomainError) Code() string {
+	return e.code
+}
+
+func (e *DomainError) Fields() []FieldError {
+	return e.fields
+}
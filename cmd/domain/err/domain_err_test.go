@@ -8,9 +8,25 @@ import (
 func TestNewDomainError(t *testing.T) {
 	t.Run("Should create new domain error", func(t *testing.T) {
 		reason := "error creating payment"
-		err := NewDomainError(reason)
+		err := NewDomainError(CodeInvalidRequest, reason)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, reason, err.Error())
+		assert.Equal(t, CodeInvalidRequest, err.Code())
+		assert.Empty(t, err.Fields())
+	})
+}
+
+func TestNewValidationError(t *testing.T) {
+	t.Run("Should carry every field error", func(t *testing.T) {
+		err := NewValidationError(
+			FieldError{Field: "amount", Code: "gt", Message: "must be greater than 0"},
+			FieldError{Field: "order_id", Code: "required", Message: "is required"},
+		)
+
+		assert.Equal(t, CodeValidationFailed, err.Code())
+		assert.Equal(t, "Request validation failed", err.Error())
+		assert.Len(t, err.Fields(), 2)
+		assert.Equal(t, "amount", err.Fields()[0].Field)
 	})
 }
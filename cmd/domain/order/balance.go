@@ -0,0 +1,27 @@
+package order
+
+import "math"
+
+// Balance holds the running totals an order keeps of its payments and
+// charges.
+type Balance struct {
+	Paid     float64
+	Charges  float64
+	Refunded float64
+}
+
+// Matches reports whether b and other agree to the cent. Smaller differences
+// are float noise, not drift.
+func (b Balance) Matches(other Balance) bool {
+	return math.Abs(b.Paid-other.Paid) < 0.005 &&
+		math.Abs(b.Charges-other.Charges) < 0.005 &&
+		math.Abs(b.Refunded-other.Refunded) < 0.005
+}
+
+// Drift is an order whose stored balance no longer matches the one
+// recomputed from its payments and charges.
+type Drift struct {
+	OrderId  int64
+	Stored   Balance
+	Computed Balance
+}
@@ -19,6 +19,11 @@ func (b *Builder) WithId(id int64) *Builder {
 	return b
 }
 
+func (b *Builder) WithMerchantId(merchantId int64) *Builder {
+	b.o.SetMerchantId(merchantId)
+	return b
+}
+
 func (b *Builder) WithStatus(status string) *Builder {
 	b.o.SetStatus(status)
 	return b
@@ -29,6 +34,21 @@ func (b *Builder) WithAmount(amount float64) *Builder {
 	return b
 }
 
+func (b *Builder) WithPaidAmount(amount float64) *Builder {
+	b.o.SetPaidAmount(amount)
+	return b
+}
+
+func (b *Builder) WithChargesAmount(amount float64) *Builder {
+	b.o.SetChargesAmount(amount)
+	return b
+}
+
+func (b *Builder) WithRefundedAmount(amount float64) *Builder {
+	b.o.SetRefundedAmount(amount)
+	return b
+}
+
 func (b *Builder) WithCreatedAt(createdAt time.Time) *Builder {
 	b.o.SetCreatedAt(createdAt)
 	return b
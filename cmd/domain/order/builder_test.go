@@ -22,15 +22,24 @@ func TestBuilderMethods(t *testing.T) {
 	t.Run("should build order with all fields set", func(t *testing.T) {
 		p := order.NewOrderBuilder().
 			WithId(1).
+			WithMerchantId(7).
 			WithAmount(100.0).
 			WithStatus("approved").
+			WithPaidAmount(60.0).
+			WithChargesAmount(2.5).
+			WithRefundedAmount(10.0).
 			WithCreatedAt(now).
 			WithUpdatedAt(now).
 			Build()
 
 		assert.Equal(t, int64(1), p.Id())
+		assert.Equal(t, int64(7), p.MerchantId())
 		assert.Equal(t, 100.0, p.Amount())
 		assert.Equal(t, "approved", p.Status())
+		assert.Equal(t, 60.0, p.PaidAmount())
+		assert.Equal(t, 2.5, p.ChargesAmount())
+		assert.Equal(t, 10.0, p.RefundedAmount())
+		assert.Equal(t, 40.0, p.RemainingDebt())
 		assert.Equal(t, now, p.CreatedAt())
 		assert.Equal(t, now, p.UpdatedAt())
 	})
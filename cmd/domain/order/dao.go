@@ -1,6 +1,25 @@
 package order
 
+import (
+	"context"
+	exceptions "payment-gateway/cmd/domain/err"
+)
+
+var ErrNotFound = exceptions.NewDomainError(exceptions.CodeOrderNotFound, "Order not found")
+
 type Dao interface {
-	FindById(id int64) (*Entity, error)
-	Update(or *Entity) (*Entity, error)
+	Insert(ctx context.Context, or *Entity) (*Entity, error)
+	FindById(ctx context.Context, id int64) (*Entity, error)
+	// FindByIdForUpdate locks the order until the surrounding transaction
+	// ends, so concurrent payments cannot overwrite each other's balance.
+	FindByIdForUpdate(ctx context.Context, id int64) (*Entity, error)
+	// Update saves the status and balance and appends the transitions the
+	// order went through to its history.
+	Update(ctx context.Context, or *Entity) (*Entity, error)
+	FindTransitions(ctx context.Context, orderId int64) ([]Transition, error)
+	// FindBalanceDrift recomputes the balance of up to limit orders with ids
+	// greater than afterId from their payments and charges and returns the
+	// ones that disagree, with the id to resume from, or 0 once it reached
+	// the last order.
+	FindBalanceDrift(ctx context.Context, afterId int64, limit int) ([]Drift, int64, error)
 }
@@ -1,6 +1,8 @@
 package order
 
 import (
+	"fmt"
+	"math"
 	"payment-gateway/cmd/domain/err"
 	"payment-gateway/cmd/domain/payment"
 	"time"
@@ -8,22 +10,36 @@ import (
 
 const (
 	errPaymentExceedsDebt = "Payment exceeds debt"
+	pendingStatus         = "pending"
 	paidStatus            = "paid"
 )
 
 type Entity struct {
-	id     int64
-	status string
-	amount float64
+	id         int64
+	merchantId int64
+	status     string
+	amount     float64
+
+	paidAmount     float64
+	chargesAmount  float64
+	refundedAmount float64
 
 	createdAt time.Time
 	updatedAt time.Time
+
+	transitions []Transition
 }
 
 func (o *Entity) Id() int64 {
 	return o.id
 }
 
+// MerchantId is the merchant the order belongs to, zero for orders stored
+// before orders had one and not assigned since.
+func (o *Entity) MerchantId() int64 {
+	return o.merchantId
+}
+
 func (o *Entity) Status() string {
 	return o.status
 }
@@ -32,6 +48,35 @@ func (o *Entity) Amount() float64 {
 	return o.amount
 }
 
+func (o *Entity) PaidAmount() float64 {
+	return o.paidAmount
+}
+
+func (o *Entity) ChargesAmount() float64 {
+	return o.chargesAmount
+}
+
+func (o *Entity) RefundedAmount() float64 {
+	return o.refundedAmount
+}
+
+func (o *Entity) RemainingDebt() float64 {
+	return cents(o.amount - o.paidAmount)
+}
+
+// NetAmount is what the merchant receives: the paid amount minus charges.
+func (o *Entity) NetAmount() float64 {
+	return cents(o.paidAmount - o.chargesAmount)
+}
+
+func (o *Entity) Balance() Balance {
+	return Balance{Paid: o.paidAmount, Charges: o.chargesAmount, Refunded: o.refundedAmount}
+}
+
+func (o *Entity) IsPaid() bool {
+	return o.status == paidStatus
+}
+
 func (o *Entity) CreatedAt() time.Time {
 	return o.createdAt
 }
@@ -44,6 +89,10 @@ func (o *Entity) SetId(id int64) {
 	o.id = id
 }
 
+func (o *Entity) SetMerchantId(merchantId int64) {
+	o.merchantId = merchantId
+}
+
 func (o *Entity) SetStatus(status string) {
 	o.status = status
 	o.updatedAt = time.Now()
@@ -62,23 +111,81 @@ func (o *Entity) SetAmount(amount float64) {
 	o.updatedAt = time.Now()
 }
 
-func (o *Entity) ProcessPayment(remainingDebt float64, pay payment.Entity) error {
+func (o *Entity) SetPaidAmount(amount float64) {
+	o.paidAmount = amount
+}
+
+func (o *Entity) SetChargesAmount(amount float64) {
+	o.chargesAmount = amount
+}
+
+func (o *Entity) SetRefundedAmount(amount float64) {
+	o.refundedAmount = amount
+}
+
+// ProcessPayment adds an approved payment to the paid balance and marks the
+// order paid once nothing remains.
+func (o *Entity) ProcessPayment(pay payment.Entity) error {
 	if pay.IsValid() {
+		remainingDebt := o.RemainingDebt()
 		if remainingDebt < pay.Amount() {
-			return exceptions.NewDomainError(errPaymentExceedsDebt)
+			return exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, errPaymentExceedsDebt)
 		}
 
+		o.paidAmount = cents(o.paidAmount + pay.Amount())
+		o.updatedAt = time.Now()
 		if remainingDebt == pay.Amount() {
+			from := o.status
 			o.paid()
+			o.transitions = append(o.transitions, Transition{OrderId: o.id, From: from, To: o.status, Reason: fmt.Sprintf("payment %d approved", pay.Id())})
 		}
 	}
 
 	return nil
 }
 
-func (o *Entity) PreValidation(remainingDebt, amount float64) error {
-	if remainingDebt < amount {
-		return exceptions.NewDomainError(errPaymentExceedsDebt)
+// AsOf returns the order as it stood when it had only the given payments, in
+// the status each had then, and charges.
+func (o *Entity) AsOf(payments []payment.Entity, charges float64) *Entity {
+	past := *o
+	past.transitions = nil
+	past.paidAmount, past.refundedAmount = 0, 0
+	for _, pay := range payments {
+		switch pay.Status() {
+		case payment.StatusApproved:
+			past.paidAmount = cents(past.paidAmount + pay.Amount())
+		case payment.StatusRefunded:
+			past.refundedAmount = cents(past.refundedAmount + pay.Amount())
+		}
+	}
+	past.chargesAmount = cents(charges)
+
+	if past.RemainingDebt() <= 0 {
+		past.paid()
+	} else if past.IsPaid() {
+		past.status = pendingStatus
+	}
+
+	return &past
+}
+
+// Transitions returns the status changes not saved yet.
+func (o *Entity) Transitions() []Transition {
+	return o.transitions
+}
+
+func (o *Entity) ClearTransitions() {
+	o.transitions = nil
+}
+
+func (o *Entity) AddCharge(amount float64) {
+	o.chargesAmount = cents(o.chargesAmount + amount)
+	o.updatedAt = time.Now()
+}
+
+func (o *Entity) PreValidation(amount float64) error {
+	if o.RemainingDebt() < amount {
+		return exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, errPaymentExceedsDebt)
 	}
 
 	return nil
@@ -87,3 +194,9 @@ func (o *Entity) PreValidation(remainingDebt, amount float64) error {
 func (o *Entity) paid() {
 	o.status = paidStatus
 }
+
+// cents rounds away the binary noise float sums pick up, since amounts are
+// stored with two decimals.
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
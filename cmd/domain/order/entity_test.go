@@ -50,48 +50,114 @@ func TestEntitySetters(t *testing.T) {
 }
 
 func TestEntityProcessPayment(t *testing.T) {
-	o := order.NewOrderBuilder().WithStatus("pending").Build()
-	p := payment.NewPaymentBuilder().WithAmount(123).Build()
-
 	t.Run("should pay order", func(t *testing.T) {
-		p.SetStatus("approved")
-		err := o.ProcessPayment(123.0, *p)
+		o := order.NewOrderBuilder().WithId(1).WithStatus("pending").WithAmount(123).Build()
+		p := payment.NewPaymentBuilder().WithId(9).WithAmount(123).WithStatus("approved").Build()
+
+		err := o.ProcessPayment(*p)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "paid", o.Status())
+		assert.Equal(t, 123.0, o.PaidAmount())
+		assert.Equal(t, 0.0, o.RemainingDebt())
+		assert.Equal(t, []order.Transition{{OrderId: 1, From: "pending", To: "paid", Reason: "payment 9 approved"}}, o.Transitions())
 	})
 
 	t.Run("should not pay order", func(t *testing.T) {
-		p.SetStatus("approved")
-		o.SetStatus("pending")
+		o := order.NewOrderBuilder().WithStatus("pending").WithAmount(150).Build()
+		p := payment.NewPaymentBuilder().WithAmount(123).WithStatus("approved").Build()
 
-		err := o.ProcessPayment(150.0, *p)
+		err := o.ProcessPayment(*p)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "pending", o.Status())
+		assert.Equal(t, 123.0, o.PaidAmount())
+		assert.Equal(t, 27.0, o.RemainingDebt())
+		assert.Empty(t, o.Transitions())
+	})
+
+	t.Run("should pay order with the last of several payments", func(t *testing.T) {
+		o := order.NewOrderBuilder().WithStatus("pending").WithAmount(0.3).WithPaidAmount(0.1).Build()
+		p := payment.NewPaymentBuilder().WithAmount(0.2).WithStatus("approved").Build()
+
+		err := o.ProcessPayment(*p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "paid", o.Status())
+		assert.Equal(t, 0.3, o.PaidAmount())
 	})
 
 	t.Run("should throw error to pay", func(t *testing.T) {
-		p.SetStatus("approved")
-		o.SetStatus("pending")
+		o := order.NewOrderBuilder().WithStatus("pending").WithAmount(150).WithPaidAmount(50).Build()
+		p := payment.NewPaymentBuilder().WithAmount(123).WithStatus("approved").Build()
 
-		err := o.ProcessPayment(100.0, *p)
+		err := o.ProcessPayment(*p)
 
 		assert.Equal(t, "Payment exceeds debt", err.Error())
 		assert.Equal(t, "pending", o.Status())
+		assert.Equal(t, 50.0, o.PaidAmount())
+	})
+
+	t.Run("should leave the balance unchanged for a reproved payment", func(t *testing.T) {
+		o := order.NewOrderBuilder().WithStatus("pending").WithAmount(150).Build()
+		p := payment.NewPaymentBuilder().WithAmount(123).WithStatus("reproved").Build()
+
+		err := o.ProcessPayment(*p)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, o.PaidAmount())
 	})
 }
 
+func TestEntityAddCharge(t *testing.T) {
+	o := order.NewOrderBuilder().WithChargesAmount(0.1).Build()
+
+	o.AddCharge(0.2)
+
+	assert.Equal(t, 0.3, o.ChargesAmount())
+	assert.Equal(t, order.Balance{Charges: 0.3}, o.Balance())
+}
+
 func TestEntityPreValidation(t *testing.T) {
-	o := order.NewOrderBuilder().WithStatus("pending").Build()
+	o := order.NewOrderBuilder().WithStatus("pending").WithAmount(150).WithPaidAmount(50).Build()
 
 	t.Run("Should not throw error in validation", func(t *testing.T) {
-		err := o.PreValidation(100.0, 90.0)
+		err := o.PreValidation(90.0)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Should throw error when the payment is larger than the remaining debt", func(t *testing.T) {
-		err := o.PreValidation(100.0, 110.0)
+		err := o.PreValidation(110.0)
 		assert.Equal(t, "Payment exceeds debt", err.Error())
 	})
 }
+
+func TestEntityAsOf(t *testing.T) {
+	o := order.NewOrderBuilder().WithId(1).WithStatus("paid").WithAmount(150).
+		WithPaidAmount(150).WithChargesAmount(30).Build()
+
+	t.Run("should rebuild the balance from the payments and charges of the time", func(t *testing.T) {
+		past := o.AsOf([]payment.Entity{
+			*payment.NewPaymentBuilder().WithAmount(100.1).WithStatus("approved").Build(),
+			*payment.NewPaymentBuilder().WithAmount(20).WithStatus("reproved").Build(),
+			*payment.NewPaymentBuilder().WithAmount(50).WithStatus("pending").Build(),
+		}, 10.01)
+
+		assert.Equal(t, int64(1), past.Id())
+		assert.Equal(t, "pending", past.Status())
+		assert.Equal(t, order.Balance{Paid: 100.1, Charges: 10.01}, past.Balance())
+		assert.Equal(t, 49.9, past.RemainingDebt())
+		assert.Equal(t, "paid", o.Status())
+		assert.Equal(t, 150.0, o.PaidAmount())
+	})
+
+	t.Run("should mark the order paid once the payments of the time cover it", func(t *testing.T) {
+		past := order.NewOrderBuilder().WithStatus("pending").WithAmount(150).Build().AsOf([]payment.Entity{
+			*payment.NewPaymentBuilder().WithAmount(100).WithStatus("approved").Build(),
+			*payment.NewPaymentBuilder().WithAmount(50).WithStatus("approved").Build(),
+		}, 0)
+
+		assert.Equal(t, "paid", past.Status())
+		assert.Equal(t, 0.0, past.RemainingDebt())
+	})
+}
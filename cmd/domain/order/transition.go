@@ -0,0 +1,17 @@
+package order
+
+import "time"
+
+// Transition is a status change of an order. The entity records the ones it
+// goes through and the DAO appends them to the order's history along with
+// the update, stamped with who asked for it and when.
+type Transition struct {
+	Id        int64
+	OrderId   int64
+	From      string
+	To        string
+	Reason    string
+	Actor     string
+	RequestId string
+	CreatedAt time.Time
+}
@@ -10,7 +10,7 @@ func NewPaymentBuilder() *Builder {
 	return &Builder{
 		pay: &Entity{
 			createdAt: time.Now(),
-			status:    pendingStatus,
+			status:    StatusPending,
 		},
 	}
 }
@@ -20,6 +20,11 @@ func (b *Builder) WithId(id int64) *Builder {
 	return b
 }
 
+func (b *Builder) WithMerchantId(merchantId int64) *Builder {
+	b.pay.SetMerchantId(merchantId)
+	return b
+}
+
 func (b *Builder) WithOrderId(id int64) *Builder {
 	b.pay.SetOrderID(id)
 	return b
@@ -50,6 +55,12 @@ func (b *Builder) WithCreatedAt(createdAt time.Time) *Builder {
 	return b
 }
 
+// WithUpdatedAt goes last, since the other setters touch updatedAt.
+func (b *Builder) WithUpdatedAt(updatedAt time.Time) *Builder {
+	b.pay.SetUpdatedAt(updatedAt)
+	return b
+}
+
 func (b *Builder) Build() *Entity {
 	return b.pay
 }
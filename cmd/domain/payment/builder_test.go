@@ -22,6 +22,7 @@ func TestBuilderMethods(t *testing.T) {
 	t.Run("should build payment with all fields set", func(t *testing.T) {
 		p := payment.NewPaymentBuilder().
 			WithId(1).
+			WithMerchantId(7).
 			WithOrderId(123).
 			WithAmount(100.0).
 			WithType("credit_card").
@@ -31,6 +32,7 @@ func TestBuilderMethods(t *testing.T) {
 			Build()
 
 		assert.Equal(t, int64(1), p.Id())
+		assert.Equal(t, int64(7), p.MerchantId())
 		assert.Equal(t, int64(123), p.OrderID())
 		assert.Equal(t, 100.0, p.Amount())
 		assert.Equal(t, "credit_card", p.Type())
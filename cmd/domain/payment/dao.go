@@ -1,8 +1,34 @@
 package payment
 
+import (
+	"context"
+	exceptions "payment-gateway/cmd/domain/err"
+	"time"
+)
+
+var ErrNotFound = exceptions.NewDomainError(exceptions.CodePaymentNotFound, "Payment not found")
+
 type Dao interface {
-	FindById(id int64) (*Entity, error)
-	FindByOrderId(id int64) ([]Entity, error)
-	Insert(payment *Entity) (*Entity, error)
-	Update(pay *Entity) (*Entity, error)
+	FindById(ctx context.Context, id int64) (*Entity, error)
+	// FindByIdForUpdate locks the payment until the surrounding transaction
+	// ends, so it cannot be processed twice concurrently.
+	FindByIdForUpdate(ctx context.Context, id int64) (*Entity, error)
+	FindByOrderId(ctx context.Context, id int64) ([]Entity, error)
+	// FindByOrderIdAsOf returns the payments of the order created up to
+	// asOf, in the status each had then.
+	FindByOrderIdAsOf(ctx context.Context, orderId int64, asOf time.Time) ([]Entity, error)
+	// Search returns up to search.Limit payments matching the search, in its
+	// sort order.
+	Search(ctx context.Context, search Search) ([]Entity, error)
+	Insert(ctx context.Context, payment *Entity) (*Entity, error)
+	// Update saves the status and appends the transitions the payment went
+	// through to its history.
+	Update(ctx context.Context, pay *Entity) (*Entity, error)
+	FindTransitions(ctx context.Context, paymentId int64) ([]Transition, error)
+	// SumApprovedByOrder returns the amount paid by the approved payments of
+	// the order.
+	SumApprovedByOrder(ctx context.Context, orderId int64) (float64, error)
+	// SummarizeByOrder returns the payments of the order grouped by status
+	// and type.
+	SummarizeByOrder(ctx context.Context, orderId int64) ([]Summary, error)
 }
@@ -1,18 +1,25 @@
 package payment
 
 import (
+	exceptions "payment-gateway/cmd/domain/err"
 	"time"
 )
 
 const (
-	pendingStatus  = "pending"
-	approvedStatus = "approved"
-	reprovedStatus = "reproved"
+	errInvalidTransition = "Payment was already processed"
+
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusReproved = "reproved"
+	// StatusRefunded is counted in the refunded balance of the order. No
+	// transition reaches it yet.
+	StatusRefunded = "refunded"
 )
 
 type Entity struct {
-	id     int64
-	status string
+	id         int64
+	merchantId int64
+	status     string
 
 	orderID     int64
 	amount      float64
@@ -21,36 +28,46 @@ type Entity struct {
 
 	createdAt time.Time
 	updatedAt time.Time
+
+	transitions []Transition
 }
 
 func NewPayment(orderID int64, amount float64, paymentType string) *Entity {
 	return &Entity{
 		orderID:     orderID,
 		amount:      amount,
-		status:      pendingStatus,
+		status:      StatusPending,
 		paymentType: paymentType,
 		createdAt:   time.Now(),
 		updatedAt:   time.Now(),
 	}
 }
 
-func (p *Entity) Process(processType string, details string) {
+func (p *Entity) Process(processType string, details string) error {
+	if p.status != StatusPending {
+		return exceptions.NewDomainError(exceptions.CodeInvalidTransition, errInvalidTransition)
+	}
+
 	p.details = details
 
+	from := p.status
 	if processType == "Success" {
 		p.approve()
 	} else {
 		p.reprove()
 	}
+	p.transitions = append(p.transitions, Transition{PaymentId: p.id, From: from, To: p.status, Reason: details})
+
+	return nil
 }
 
 func (p *Entity) approve() {
-	p.status = approvedStatus
+	p.status = StatusApproved
 	p.updatedAt = time.Now()
 }
 
 func (p *Entity) reprove() {
-	p.status = reprovedStatus
+	p.status = StatusReproved
 	p.updatedAt = time.Now()
 }
 
@@ -58,6 +75,11 @@ func (p *Entity) Status() string {
 	return p.status
 }
 
+// MerchantId is the merchant of the order the payment was made for.
+func (p *Entity) MerchantId() int64 {
+	return p.merchantId
+}
+
 func (p *Entity) OrderID() int64 {
 	return p.orderID
 }
@@ -90,6 +112,10 @@ func (p *Entity) Amount() float64 {
 	return p.amount
 }
 
+func (p *Entity) SetMerchantId(merchantId int64) {
+	p.merchantId = merchantId
+}
+
 func (p *Entity) SetOrderID(id int64) {
 	p.orderID = id
 	p.updatedAt = time.Now()
@@ -119,6 +145,19 @@ func (p *Entity) SetCreatedAt(createdAt time.Time) {
 	p.createdAt = createdAt
 }
 
+func (p *Entity) SetUpdatedAt(updatedAt time.Time) {
+	p.updatedAt = updatedAt
+}
+
+// Transitions returns the status changes not saved yet.
+func (p *Entity) Transitions() []Transition {
+	return p.transitions
+}
+
+func (p *Entity) ClearTransitions() {
+	p.transitions = nil
+}
+
 func (p *Entity) IsValid() bool {
-	return p.status == approvedStatus
+	return p.status == StatusApproved
 }
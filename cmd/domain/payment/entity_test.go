@@ -51,6 +51,29 @@ func TestProcess(t *testing.T) {
 		assert.Equal(t, "reproved details", p.Details())
 		assert.True(t, p.UpdatedAt().After(initialUpdatedAt))
 	})
+
+	t.Run("should record the transition until it is cleared", func(t *testing.T) {
+		p := payment.NewPayment(123, 123.0, "credit_card")
+		p.SetId(7)
+
+		assert.NoError(t, p.Process("Failure", "insufficient funds"))
+
+		assert.Equal(t, []payment.Transition{{PaymentId: 7, From: "pending", To: "reproved", Reason: "insufficient funds"}}, p.Transitions())
+		p.ClearTransitions()
+		assert.Empty(t, p.Transitions())
+	})
+
+	t.Run("should reject processing a payment twice", func(t *testing.T) {
+		p := payment.NewPayment(123, 123.0, "credit_card")
+		assert.NoError(t, p.Process("Success", "approved details"))
+
+		err := p.Process("Failure", "reproved details")
+
+		assert.EqualError(t, err, "Payment was already processed")
+		assert.Equal(t, "approved", p.Status())
+		assert.Equal(t, "approved details", p.Details())
+		assert.Len(t, p.Transitions(), 1)
+	})
 }
 
 func TestEntitySetters(t *testing.T) {
@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"cmp"
+	"time"
+)
+
+const (
+	SortId        = "id"
+	SortAmount    = "amount"
+	SortCreatedAt = "created_at"
+)
+
+// Search filters, sorts and pages the payments of a merchant. MerchantId is
+// always applied, so a zero one matches no payment; the other zero filters
+// match every payment. The amount range is inclusive and CreatedTo is
+// exclusive.
+type Search struct {
+	MerchantId  int64
+	OrderId     int64
+	Status      string
+	Type        string
+	MinAmount   float64
+	MaxAmount   float64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	Sort       string
+	Descending bool
+	// After resumes the listing right past the payment it points at.
+	After *Cursor
+	Limit int
+}
+
+// Cursor is the position of a payment in any of the sort orders, the id
+// breaking ties between equal amounts or creation times.
+type Cursor struct {
+	Id        int64     `json:"id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func CursorOf(pay Entity) Cursor {
+	return Cursor{Id: pay.Id(), Amount: pay.Amount(), CreatedAt: pay.CreatedAt()}
+}
+
+// Matches reports whether pay passes every filter and comes after the cursor.
+func (s Search) Matches(pay Entity) bool {
+	switch {
+	case pay.MerchantId() != s.MerchantId,
+		s.OrderId != 0 && pay.OrderID() != s.OrderId,
+		s.Status != "" && pay.Status() != s.Status,
+		s.Type != "" && pay.Type() != s.Type,
+		s.MinAmount != 0 && pay.Amount() < s.MinAmount,
+		s.MaxAmount != 0 && pay.Amount() > s.MaxAmount,
+		!s.CreatedFrom.IsZero() && pay.CreatedAt().Before(s.CreatedFrom),
+		!s.CreatedTo.IsZero() && !pay.CreatedAt().Before(s.CreatedTo):
+		return false
+	}
+
+	return s.After == nil || s.Less(*s.After, CursorOf(pay))
+}
+
+// Less reports whether a is listed before b.
+func (s Search) Less(a, b Cursor) bool {
+	order := 0
+	switch s.Sort {
+	case SortAmount:
+		order = cmp.Compare(a.Amount, b.Amount)
+	case SortCreatedAt:
+		order = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if order == 0 {
+		order = cmp.Compare(a.Id, b.Id)
+	}
+	if s.Descending {
+		order = -order
+	}
+
+	return order < 0
+}
@@ -0,0 +1,37 @@
+package payment
+
+import "sort"
+
+// Summary counts and sums the payments of an order that share a status and
+// a type.
+type Summary struct {
+	Status string
+	Type   string
+	Count  int
+	Amount float64
+}
+
+// Summarize groups payments by status and type, ordered by both.
+func Summarize(payments []Entity) []Summary {
+	var summaries []Summary
+	index := map[[2]string]int{}
+	for _, pay := range payments {
+		key := [2]string{pay.Status(), pay.Type()}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, Summary{Status: pay.Status(), Type: pay.Type()})
+		}
+		summaries[i].Count++
+		summaries[i].Amount += pay.Amount()
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Status != summaries[j].Status {
+			return summaries[i].Status < summaries[j].Status
+		}
+		return summaries[i].Type < summaries[j].Type
+	})
+
+	return summaries
+}
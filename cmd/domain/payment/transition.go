@@ -0,0 +1,17 @@
+package payment
+
+import "time"
+
+// Transition is a status change of a payment. The entity records the ones it
+// goes through and the DAO appends them to the payment's history along with
+// the update, stamped with who asked for it and when.
+type Transition struct {
+	Id        int64
+	PaymentId int64
+	From      string
+	To        string
+	Reason    string
+	Actor     string
+	RequestId string
+	CreatedAt time.Time
+}
@@ -0,0 +1,55 @@
+package paymentmethod
+
+type Builder struct {
+	method *Entity
+}
+
+func NewPaymentMethodBuilder() *Builder {
+	return &Builder{
+		method: &Entity{
+			feeCategory: FeeCategoryFree,
+			enabled:     true,
+		},
+	}
+}
+
+func (b *Builder) WithCode(code string) *Builder {
+	b.method.code = code
+	return b
+}
+
+func (b *Builder) WithFee(category string, rate float64) *Builder {
+	b.method.feeCategory = category
+	b.method.feeRate = rate
+	return b
+}
+
+func (b *Builder) WithTicket(min float64, max float64) *Builder {
+	b.method.minTicket = min
+	b.method.maxTicket = max
+	return b
+}
+
+func (b *Builder) WithInstallments(supported bool) *Builder {
+	b.method.installments = supported
+	return b
+}
+
+func (b *Builder) WithRefunds(supported bool) *Builder {
+	b.method.refunds = supported
+	return b
+}
+
+func (b *Builder) WithAsyncConfirmation(supported bool) *Builder {
+	b.method.asyncConfirmation = supported
+	return b
+}
+
+func (b *Builder) WithEnabled(enabled bool) *Builder {
+	b.method.SetEnabled(enabled)
+	return b
+}
+
+func (b *Builder) Build() *Entity {
+	return b.method
+}
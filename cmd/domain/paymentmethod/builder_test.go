@@ -0,0 +1,39 @@
+package paymentmethod_test
+
+import (
+	"payment-gateway/cmd/domain/paymentmethod"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaymentMethodBuilder(t *testing.T) {
+	t.Run("should create an enabled free method by default", func(t *testing.T) {
+		method := paymentmethod.NewPaymentMethodBuilder().Build()
+
+		assert.True(t, method.Enabled())
+		assert.Equal(t, paymentmethod.FeeCategoryFree, method.FeeCategory())
+	})
+
+	t.Run("should build payment method with all fields set", func(t *testing.T) {
+		method := paymentmethod.NewPaymentMethodBuilder().
+			WithCode("CreditCard").
+			WithFee(paymentmethod.FeeCategoryFinancial, 0.1).
+			WithTicket(1, 100).
+			WithInstallments(true).
+			WithRefunds(true).
+			WithAsyncConfirmation(true).
+			WithEnabled(false).
+			Build()
+
+		assert.Equal(t, "CreditCard", method.Code())
+		assert.Equal(t, "financial_fee", method.FeeCategory())
+		assert.Equal(t, 0.1, method.FeeRate())
+		assert.Equal(t, 1.0, method.MinTicket())
+		assert.Equal(t, 100.0, method.MaxTicket())
+		assert.True(t, method.SupportsInstallments())
+		assert.True(t, method.SupportsRefunds())
+		assert.True(t, method.SupportsAsyncConfirmation())
+		assert.False(t, method.Enabled())
+	})
+}
@@ -0,0 +1,8 @@
+package paymentmethod
+
+import "context"
+
+// Dao stores per-merchant overrides. Methods without an override are enabled.
+type Dao interface {
+	FindSettingsByMerchantId(ctx context.Context, merchantId int64) (map[string]bool, error)
+}
@@ -0,0 +1,84 @@
+package paymentmethod
+
+import (
+	"fmt"
+	exceptions "payment-gateway/cmd/domain/err"
+)
+
+const (
+	FeeCategoryFinancial = "financial_fee"
+	FeeCategoryProcess   = "process_fee"
+	FeeCategoryFree      = "free"
+
+	errTicketOutOfRange = "Amount must be between %.2f and %.2f for %s"
+	errMethodDisabled   = "Payment method %s is not enabled for this merchant"
+)
+
+type Entity struct {
+	code              string
+	feeCategory       string
+	feeRate           float64
+	minTicket         float64
+	maxTicket         float64
+	installments      bool
+	refunds           bool
+	asyncConfirmation bool
+	enabled           bool
+}
+
+func (m *Entity) Code() string {
+	return m.code
+}
+
+func (m *Entity) FeeCategory() string {
+	return m.feeCategory
+}
+
+func (m *Entity) FeeRate() float64 {
+	return m.feeRate
+}
+
+func (m *Entity) MinTicket() float64 {
+	return m.minTicket
+}
+
+func (m *Entity) MaxTicket() float64 {
+	return m.maxTicket
+}
+
+func (m *Entity) SupportsInstallments() bool {
+	return m.installments
+}
+
+func (m *Entity) SupportsRefunds() bool {
+	return m.refunds
+}
+
+func (m *Entity) SupportsAsyncConfirmation() bool {
+	return m.asyncConfirmation
+}
+
+func (m *Entity) Enabled() bool {
+	return m.enabled
+}
+
+func (m *Entity) SetEnabled(enabled bool) {
+	m.enabled = enabled
+}
+
+func (m *Entity) Fee(amount float64) float64 {
+	return amount * m.feeRate
+}
+
+func (m *Entity) CheckEligibility(amount float64) error {
+	if !m.enabled {
+		return exceptions.NewDomainError(exceptions.CodePaymentMethodDisabled, fmt.Sprintf(errMethodDisabled, m.code))
+	}
+
+	if amount < m.minTicket || amount > m.maxTicket {
+		return exceptions.NewDomainError(exceptions.CodePaymentMethodNotEligible,
+			fmt.Sprintf(errTicketOutOfRange, m.minTicket, m.maxTicket, m.code))
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package paymentmethod_test
+
+import (
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEligibility(t *testing.T) {
+	method := paymentmethod.NewPaymentMethodBuilder().WithCode("Card").WithTicket(1, 100).Build()
+
+	t.Run("should accept amounts inside the ticket range", func(t *testing.T) {
+		assert.NoError(t, method.CheckEligibility(1))
+		assert.NoError(t, method.CheckEligibility(100))
+	})
+
+	t.Run("should reject amounts outside the ticket range", func(t *testing.T) {
+		err := method.CheckEligibility(100.01)
+
+		assert.EqualError(t, err, "Amount must be between 1.00 and 100.00 for Card")
+		assert.Equal(t, exceptions.CodePaymentMethodNotEligible, err.(*exceptions.DomainError).Code())
+	})
+
+	t.Run("should reject disabled methods", func(t *testing.T) {
+		disabled := paymentmethod.NewPaymentMethodBuilder().WithCode("Card").WithTicket(1, 100).WithEnabled(false).Build()
+
+		err := disabled.CheckEligibility(10)
+
+		assert.Equal(t, exceptions.CodePaymentMethodDisabled, err.(*exceptions.DomainError).Code())
+	})
+}
+
+func TestFee(t *testing.T) {
+	t.Run("should apply the fee rate", func(t *testing.T) {
+		method := paymentmethod.NewPaymentMethodBuilder().WithFee(paymentmethod.FeeCategoryProcess, 0.2).Build()
+
+		assert.Equal(t, 20.0, method.Fee(100))
+		assert.Equal(t, "process_fee", method.FeeCategory())
+	})
+}
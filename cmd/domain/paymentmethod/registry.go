@@ -0,0 +1,99 @@
+package paymentmethod
+
+import (
+	"fmt"
+	exceptions "payment-gateway/cmd/domain/err"
+	"strings"
+)
+
+const (
+	CreditCard = "CreditCard"
+	CashSlip   = "CashSlip"
+	Cash       = "Cash"
+
+	errUnsupportedMethod = "must be one of "
+)
+
+var registry = []*Entity{
+	NewPaymentMethodBuilder().
+		WithCode(CreditCard).
+		WithFee(FeeCategoryFinancial, 0.1).
+		WithTicket(1, 100000).
+		WithInstallments(true).
+		WithRefunds(true).
+		Build(),
+	NewPaymentMethodBuilder().
+		WithCode(CashSlip).
+		WithFee(FeeCategoryProcess, 0.2).
+		WithTicket(5, 50000).
+		WithAsyncConfirmation(true).
+		Build(),
+	NewPaymentMethodBuilder().
+		WithCode(Cash).
+		WithFee(FeeCategoryFree, 0).
+		WithTicket(0.01, 10000).
+		WithRefunds(true).
+		Build(),
+}
+
+// All returns a copy of every registered payment method, enabled by default.
+func All() []Entity {
+	methods := make([]Entity, 0, len(registry))
+	for _, method := range registry {
+		methods = append(methods, *method)
+	}
+
+	return methods
+}
+
+func Codes() []string {
+	codes := make([]string, 0, len(registry))
+	for _, method := range registry {
+		codes = append(codes, method.code)
+	}
+
+	return codes
+}
+
+func IsRegistered(code string) bool {
+	_, ok := lookup(code)
+	return ok
+}
+
+// Find returns a copy of the payment method registered under code, or a
+// validation error on payment_type when the code is unknown.
+func Find(code string) (*Entity, error) {
+	method, ok := lookup(code)
+	if !ok {
+		return nil, exceptions.NewValidationError(exceptions.FieldError{
+			Field:   "payment_type",
+			Code:    "payment_type",
+			Message: errUnsupportedMethod + strings.Join(Codes(), ", "),
+		})
+	}
+
+	found := *method
+	return &found, nil
+}
+
+// SetFeeRate overrides the fee rate of a registered method, as configured at
+// startup. It must not be called while requests are being served.
+func SetFeeRate(code string, rate float64) error {
+	method, ok := lookup(code)
+	if !ok {
+		return fmt.Errorf("unknown payment method %q", code)
+	}
+
+	method.feeRate = rate
+	return nil
+}
+
+func lookup(code string) (*Entity, bool) {
+	for _, method := range registry {
+		if method.code == code {
+			return method, true
+		}
+	}
+
+	return nil, false
+}
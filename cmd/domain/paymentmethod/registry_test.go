@@ -0,0 +1,60 @@
+package paymentmethod_test
+
+import (
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("should register the supported payment methods", func(t *testing.T) {
+		assert.Equal(t, []string{"CreditCard", "CashSlip", "Cash"}, paymentmethod.Codes())
+		assert.Len(t, paymentmethod.All(), 3)
+	})
+
+	t.Run("should find a registered method", func(t *testing.T) {
+		method, err := paymentmethod.Find(paymentmethod.CashSlip)
+
+		assert.NoError(t, err)
+		assert.Equal(t, paymentmethod.FeeCategoryProcess, method.FeeCategory())
+		assert.True(t, method.SupportsAsyncConfirmation())
+	})
+
+	t.Run("should return copies that do not change the registry", func(t *testing.T) {
+		method, _ := paymentmethod.Find(paymentmethod.Cash)
+		method.SetEnabled(false)
+
+		again, _ := paymentmethod.Find(paymentmethod.Cash)
+		assert.True(t, again.Enabled())
+	})
+
+	t.Run("should return a field error for unknown codes", func(t *testing.T) {
+		method, err := paymentmethod.Find("credit_card")
+
+		assert.Nil(t, method)
+		assert.False(t, paymentmethod.IsRegistered("credit_card"))
+		ex := err.(*exceptions.DomainError)
+		assert.Equal(t, exceptions.CodeValidationFailed, ex.Code())
+		assert.Equal(t, "must be one of CreditCard, CashSlip, Cash", ex.Fields()[0].Message)
+	})
+
+	t.Run("should override the fee rate of a registered method", func(t *testing.T) {
+		original, _ := paymentmethod.Find(paymentmethod.CreditCard)
+		defer paymentmethod.SetFeeRate(paymentmethod.CreditCard, original.FeeRate())
+
+		err := paymentmethod.SetFeeRate(paymentmethod.CreditCard, 0.05)
+
+		assert.NoError(t, err)
+		method, _ := paymentmethod.Find(paymentmethod.CreditCard)
+		assert.Equal(t, 0.05, method.FeeRate())
+		assert.Equal(t, 5.0, method.Fee(100))
+	})
+
+	t.Run("should reject fee overrides for unknown codes", func(t *testing.T) {
+		err := paymentmethod.SetFeeRate("credit_card", 0.05)
+
+		assert.EqualError(t, err, `unknown payment method "credit_card"`)
+	})
+}
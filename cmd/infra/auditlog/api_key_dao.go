@@ -0,0 +1,96 @@
+package auditlog
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/domain/audit"
+	"time"
+)
+
+// apiKeyState keeps the key hash, already a digest of the token, and a
+// SHA-256 digest of the signing secret instead of the secret itself, so a
+// credential swapped in the database is caught without the log leaking it.
+type apiKeyState struct {
+	Id                  int64      `json:"id"`
+	MerchantId          int64      `json:"merchant_id"`
+	Name                string     `json:"name"`
+	Prefix              string     `json:"prefix"`
+	KeyHash             string     `json:"key_hash"`
+	SigningSecretSha256 string     `json:"signing_secret_sha256"`
+	Scopes              []string   `json:"scopes"`
+	Mode                string     `json:"mode"`
+	RevokedAt           *time.Time `json:"revoked_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func apiKeyStateOf(key *apikey.Entity) apiKeyState {
+	state := apiKeyState{
+		Id:                  key.Id(),
+		MerchantId:          key.MerchantId(),
+		Name:                key.Name(),
+		Prefix:              key.Prefix(),
+		KeyHash:             key.Hash(),
+		SigningSecretSha256: apikey.Hash(key.SigningSecret()),
+		Scopes:              key.Scopes(),
+		Mode:                key.Mode(),
+		UpdatedAt:           key.UpdatedAt(),
+	}
+	if key.IsRevoked() {
+		revokedAt := key.RevokedAt()
+		state.RevokedAt = &revokedAt
+	}
+
+	return state
+}
+
+// ApiKeyDao records the inserts and updates of the apikey.Dao it wraps.
+// TouchLastUsed only bookkeeps when the key was last used, so it is left
+// out.
+type ApiKeyDao struct {
+	apikey.Dao
+	recorder *Recorder
+}
+
+func NewApiKeyDao(apiKeyDao apikey.Dao, recorder *Recorder) *ApiKeyDao {
+	return &ApiKeyDao{
+		Dao:      apiKeyDao,
+		recorder: recorder,
+	}
+}
+
+func (a *ApiKeyDao) Insert(ctx context.Context, key *apikey.Entity) (saved *apikey.Entity, err error) {
+	err = a.recorder.transaction(ctx, func(ctx context.Context) error {
+		saved, err = a.Dao.Insert(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		return a.recorder.record(ctx, audit.EntityApiKey, saved.Id(), audit.ActionInsert, nil, apiKeyStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (a *ApiKeyDao) Update(ctx context.Context, key *apikey.Entity) (saved *apikey.Entity, err error) {
+	err = a.recorder.transaction(ctx, func(ctx context.Context) error {
+		before, err := a.Dao.FindById(ctx, key.Id())
+		if err != nil {
+			return err
+		}
+
+		saved, err = a.Dao.Update(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		return a.recorder.record(ctx, audit.EntityApiKey, saved.Id(), audit.ActionUpdate, apiKeyStateOf(before), apiKeyStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,324 @@
+package auditlog_test
+
+import (
+	"context"
+	"encoding/json"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/auditlog"
+	"payment-gateway/cmd/infra/dao/memory"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/testhelpers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var auditKey = []byte("0123456789abcdef0123456789abcdef")
+
+type fixture struct {
+	store    *memory.Store
+	audit    *memory.AuditDao
+	payments *auditlog.PaymentDao
+	orders   *auditlog.OrderDao
+	charges  *auditlog.ChargeDao
+	orderId  int64
+}
+
+func setup(t *testing.T) fixture {
+	store := memory.NewStore()
+	auditDao := memory.NewAuditDao(store, auditKey)
+	recorder := auditlog.NewRecorder(auditDao, store)
+	orders := memory.NewOrderDao(store)
+
+	created, err := orders.Insert(context.Background(), order.NewOrderBuilder().WithStatus("pending").WithAmount(100).Build())
+	require.NoError(t, err)
+
+	return fixture{
+		store:    store,
+		audit:    auditDao,
+		payments: auditlog.NewPaymentDao(memory.NewPaymentDao(store), recorder),
+		orders:   auditlog.NewOrderDao(orders, recorder),
+		charges:  auditlog.NewChargeDao(memory.NewChargeDao(store), recorder),
+		orderId:  created.Id(),
+	}
+}
+
+func entries(t *testing.T, f fixture) []audit.Entry {
+	found, err := f.audit.FindAfter(context.Background(), 0, 100)
+	require.NoError(t, err)
+
+	return found
+}
+
+func state(t *testing.T, raw string) map[string]any {
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &decoded))
+
+	return decoded
+}
+
+func TestPaymentDao(t *testing.T) {
+	ctx := logging.WithActor(logging.WithRequestId(context.Background(), "req-1"), "api_key:abc123")
+
+	t.Run("should record the inserted payment with who inserted it", func(t *testing.T) {
+		f := setup(t)
+
+		pay, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 40, "CreditCard"))
+		require.NoError(t, err)
+
+		logged := entries(t, f)
+		if assert.Len(t, logged, 1) {
+			assert.Equal(t, audit.EntityPayment, logged[0].Entity)
+			assert.Equal(t, pay.Id(), logged[0].EntityId)
+			assert.Equal(t, audit.ActionInsert, logged[0].Action)
+			assert.Empty(t, logged[0].Before)
+			assert.Equal(t, "pending", state(t, logged[0].After)["status"])
+			assert.Equal(t, "api_key:abc123", logged[0].Actor)
+			assert.Equal(t, "req-1", logged[0].RequestId)
+		}
+	})
+
+	t.Run("should record the payment before and after an update", func(t *testing.T) {
+		f := setup(t)
+		pay, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 40, "CreditCard"))
+		require.NoError(t, err)
+
+		require.NoError(t, pay.Process("Success", "ok"))
+		_, err = f.payments.Update(ctx, pay)
+		require.NoError(t, err)
+
+		logged := entries(t, f)
+		if assert.Len(t, logged, 2) {
+			assert.Equal(t, audit.ActionUpdate, logged[1].Action)
+			assert.Equal(t, "pending", state(t, logged[1].Before)["status"])
+			assert.Equal(t, "approved", state(t, logged[1].After)["status"])
+			assert.Equal(t, logged[0].Hash, logged[1].PrevHash)
+		}
+	})
+
+	t.Run("should record nothing when the change fails", func(t *testing.T) {
+		f := setup(t)
+
+		_, err := f.payments.Update(ctx, payment.NewPaymentBuilder().WithId(99).Build())
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Empty(t, entries(t, f))
+	})
+
+	t.Run("should drop the entry along with a transaction that fails", func(t *testing.T) {
+		f := setup(t)
+
+		err := f.store.Transaction(ctx, func(ctx context.Context) error {
+			if _, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 40, "CreditCard")); err != nil {
+				return err
+			}
+			return assert.AnError
+		})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, entries(t, f))
+	})
+}
+
+func TestOrderAndChargeDao(t *testing.T) {
+	t.Run("should record the inserted order", func(t *testing.T) {
+		f := setup(t)
+
+		or, err := f.orders.Insert(context.Background(), order.NewOrderBuilder().WithMerchantId(3).WithStatus("pending").WithAmount(80).Build())
+		require.NoError(t, err)
+
+		logged := entries(t, f)
+		if assert.Len(t, logged, 1) {
+			assert.Equal(t, audit.EntityOrder, logged[0].Entity)
+			assert.Equal(t, or.Id(), logged[0].EntityId)
+			assert.Equal(t, audit.ActionInsert, logged[0].Action)
+			assert.Empty(t, logged[0].Before)
+			assert.Equal(t, 3.0, state(t, logged[0].After)["merchant_id"])
+			assert.Equal(t, 80.0, state(t, logged[0].After)["amount"])
+		}
+	})
+
+	t.Run("should chain the order update and the charge of a processed payment", func(t *testing.T) {
+		f := setup(t)
+		ctx := context.Background()
+		pay, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+
+		err = f.store.Transaction(ctx, func(ctx context.Context) error {
+			or, err := f.orders.FindByIdForUpdate(ctx, f.orderId)
+			if err != nil {
+				return err
+			}
+			if err := pay.Process("Success", "ok"); err != nil {
+				return err
+			}
+			if err := or.ProcessPayment(*pay); err != nil {
+				return err
+			}
+			newCharge, _ := charge.NewCharge(*pay)
+			or.AddCharge(newCharge.Amount())
+
+			if _, err := f.payments.Update(ctx, pay); err != nil {
+				return err
+			}
+			if _, err := f.orders.Update(ctx, or); err != nil {
+				return err
+			}
+			_, err = f.charges.Insert(ctx, newCharge)
+			return err
+		})
+		require.NoError(t, err)
+
+		logged := entries(t, f)
+		if assert.Len(t, logged, 4) {
+			assert.Equal(t, audit.EntityOrder, logged[2].Entity)
+			assert.Equal(t, "pending", state(t, logged[2].Before)["status"])
+			assert.Equal(t, "paid", state(t, logged[2].After)["status"])
+			assert.Equal(t, 100.0, state(t, logged[2].After)["paid_amount"])
+			assert.Equal(t, audit.EntityCharge, logged[3].Entity)
+			assert.Equal(t, float64(pay.Id()), state(t, logged[3].After)["payment_id"])
+		}
+
+		verification := audit.NewVerification(auditKey)
+		for _, entry := range logged {
+			require.True(t, verification.Add(entry), verification.Reason)
+		}
+	})
+}
+
+func TestStates(t *testing.T) {
+	ctx := context.Background()
+	states := func(f fixture) *auditlog.States {
+		return auditlog.NewStates(memory.NewPaymentDao(f.store), memory.NewOrderDao(f.store), memory.NewChargeDao(f.store), new(testhelpers.MockApiKeyDao))
+	}
+
+	t.Run("should read a row in the state its last entry recorded", func(t *testing.T) {
+		f := setup(t)
+		pay, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 40, "CreditCard"))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", "ok"))
+		_, err = f.payments.Update(ctx, pay)
+		require.NoError(t, err)
+
+		current, found, err := states(f).State(ctx, audit.EntityPayment, pay.Id())
+
+		require.NoError(t, err)
+		assert.True(t, found)
+		field, err := audit.Diff(entries(t, f)[1].After, current)
+		require.NoError(t, err)
+		assert.Empty(t, field)
+	})
+
+	t.Run("should tell a row changed without going through the log", func(t *testing.T) {
+		f := setup(t)
+		pay, err := f.payments.Insert(ctx, payment.NewPayment(f.orderId, 40, "CreditCard"))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", "ok"))
+		_, err = memory.NewPaymentDao(f.store).Update(ctx, pay)
+		require.NoError(t, err)
+
+		current, _, err := states(f).State(ctx, audit.EntityPayment, pay.Id())
+
+		require.NoError(t, err)
+		field, err := audit.Diff(entries(t, f)[0].After, current)
+		require.NoError(t, err)
+		assert.Equal(t, "details", field)
+	})
+
+	t.Run("should tell an api key whose signing secret was swapped", func(t *testing.T) {
+		f := setup(t)
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		logged := apikey.NewApiKeyBuilder().WithId(4).WithHash("hash").WithSigningSecret("secret").WithScopes("read").Build()
+		swapped := apikey.NewApiKeyBuilder().WithId(4).WithHash("hash").WithSigningSecret("other").WithScopes("read").Build()
+		swapped.SetUpdatedAt(logged.UpdatedAt())
+		mockApiKeyDao.On("FindById", int64(4)).Return(swapped, nil)
+		mockApiKeyDao.On("Insert", logged).Return(logged, nil)
+		_, err := auditlog.NewApiKeyDao(mockApiKeyDao, auditlog.NewRecorder(f.audit, f.store)).Insert(ctx, logged)
+		require.NoError(t, err)
+
+		current, found, err := auditlog.NewStates(memory.NewPaymentDao(f.store), memory.NewOrderDao(f.store), memory.NewChargeDao(f.store), mockApiKeyDao).State(ctx, audit.EntityApiKey, 4)
+
+		require.NoError(t, err)
+		assert.True(t, found)
+		field, err := audit.Diff(entries(t, f)[0].After, current)
+		require.NoError(t, err)
+		assert.Equal(t, "signing_secret_sha256", field)
+	})
+
+	t.Run("should report a row that no longer exists", func(t *testing.T) {
+		f := setup(t)
+
+		_, found, err := states(f).State(ctx, audit.EntityCharge, 99)
+
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should reject an entity the log does not record", func(t *testing.T) {
+		f := setup(t)
+
+		_, _, err := states(f).State(ctx, "merchant", 1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestApiKeyDao(t *testing.T) {
+	t.Run("should record the key hash and only a digest of the signing secret", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		key := apikey.NewApiKeyBuilder().WithId(4).WithPrefix("abc123").WithHash("hash").WithSigningSecret("secret").WithScopes("read").Build()
+		mockApiKeyDao.On("Insert", key).Return(key, nil)
+		mockAuditDao.On("Append", mock.Anything).Return(&audit.Entry{}, nil)
+		dao := auditlog.NewApiKeyDao(mockApiKeyDao, auditlog.NewRecorder(mockAuditDao, testhelpers.NewNopTransactor()))
+
+		_, err := dao.Insert(context.Background(), key)
+
+		require.NoError(t, err)
+		entry := mockAuditDao.Calls[0].Arguments.Get(0).(*audit.Entry)
+		assert.Equal(t, audit.EntityApiKey, entry.Entity)
+		assert.Equal(t, "abc123", state(t, entry.After)["prefix"])
+		assert.Equal(t, "hash", state(t, entry.After)["key_hash"])
+		assert.Equal(t, apikey.Hash("secret"), state(t, entry.After)["signing_secret_sha256"])
+		assert.NotContains(t, entry.After, `"secret"`)
+	})
+
+	t.Run("should record a revocation", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		stored := apikey.NewApiKeyBuilder().WithId(4).WithPrefix("abc123").WithScopes("read").Build()
+		key := apikey.NewApiKeyBuilder().WithId(4).WithPrefix("abc123").WithScopes("read").Build()
+		require.NoError(t, key.Revoke())
+		mockApiKeyDao.On("FindById", int64(4)).Return(stored, nil)
+		mockApiKeyDao.On("Update", key).Return(key, nil)
+		mockAuditDao.On("Append", mock.Anything).Return(&audit.Entry{}, nil)
+		dao := auditlog.NewApiKeyDao(mockApiKeyDao, auditlog.NewRecorder(mockAuditDao, testhelpers.NewNopTransactor()))
+
+		_, err := dao.Update(context.Background(), key)
+
+		require.NoError(t, err)
+		entry := mockAuditDao.Calls[0].Arguments.Get(0).(*audit.Entry)
+		assert.Nil(t, state(t, entry.Before)["revoked_at"])
+		assert.NotNil(t, state(t, entry.After)["revoked_at"])
+	})
+
+	t.Run("should fail the change when the log cannot be appended", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		key := apikey.NewApiKeyBuilder().WithId(4).WithScopes("read").Build()
+		mockApiKeyDao.On("Insert", key).Return(key, nil)
+		mockAuditDao.On("Append", mock.Anything).Return(nil, assert.AnError)
+		dao := auditlog.NewApiKeyDao(mockApiKeyDao, auditlog.NewRecorder(mockAuditDao, testhelpers.NewNopTransactor()))
+
+		saved, err := dao.Insert(context.Background(), key)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, saved)
+	})
+}
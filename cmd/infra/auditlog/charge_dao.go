@@ -0,0 +1,55 @@
+package auditlog
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/charge"
+	"time"
+)
+
+type chargeState struct {
+	Id        int64     `json:"id"`
+	PaymentId int64     `json:"payment_id"`
+	Category  string    `json:"category"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func chargeStateOf(c *charge.Entity) chargeState {
+	return chargeState{
+		Id:        c.Id(),
+		PaymentId: c.PaymentId(),
+		Category:  c.Category(),
+		Amount:    c.Amount(),
+		CreatedAt: c.CreatedAt(),
+	}
+}
+
+// ChargeDao records the inserts of the charge.Dao it wraps.
+type ChargeDao struct {
+	charge.Dao
+	recorder *Recorder
+}
+
+func NewChargeDao(chargeDao charge.Dao, recorder *Recorder) *ChargeDao {
+	return &ChargeDao{
+		Dao:      chargeDao,
+		recorder: recorder,
+	}
+}
+
+func (c *ChargeDao) Insert(ctx context.Context, ch *charge.Entity) (saved *charge.Entity, err error) {
+	err = c.recorder.transaction(ctx, func(ctx context.Context) error {
+		saved, err = c.Dao.Insert(ctx, ch)
+		if err != nil {
+			return err
+		}
+
+		return c.recorder.record(ctx, audit.EntityCharge, saved.Id(), audit.ActionInsert, nil, chargeStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
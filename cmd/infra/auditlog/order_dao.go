@@ -0,0 +1,82 @@
+package auditlog
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/order"
+	"time"
+)
+
+type orderState struct {
+	Id             int64     `json:"id"`
+	MerchantId     int64     `json:"merchant_id"`
+	Status         string    `json:"status"`
+	Amount         float64   `json:"amount"`
+	PaidAmount     float64   `json:"paid_amount"`
+	ChargesAmount  float64   `json:"charges_amount"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func orderStateOf(or *order.Entity) orderState {
+	return orderState{
+		Id:             or.Id(),
+		MerchantId:     or.MerchantId(),
+		Status:         or.Status(),
+		Amount:         or.Amount(),
+		PaidAmount:     or.PaidAmount(),
+		ChargesAmount:  or.ChargesAmount(),
+		RefundedAmount: or.RefundedAmount(),
+		UpdatedAt:      or.UpdatedAt(),
+	}
+}
+
+// OrderDao records the inserts and updates of the order.Dao it wraps.
+type OrderDao struct {
+	order.Dao
+	recorder *Recorder
+}
+
+func NewOrderDao(orderDao order.Dao, recorder *Recorder) *OrderDao {
+	return &OrderDao{
+		Dao:      orderDao,
+		recorder: recorder,
+	}
+}
+
+func (o *OrderDao) Insert(ctx context.Context, or *order.Entity) (saved *order.Entity, err error) {
+	err = o.recorder.transaction(ctx, func(ctx context.Context) error {
+		saved, err = o.Dao.Insert(ctx, or)
+		if err != nil {
+			return err
+		}
+
+		return o.recorder.record(ctx, audit.EntityOrder, saved.Id(), audit.ActionInsert, nil, orderStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (o *OrderDao) Update(ctx context.Context, or *order.Entity) (saved *order.Entity, err error) {
+	err = o.recorder.transaction(ctx, func(ctx context.Context) error {
+		before, err := o.Dao.FindById(ctx, or.Id())
+		if err != nil {
+			return err
+		}
+
+		saved, err = o.Dao.Update(ctx, or)
+		if err != nil {
+			return err
+		}
+
+		return o.recorder.record(ctx, audit.EntityOrder, saved.Id(), audit.ActionUpdate, orderStateOf(before), orderStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,84 @@
+package auditlog
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/payment"
+	"time"
+)
+
+type paymentState struct {
+	Id         int64     `json:"id"`
+	MerchantId int64     `json:"merchant_id"`
+	OrderId    int64     `json:"order_id"`
+	Status     string    `json:"status"`
+	Type       string    `json:"payment_type"`
+	Amount     float64   `json:"amount"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func paymentStateOf(pay *payment.Entity) paymentState {
+	return paymentState{
+		Id:         pay.Id(),
+		MerchantId: pay.MerchantId(),
+		OrderId:    pay.OrderID(),
+		Status:     pay.Status(),
+		Type:       pay.Type(),
+		Amount:     pay.Amount(),
+		Details:    pay.Details(),
+		CreatedAt:  pay.CreatedAt(),
+		UpdatedAt:  pay.UpdatedAt(),
+	}
+}
+
+// PaymentDao records the inserts and updates of the payment.Dao it wraps.
+type PaymentDao struct {
+	payment.Dao
+	recorder *Recorder
+}
+
+func NewPaymentDao(paymentDao payment.Dao, recorder *Recorder) *PaymentDao {
+	return &PaymentDao{
+		Dao:      paymentDao,
+		recorder: recorder,
+	}
+}
+
+func (p *PaymentDao) Insert(ctx context.Context, pay *payment.Entity) (saved *payment.Entity, err error) {
+	err = p.recorder.transaction(ctx, func(ctx context.Context) error {
+		saved, err = p.Dao.Insert(ctx, pay)
+		if err != nil {
+			return err
+		}
+
+		return p.recorder.record(ctx, audit.EntityPayment, saved.Id(), audit.ActionInsert, nil, paymentStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (p *PaymentDao) Update(ctx context.Context, pay *payment.Entity) (saved *payment.Entity, err error) {
+	err = p.recorder.transaction(ctx, func(ctx context.Context) error {
+		before, err := p.Dao.FindById(ctx, pay.Id())
+		if err != nil {
+			return err
+		}
+
+		saved, err = p.Dao.Update(ctx, pay)
+		if err != nil {
+			return err
+		}
+
+		return p.recorder.record(ctx, audit.EntityPayment, saved.Id(), audit.ActionUpdate, paymentStateOf(before), paymentStateOf(saved))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
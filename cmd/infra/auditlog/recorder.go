@@ -0,0 +1,64 @@
+// Package auditlog wraps the DAOs that change payments, orders, charges and
+// api keys so every change they make is appended to the audit log in the
+// same transaction.
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/infra/logging"
+	"time"
+)
+
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Recorder appends entries for the DAOs of this package. A change and its
+// entry commit together, joining the caller's transaction when there is one.
+type Recorder struct {
+	auditDao   audit.Dao
+	transactor Transactor
+}
+
+func NewRecorder(auditDao audit.Dao, transactor Transactor) *Recorder {
+	return &Recorder{
+		auditDao:   auditDao,
+		transactor: transactor,
+	}
+}
+
+func (r *Recorder) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.Transaction(ctx, fn)
+}
+
+// record appends a change of the entity. before is nil on insert; the states
+// are the columns the DAO writes, as JSON.
+func (r *Recorder) record(ctx context.Context, entity string, entityId int64, action string, before, after any) error {
+	entry := &audit.Entry{
+		Entity:    entity,
+		EntityId:  entityId,
+		Action:    action,
+		Actor:     logging.Actor(ctx),
+		RequestId: logging.RequestId(ctx),
+		CreatedAt: time.Now(),
+	}
+
+	if before != nil {
+		state, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		entry.Before = string(state)
+	}
+
+	state, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	entry.After = string(state)
+
+	_, err = r.auditDao.Append(ctx, entry)
+	return err
+}
@@ -0,0 +1,75 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+)
+
+// States reads the entities the DAOs of this package record, turned into the
+// states their entries hold.
+type States struct {
+	paymentDao payment.Dao
+	orderDao   order.Dao
+	chargeDao  charge.Dao
+	apiKeyDao  apikey.Dao
+}
+
+func NewStates(paymentDao payment.Dao, orderDao order.Dao, chargeDao charge.Dao, apiKeyDao apikey.Dao) *States {
+	return &States{
+		paymentDao: paymentDao,
+		orderDao:   orderDao,
+		chargeDao:  chargeDao,
+		apiKeyDao:  apiKeyDao,
+	}
+}
+
+func (s *States) State(ctx context.Context, entity string, entityId int64) (string, bool, error) {
+	var state any
+	var err error
+	switch entity {
+	case audit.EntityPayment:
+		var pay *payment.Entity
+		if pay, err = s.paymentDao.FindById(ctx, entityId); err == nil {
+			state = paymentStateOf(pay)
+		}
+	case audit.EntityOrder:
+		var or *order.Entity
+		if or, err = s.orderDao.FindById(ctx, entityId); err == nil {
+			state = orderStateOf(or)
+		}
+	case audit.EntityCharge:
+		var c *charge.Entity
+		if c, err = s.chargeDao.FindById(ctx, entityId); err == nil {
+			state = chargeStateOf(c)
+		}
+	case audit.EntityApiKey:
+		var key *apikey.Entity
+		if key, err = s.apiKeyDao.FindById(ctx, entityId); err == nil {
+			state = apiKeyStateOf(key)
+		}
+	default:
+		return "", false, fmt.Errorf("unknown audited entity %q", entity)
+	}
+
+	switch {
+	case errors.Is(err, payment.ErrNotFound), errors.Is(err, order.ErrNotFound),
+		errors.Is(err, charge.ErrNotFound), errors.Is(err, apikey.ErrNotFound):
+		return "", false, nil
+	case err != nil:
+		return "", false, err
+	}
+
+	current, err := json.Marshal(state)
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(current), true, nil
+}
@@ -0,0 +1,32 @@
+package conf
+
+import (
+	"context"
+	"errors"
+)
+
+// Start launches the background workers.
+func (r *Runtime) Start(ctx context.Context) {
+	for _, w := range r.workers {
+		w.Start(ctx)
+	}
+}
+
+// Drain fails readiness so no new traffic is routed to this instance.
+func (r *Runtime) Drain() {
+	r.readiness.Shutdown()
+}
+
+// Stop waits for the workers to finish and closes the database pools.
+func (r *Runtime) Stop(ctx context.Context) error {
+	var errs []error
+	for _, w := range r.workers {
+		errs = append(errs, w.Stop(ctx))
+	}
+	errs = append(errs, r.db.Close())
+	for _, replica := range r.replicas {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,17 +2,34 @@ package conf
 
 import (
 	"github.com/gin-gonic/gin"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/middleware"
 )
 
 func Routes(engine *gin.Engine, run *Runtime) {
-	engine.POST("/payments", run.CreatePaymentHandler.Execute)
-	engine.POST("/payments/:id/process", run.ProcessPaymentHandler.Execute)
-	engine.GET("/orders/:id", run.GetCashoutHandler.Execute)
-	engine.GET("/health", HealthHandler())
-}
+	engine.Use(middleware.RequestId(), run.Tracing, run.AccessLog, run.RequestMetrics, middleware.ErrorHandler(), run.Timeout)
+	engine.NoRoute(middleware.NotFound())
+
+	engine.GET("/livez", run.LivezHandler.Execute)
+	engine.GET("/readyz", run.ReadyzHandler.Execute)
+	engine.GET("/metrics", run.MetricsHandler)
+
+	signed := engine.Group("/", run.RateLimitIP, run.AuthenticateSigned, run.RateLimit)
+	signed.POST("/payments", middleware.RequireScope(apikey.ScopeWrite), run.CreatePaymentHandler.Execute)
+	signed.POST("/payments/:id/process", middleware.RequireScope(apikey.ScopeWrite), run.ProcessPaymentHandler.Execute)
+
+	api := engine.Group("/", run.RateLimitIP, run.Authenticate, run.RateLimit)
+	api.GET("/orders/:id", middleware.RequireScope(apikey.ScopeRead), run.GetCashoutHandler.Execute)
+	api.GET("/orders/:id/payments", middleware.RequireScope(apikey.ScopeRead), run.ListOrderPaymentsHandler.Execute)
+	api.GET("/orders/:id/charges", middleware.RequireScope(apikey.ScopeRead), run.ListOrderChargesHandler.Execute)
+	api.GET("/orders/:id/timeline", middleware.RequireScope(apikey.ScopeRead), run.GetOrderTimelineHandler.Execute)
+	api.GET("/payments", middleware.RequireScope(apikey.ScopeRead), run.SearchPaymentsHandler.Execute)
+	api.GET("/payments/:id", middleware.RequireScope(apikey.ScopeRead), run.GetPaymentHandler.Execute)
+	api.GET("/payments/:id/timeline", middleware.RequireScope(apikey.ScopeRead), run.GetPaymentTimelineHandler.Execute)
+	api.GET("/payment-methods", middleware.RequireScope(apikey.ScopeRead), run.ListPaymentMethodsHandler.Execute)
 
-func HealthHandler() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		ctx.JSON(200, gin.H{"status": "ok"})
-	}
+	api.POST("/api-keys", middleware.RequireScope(apikey.ScopeAdmin), run.CreateApiKeyHandler.Execute)
+	api.GET("/api-keys", middleware.RequireScope(apikey.ScopeAdmin), run.ListApiKeysHandler.Execute)
+	api.DELETE("/api-keys/:id", middleware.RequireScope(apikey.ScopeAdmin), run.RevokeApiKeyHandler.Execute)
+	api.POST("/api-keys/:id/rotate", middleware.RequireScope(apikey.ScopeAdmin), run.RotateApiKeyHandler.Execute)
 }
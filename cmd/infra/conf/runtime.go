@@ -1,44 +1,338 @@
 package conf
 
 import (
-	"payment-gateway/cmd/infra"
+	"context"
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"payment-gateway/cmd/infra/auditlog"
+	"payment-gateway/cmd/infra/config"
 	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
 	"payment-gateway/cmd/infra/db/mysql"
+	"payment-gateway/cmd/infra/db/postgres"
+	"payment-gateway/cmd/infra/db/sqlite"
 	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/health"
+	"payment-gateway/cmd/infra/metrics"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/migrate"
+	"payment-gateway/cmd/infra/ratelimit"
+	"payment-gateway/cmd/infra/tracing"
+	"payment-gateway/cmd/infra/worker"
 	"payment-gateway/cmd/usecases"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Runtime struct {
 	CreatePaymentHandler  handler.Handler
 	ProcessPaymentHandler handler.Handler
 	GetCashoutHandler     handler.Handler
+	GetPaymentHandler     handler.Handler
+	SearchPaymentsHandler handler.Handler
+
+	ListOrderPaymentsHandler handler.Handler
+	ListOrderChargesHandler  handler.Handler
+	GetOrderTimelineHandler  handler.Handler
+
+	GetPaymentTimelineHandler handler.Handler
+
+	CreateApiKeyHandler handler.Handler
+	ListApiKeysHandler  handler.Handler
+	RevokeApiKeyHandler handler.Handler
+	RotateApiKeyHandler handler.Handler
+
+	ListPaymentMethodsHandler handler.Handler
+
+	LivezHandler  handler.Handler
+	ReadyzHandler handler.Handler
+
+	Authenticate       gin.HandlerFunc
+	AuthenticateSigned gin.HandlerFunc
+	RateLimit          gin.HandlerFunc
+	RateLimitIP        gin.HandlerFunc
+	Timeout            gin.HandlerFunc
+	AccessLog          gin.HandlerFunc
+	RequestMetrics     gin.HandlerFunc
+	Tracing            gin.HandlerFunc
+	MetricsHandler     gin.HandlerFunc
+
+	db        *sql.DB
+	replicas  []*dbclient.Replica
+	readiness *health.Readiness
+	workers   []*worker.Worker
 }
 
-func NewRuntime(configuration *infra.Configuration) *Runtime {
+// OpenDatabase connects to the database selected by the driver setting.
+func OpenDatabase(configuration config.Database, logger *slog.Logger) (*sql.DB, dbclient.Dialect, error) {
+	dialect, err := dbclient.DialectFor(configuration.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var db *sql.DB
+	switch configuration.Driver {
+	case dbclient.DriverPostgres:
+		db, err = postgres.NewPostgresClient(configuration, logger)
+	case dbclient.DriverSQLite:
+		db, err = sqlite.NewSQLiteClient(configuration, logger)
+	default:
+		db, err = mysql.NewMySQLClient(configuration, logger)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, dialect, nil
+}
+
+// OpenReplicas connects to every read replica with the settings of the
+// primary, closing the ones already open when one fails.
+func OpenReplicas(configuration config.Database, logger *slog.Logger) ([]*dbclient.Replica, error) {
+	replicas := make([]*dbclient.Replica, 0, len(configuration.Replicas))
+	for _, addr := range configuration.Replicas {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			configuration.Host = host
+			configuration.Port, err = strconv.Atoi(port)
+		}
+		var db *sql.DB
+		if err == nil {
+			db, _, err = OpenDatabase(configuration, logger.With(slog.String("replica", addr)))
+		}
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, err
+		}
+		replicas = append(replicas, dbclient.NewReplica(addr, db))
+	}
+
+	return replicas, nil
+}
+
+// NewMigrator builds the migrator for the migrations embedded in the binary.
+func NewMigrator(db *sql.DB, dialect dbclient.Dialect, configuration config.Migrate, logger *slog.Logger) (*migrate.Migrator, error) {
+	migrations, err := migrate.Embedded(dialect.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, dialect, migrations, migrate.Options{
+		Dev:         configuration.DevSeed,
+		LockTimeout: configuration.LockTimeout,
+	}, logger), nil
+}
+
+// NewVerifyAuditLog builds the check of the audit log chain sealed with
+// secret and of the rows it records, reading from the primary.
+func NewVerifyAuditLog(db *sql.DB, dialect dbclient.Dialect, secret string) *usecases.VerifyAuditLog {
+	client := dbclient.NewReboundClient(dbclient.NewTxClient(db), dialect)
+	states := auditlog.NewStates(
+		dao.NewPaymentDao(client, dialect),
+		dao.NewOrderDao(client, dialect),
+		dao.NewChargeDao(client, dialect),
+		dao.NewApiKeyDao(client, dialect),
+	)
+
+	return usecases.NewVerifyAuditLog(dao.NewAuditDao(client, dialect, []byte(secret)), states, []byte(secret))
+}
+
+func NewRuntime(configuration *config.Configuration, logger *slog.Logger, tracerProvider trace.TracerProvider) (*Runtime, error) {
+	// Apply Fees
+	fees := map[string]float64{
+		paymentmethod.CreditCard: configuration.Fees.CreditCard,
+		paymentmethod.CashSlip:   configuration.Fees.CashSlip,
+		paymentmethod.Cash:       configuration.Fees.Cash,
+	}
+	for code, rate := range fees {
+		if err := paymentmethod.SetFeeRate(code, rate); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create DB
-	db, err := mysql.NewMySQLClient(configuration)
+	db, dialect, err := OpenDatabase(configuration.Database, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply Migrations
+	migrator, err := NewMigrator(db, dialect, configuration.Migrate, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if configuration.Migrate.OnStart {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	replicas, err := OpenReplicas(configuration.Database, logger)
 	if err != nil {
-		panic(err)
+		db.Close()
+		return nil, err
 	}
 
+	gatewayMetrics := metrics.New()
+	instrument := func(client dbclient.Client) dbclient.Client {
+		return dbclient.NewLoggedClient(metrics.NewClient(tracing.NewClient(dbclient.NewReboundClient(client, dialect), tracerProvider, dialect.Name()), gatewayMetrics), logger)
+	}
+	client := instrument(dbclient.NewTxClient(db))
+
+	// Reads that tolerate replication lag go to the replicas, or to the
+	// primary while none is in rotation.
+	replicaClient := dbclient.NewReplicaClient(dbclient.NewTxClient(db), replicas, dialect, configuration.Database.ReplicaMaxLag, logger)
+	replicaClient.CheckLag(context.Background())
+	readClient := instrument(replicaClient)
+
 	// Create DAOs
-	paymentDao := dao.NewPaymentDao(db)
-	chargeDao := dao.NewChargeDao(db)
-	orderDao := dao.NewOrderDao(db)
+	transactor := dbclient.NewTransactor(db)
+	recorder := auditlog.NewRecorder(dao.NewAuditDao(client, dialect, []byte(configuration.Audit.Secret)), transactor)
+	paymentDao := auditlog.NewPaymentDao(dao.NewPaymentDao(client, dialect), recorder)
+	chargeDao := auditlog.NewChargeDao(dao.NewChargeDao(client, dialect), recorder)
+	orderDao := auditlog.NewOrderDao(dao.NewOrderDao(client, dialect), recorder)
+	apiKeyDao := auditlog.NewApiKeyDao(dao.NewApiKeyDao(client, dialect), recorder)
+	nonceDao := dao.NewNonceDao(client, dialect)
+	paymentMethodDao := dao.NewPaymentMethodDao(client)
+
+	readPaymentDao := dao.NewPaymentDao(readClient, dialect)
+	readChargeDao := dao.NewChargeDao(readClient, dialect)
+	readOrderDao := dao.NewOrderDao(readClient, dialect)
+	readApiKeyDao := dao.NewApiKeyDao(readClient, dialect)
+	readPaymentMethodDao := dao.NewPaymentMethodDao(readClient)
+
+	// Create Rate Limiter
+	rateLimitPolicy := ratelimit.Policy{
+		PerIP:       configuration.RateLimit.PerIP,
+		PerKey:      configuration.RateLimit.PerKey,
+		PerMerchant: configuration.RateLimit.PerMerchant,
+		PerRoute:    configuration.RateLimit.PerRoute,
+	}
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	var sqlRateLimitStore *ratelimit.SQLStore
+	if configuration.RateLimit.Store == "database" || configuration.RateLimit.Store == "mysql" {
+		sqlRateLimitStore = ratelimit.NewSQLStore(db, dialect)
+		rateLimitStore = sqlRateLimitStore
+	}
+	limiter := ratelimit.NewLimiter(rateLimitStore, rateLimitPolicy)
 
 	// Create Use Cases
-	createPayment := usecases.NewCreatePayment(paymentDao, orderDao)
-	processPayment := usecases.NewProcessPayment(paymentDao, chargeDao, orderDao)
-	getCashout := usecases.NewGetCashout(paymentDao, orderDao, chargeDao)
+	createPayment := usecases.NewCreatePayment(paymentDao, orderDao, paymentMethodDao, logger, gatewayMetrics)
+	processPayment := usecases.NewProcessPayment(paymentDao, chargeDao, orderDao, transactor, logger, gatewayMetrics)
+	getCashout := usecases.NewGetCashout(readOrderDao, readPaymentDao, readChargeDao)
+	getPayment := usecases.NewGetPayment(paymentDao)
+	searchPayments := usecases.NewSearchPayments(readPaymentDao)
+	listOrderPayments := usecases.NewListOrderPayments(readOrderDao, readPaymentDao)
+	listOrderCharges := usecases.NewListOrderCharges(readOrderDao, readChargeDao)
+	getOrderTimeline := usecases.NewGetOrderTimeline(readOrderDao)
+	getPaymentTimeline := usecases.NewGetPaymentTimeline(readPaymentDao)
+	createApiKey := usecases.NewCreateApiKey(apiKeyDao)
+	listApiKeys := usecases.NewListApiKeys(readApiKeyDao)
+	revokeApiKey := usecases.NewRevokeApiKey(apiKeyDao)
+	rotateApiKey := usecases.NewRotateApiKey(apiKeyDao)
+	authenticateApiKey := usecases.NewAuthenticateApiKey(apiKeyDao)
+	listPaymentMethods := usecases.NewListPaymentMethods(readPaymentMethodDao)
+	verifySignature := usecases.NewVerifySignature(apiKeyDao, nonceDao, configuration.Signature.Tolerance)
+
+	purgeExpiredNonces := usecases.NewPurgeExpiredNonces(nonceDao)
+	checkOrderBalances := usecases.NewCheckOrderBalances(readOrderDao, configuration.Workers.BalanceCheckBatchSize, logger, gatewayMetrics)
+
+	// Create Workers
+	workers := []*worker.Worker{
+		worker.New("purge-expired-nonces", configuration.Workers.NoncePurgeInterval, func(ctx context.Context) error {
+			_, err := purgeExpiredNonces.Execute(ctx)
+			return err
+		}, logger),
+		worker.New("check-order-balances", configuration.Workers.BalanceCheckInterval, func(ctx context.Context) error {
+			_, err := checkOrderBalances.Execute(ctx)
+			return err
+		}, logger),
+	}
+	if sqlRateLimitStore != nil {
+		workers = append(workers, worker.New("purge-rate-limit-buckets", configuration.Workers.RateLimitPurgeInterval, func(ctx context.Context) error {
+			_, err := sqlRateLimitStore.PurgeIdle(ctx, time.Now().Add(-rateLimitPolicy.RefillTime()))
+			return err
+		}, logger))
+	}
+	if len(replicas) > 0 {
+		workers = append(workers, worker.New("check-replica-lag", configuration.Workers.ReplicaLagInterval, func(ctx context.Context) error {
+			replicaClient.CheckLag(ctx)
+			return nil
+		}, logger))
+	}
+
+	// Create Readiness
+	checkers := []health.Checker{
+		health.Database(db),
+		migrator,
+	}
+	for _, w := range workers {
+		checkers = append(checkers, w)
+	}
+	readiness := health.NewReadiness(configuration.Readiness.Timeout, checkers...)
 
 	// Create Handlers
 	paymentHandler := handler.NewCreatePaymentHandler(createPayment)
 	processPaymentHandler := handler.NewProcessPaymentHandler(processPayment)
 	getCashoutHandler := handler.NewGetCashoutHandler(getCashout)
+	getPaymentHandler := handler.NewGetPaymentHandler(getPayment)
+	searchPaymentsHandler := handler.NewSearchPaymentsHandler(searchPayments)
+	listOrderPaymentsHandler := handler.NewListOrderPaymentsHandler(listOrderPayments)
+	listOrderChargesHandler := handler.NewListOrderChargesHandler(listOrderCharges)
+	getOrderTimelineHandler := handler.NewGetOrderTimelineHandler(getOrderTimeline)
+	getPaymentTimelineHandler := handler.NewGetPaymentTimelineHandler(getPaymentTimeline)
+	createApiKeyHandler := handler.NewCreateApiKeyHandler(createApiKey)
+	listApiKeysHandler := handler.NewListApiKeysHandler(listApiKeys)
+	revokeApiKeyHandler := handler.NewRevokeApiKeyHandler(revokeApiKey)
+	rotateApiKeyHandler := handler.NewRotateApiKeyHandler(rotateApiKey)
+	listPaymentMethodsHandler := handler.NewListPaymentMethodsHandler(listPaymentMethods)
 
 	return &Runtime{
 		CreatePaymentHandler:  paymentHandler,
 		ProcessPaymentHandler: processPaymentHandler,
 		GetCashoutHandler:     getCashoutHandler,
-	}
+		GetPaymentHandler:     getPaymentHandler,
+		SearchPaymentsHandler: searchPaymentsHandler,
+
+		ListOrderPaymentsHandler: listOrderPaymentsHandler,
+		ListOrderChargesHandler:  listOrderChargesHandler,
+		GetOrderTimelineHandler:  getOrderTimelineHandler,
+
+		GetPaymentTimelineHandler: getPaymentTimelineHandler,
+
+		CreateApiKeyHandler: createApiKeyHandler,
+		ListApiKeysHandler:  listApiKeysHandler,
+		RevokeApiKeyHandler: revokeApiKeyHandler,
+		RotateApiKeyHandler: rotateApiKeyHandler,
+
+		ListPaymentMethodsHandler: listPaymentMethodsHandler,
+
+		LivezHandler:  handler.NewLivezHandler(),
+		ReadyzHandler: handler.NewReadyzHandler(readiness),
+
+		Authenticate:       middleware.Authenticate(authenticateApiKey),
+		AuthenticateSigned: middleware.AuthenticateSigned(authenticateApiKey, verifySignature, int64(configuration.Signature.MaxBodyBytes)),
+		RateLimit:          middleware.RateLimit(limiter),
+		RateLimitIP:        middleware.RateLimitIP(limiter),
+		Timeout:            middleware.Timeout(configuration.Server.RequestTimeout, configuration.Server.RouteTimeouts),
+		AccessLog:          middleware.AccessLog(logger),
+		RequestMetrics:     middleware.Metrics(gatewayMetrics),
+		Tracing:            middleware.Tracing(tracerProvider, otel.GetTextMapPropagator()),
+		MetricsHandler:     gin.WrapH(gatewayMetrics.Handler()),
+
+		db:        db,
+		replicas:  replicas,
+		readiness: readiness,
+		workers:   workers,
+	}, nil
 }
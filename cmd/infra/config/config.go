@@ -0,0 +1,204 @@
+// Package config loads the gateway configuration from a YAML or TOML file,
+// environment variables and command-line flags, in that order of precedence.
+package config
+
+import (
+	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/ratelimit"
+	"time"
+)
+
+// Every leaf field declares its dotted file/flag key and its environment
+// variable. Fields tagged secret can also be read from "<key>_file" or
+// "<ENV>_FILE", as mounted by Docker secrets, and are hidden when redacted.
+type Configuration struct {
+	Server    Server    `key:"server"`
+	Database  Database  `key:"database"`
+	Migrate   Migrate   `key:"migrate"`
+	Log       Log       `key:"log"`
+	Tracing   Tracing   `key:"tracing"`
+	RateLimit RateLimit `key:"rate_limit"`
+	Signature Signature `key:"signature"`
+	Audit     Audit     `key:"audit"`
+	Readiness Readiness `key:"readiness"`
+	Workers   Workers   `key:"workers"`
+	Fees      Fees      `key:"fees"`
+}
+
+type Server struct {
+	Addr              string                   `key:"addr" env:"SERVER_ADDR"`
+	ReadHeaderTimeout time.Duration            `key:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration            `key:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration            `key:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration            `key:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration            `key:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	PreStopDelay      time.Duration            `key:"pre_stop_delay" env:"SHUTDOWN_PRE_STOP_DELAY"`
+	RequestTimeout    time.Duration            `key:"request_timeout" env:"REQUEST_TIMEOUT"`
+	RouteTimeouts     map[string]time.Duration `key:"route_timeouts" env:"ROUTE_TIMEOUTS"`
+	TrustedProxies    []string                 `key:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+	TLS               TLS                      `key:"tls"`
+}
+
+type TLS struct {
+	CertFile string `key:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `key:"key_file" env:"TLS_KEY_FILE"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+type Database struct {
+	Driver          string        `key:"driver" env:"DB_DRIVER"`
+	Host            string        `key:"host" env:"DB_HOST"`
+	Port            int           `key:"port" env:"DB_PORT"`
+	User            string        `key:"user" env:"DB_USER"`
+	Password        string        `key:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `key:"name" env:"DB_NAME"`
+	Path            string        `key:"path" env:"DB_PATH"`
+	SSLMode         string        `key:"ssl_mode" env:"DB_SSL_MODE"`
+	MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	Retry           Retry         `key:"retry"`
+	// Replicas are host:port addresses of read replicas, reached with the
+	// credentials of the primary.
+	Replicas      []string      `key:"replicas" env:"DB_REPLICAS"`
+	ReplicaMaxLag time.Duration `key:"replica_max_lag" env:"DB_REPLICA_MAX_LAG"`
+}
+
+// ConnectOptions returns the pool limits and retry policy of the database.
+func (d Database) ConnectOptions() db.ConnectOptions {
+	return db.ConnectOptions{
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		ConnMaxIdleTime: d.ConnMaxIdleTime,
+		RetryAttempts:   d.Retry.Attempts,
+		RetryInterval:   d.Retry.Interval,
+	}
+}
+
+type Retry struct {
+	Attempts int           `key:"attempts" env:"DB_RETRY_ATTEMPTS"`
+	Interval time.Duration `key:"interval" env:"DB_RETRY_INTERVAL"`
+}
+
+type Migrate struct {
+	OnStart     bool          `key:"on_start" env:"MIGRATE_ON_START"`
+	DevSeed     bool          `key:"dev_seed" env:"MIGRATE_DEV_SEED"`
+	LockTimeout time.Duration `key:"lock_timeout" env:"MIGRATE_LOCK_TIMEOUT"`
+}
+
+type Log struct {
+	Level  string `key:"level" env:"LOG_LEVEL"`
+	Format string `key:"format" env:"LOG_FORMAT"`
+}
+
+type Tracing struct {
+	Exporter     string  `key:"exporter" env:"TRACING_EXPORTER"`
+	File         string  `key:"file" env:"TRACING_FILE"`
+	OTLPEndpoint string  `key:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool    `key:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	SampleRatio  float64 `key:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type RateLimit struct {
+	Store       string                     `key:"store" env:"RATE_LIMIT_STORE"`
+	PerIP       ratelimit.Limit            `key:"per_ip" env:"RATE_LIMIT_PER_IP"`
+	PerKey      ratelimit.Limit            `key:"per_key" env:"RATE_LIMIT_PER_KEY"`
+	PerMerchant ratelimit.Limit            `key:"per_merchant" env:"RATE_LIMIT_PER_MERCHANT"`
+	PerRoute    map[string]ratelimit.Limit `key:"per_route" env:"RATE_LIMIT_PER_ROUTE"`
+}
+
+type Signature struct {
+	Tolerance    time.Duration `key:"tolerance" env:"SIGNATURE_TOLERANCE"`
+	MaxBodyBytes int           `key:"max_body_bytes" env:"SIGNATURE_MAX_BODY_BYTES"`
+}
+
+// Audit holds the key the audit log chain is sealed with. Changing it makes
+// every entry sealed with the previous one fail verification.
+type Audit struct {
+	Secret string `key:"secret" env:"AUDIT_SECRET" secret:"true"`
+}
+
+type Readiness struct {
+	Timeout time.Duration `key:"timeout" env:"READINESS_TIMEOUT"`
+}
+
+type Workers struct {
+	NoncePurgeInterval     time.Duration `key:"nonce_purge_interval" env:"NONCE_PURGE_INTERVAL"`
+	BalanceCheckInterval   time.Duration `key:"balance_check_interval" env:"BALANCE_CHECK_INTERVAL"`
+	BalanceCheckBatchSize  int           `key:"balance_check_batch_size" env:"BALANCE_CHECK_BATCH_SIZE"`
+	RateLimitPurgeInterval time.Duration `key:"rate_limit_purge_interval" env:"RATE_LIMIT_PURGE_INTERVAL"`
+	ReplicaLagInterval     time.Duration `key:"replica_lag_interval" env:"REPLICA_LAG_INTERVAL"`
+}
+
+// Fees are the rates charged per payment method, as a fraction of the amount.
+type Fees struct {
+	CreditCard float64 `key:"credit_card" env:"FEE_CREDIT_CARD"`
+	CashSlip   float64 `key:"cash_slip" env:"FEE_CASH_SLIP"`
+	Cash       float64 `key:"cash" env:"FEE_CASH"`
+}
+
+// defaultPorts is used when the database port is left unset.
+var defaultPorts = map[string]int{"mysql": 3306, "postgres": 5432}
+
+func Defaults() *Configuration {
+	return &Configuration{
+		Server: Server{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+			PreStopDelay:      10 * time.Second,
+			RequestTimeout:    10 * time.Second,
+			RouteTimeouts: map[string]time.Duration{
+				"POST /payments":             5 * time.Second,
+				"POST /payments/:id/process": 5 * time.Second,
+			},
+		},
+		Database: Database{
+			Driver:          "mysql",
+			SSLMode:         "disable",
+			Path:            "payment-gateway.db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: time.Minute,
+			Retry:           Retry{Attempts: 15, Interval: 5 * time.Second},
+			ReplicaMaxLag:   5 * time.Second,
+		},
+		Migrate: Migrate{LockTimeout: time.Minute},
+		Log:     Log{Level: "info", Format: "json"},
+		Tracing: Tracing{
+			Exporter:     "none",
+			File:         "traces.json",
+			OTLPEndpoint: "localhost:4318",
+			OTLPInsecure: true,
+			SampleRatio:  1,
+		},
+		RateLimit: RateLimit{
+			Store:       "memory",
+			PerIP:       ratelimit.Limit{Rate: 50, Burst: 100},
+			PerKey:      ratelimit.Limit{Rate: 20, Burst: 40},
+			PerMerchant: ratelimit.Limit{Rate: 50, Burst: 100},
+			PerRoute: map[string]ratelimit.Limit{
+				"POST /payments": {Rate: 5, Burst: 10},
+			},
+		},
+		Signature: Signature{Tolerance: 5 * time.Minute, MaxBodyBytes: 1 << 20},
+		Readiness: Readiness{Timeout: 2 * time.Second},
+		Workers: Workers{
+			NoncePurgeInterval:     time.Minute,
+			BalanceCheckInterval:   5 * time.Minute,
+			BalanceCheckBatchSize:  1000,
+			RateLimitPurgeInterval: 5 * time.Minute,
+			ReplicaLagInterval:     5 * time.Second,
+		},
+		Fees: Fees{CreditCard: 0.1, CashSlip: 0.2, Cash: 0},
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/ratelimit"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fileSuffix = "_file"
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	limitType       = reflect.TypeOf(ratelimit.Limit{})
+	routeLimitsType = reflect.TypeOf(map[string]ratelimit.Limit{})
+	timeoutsType    = reflect.TypeOf(map[string]time.Duration{})
+	stringsType     = reflect.TypeOf([]string{})
+)
+
+type field struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists every leaf of cfg in declaration order.
+func fields(cfg *Configuration) []field {
+	return collect(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func collect(v reflect.Value, prefix string) []field {
+	var leaves []field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		key := prefix + sf.Tag.Get("key")
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != limitType {
+			leaves = append(leaves, collect(v.Field(i), key+".")...)
+			continue
+		}
+
+		leaves = append(leaves, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+
+	return leaves
+}
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch f.value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		f.value.SetInt(int64(d))
+		return nil
+	case limitType:
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return err
+		}
+		f.value.Set(reflect.ValueOf(limit))
+		return nil
+	case routeLimitsType:
+		limits, err := ratelimit.ParseRouteLimits(raw)
+		if err != nil {
+			return err
+		}
+		f.value.Set(reflect.ValueOf(limits))
+		return nil
+	case timeoutsType:
+		timeouts, err := middleware.ParseRouteTimeouts(raw)
+		if err != nil {
+			return err
+		}
+		f.value.Set(reflect.ValueOf(timeouts))
+		return nil
+	case stringsType:
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		f.value.Set(reflect.ValueOf(values))
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.value.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		f.value.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+
+	return nil
+}
+
+// isMap reports whether the file may declare the field as a nested table.
+func (f field) isMap() bool {
+	return f.value.Kind() == reflect.Map
+}
+
+// isList reports whether the file may declare the field as a list.
+func (f field) isList() bool {
+	return f.value.Kind() == reflect.Slice
+}
+
+// display returns the value as it is written in a configuration file.
+func (f field) display() any {
+	switch f.value.Type() {
+	case durationType:
+		return time.Duration(f.value.Int()).String()
+	case limitType:
+		return formatLimit(f.value.Interface().(ratelimit.Limit))
+	case routeLimitsType:
+		out := map[string]string{}
+		for route, limit := range f.value.Interface().(map[string]ratelimit.Limit) {
+			out[route] = formatLimit(limit)
+		}
+		return out
+	case timeoutsType:
+		out := map[string]string{}
+		for route, timeout := range f.value.Interface().(map[string]time.Duration) {
+			out[route] = timeout.String()
+		}
+		return out
+	}
+
+	return f.value.Interface()
+}
+
+// joinList flattens a list from the file into the "a,b,..." form set
+// understands.
+func joinList(list []any) string {
+	values := make([]string, 0, len(list))
+	for _, value := range list {
+		values = append(values, fmt.Sprint(value))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func formatLimit(limit ratelimit.Limit) string {
+	return strconv.FormatFloat(limit.Rate, 'f', -1, 64) + ":" + strconv.Itoa(limit.Burst)
+}
+
+// joinMap flattens a table from the file into the "key=value;..." form the
+// parsers understand.
+func joinMap(table map[string]any) string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, key+"="+fmt.Sprint(table[key]))
+	}
+
+	return strings.Join(entries, ";")
+}
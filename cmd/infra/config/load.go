@@ -0,0 +1,184 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from the defaults, then the file named by
+// -config or CONFIG_FILE, then the environment, then the remaining flags.
+// Every problem found along the way is reported at once.
+func Load(args []string, environ []string) (*Configuration, error) {
+	cfg := Defaults()
+	leaves := fields(cfg)
+	env := parseEnviron(environ)
+
+	fs := flag.NewFlagSet("payment-gateway", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", env[configFileEnv], "path to a YAML or TOML configuration file")
+	flags := map[string]string{}
+	for _, leaf := range leaves {
+		names := []string{leaf.key}
+		if leaf.secret {
+			names = append(names, leaf.key+fileSuffix)
+		}
+		for _, name := range names {
+			fs.Func(name, "overrides "+leaf.key, func(value string) error {
+				flags[name] = value
+				return nil
+			})
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, &Error{Problems: []string{err.Error()}}
+	}
+
+	var problems []string
+	if *path != "" {
+		problems = append(problems, applyFile(leaves, *path)...)
+	}
+	problems = append(problems, applyEnv(leaves, env)...)
+	problems = append(problems, applyFlags(leaves, flags)...)
+	if cfg.Database.Port == 0 {
+		cfg.Database.Port = defaultPorts[cfg.Database.Driver]
+	}
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+// Error aggregates every configuration problem found at startup.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func applyFile(leaves []field, path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	table := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &table)
+	case ".toml":
+		err = toml.Unmarshal(content, &table)
+	default:
+		return []string{fmt.Sprintf("%s: unsupported configuration format, expected .yaml, .yml or .toml", path)}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+
+	byKey := map[string]field{}
+	for _, leaf := range leaves {
+		byKey[leaf.key] = leaf
+		if leaf.secret {
+			byKey[leaf.key+fileSuffix] = leaf
+		}
+	}
+
+	var problems []string
+	var walk func(prefix string, table map[string]any)
+	walk = func(prefix string, table map[string]any) {
+		for name, value := range table {
+			key := prefix + name
+			leaf, ok := byKey[key]
+			nested, isTable := value.(map[string]any)
+			list, isList := value.([]any)
+
+			switch {
+			case ok && isTable && leaf.isMap():
+				problems = append(problems, set(leaf, "file", key, joinMap(nested), false)...)
+			case ok && isList && leaf.isList():
+				problems = append(problems, set(leaf, "file", key, joinList(list), false)...)
+			case ok && !isTable && !isList:
+				problems = append(problems, set(leaf, "file", key, fmt.Sprint(value), key != leaf.key)...)
+			case !ok && isTable:
+				walk(key+".", nested)
+			default:
+				problems = append(problems, fmt.Sprintf("file %s: unknown key", key))
+			}
+		}
+	}
+	walk("", table)
+
+	return problems
+}
+
+func applyEnv(leaves []field, env map[string]string) []string {
+	var problems []string
+	for _, leaf := range leaves {
+		if value, ok := env[leaf.env]; ok {
+			problems = append(problems, set(leaf, "env", leaf.env, value, false)...)
+		}
+		if leaf.secret {
+			if value, ok := env[leaf.env+strings.ToUpper(fileSuffix)]; ok {
+				problems = append(problems, set(leaf, "env", leaf.env+strings.ToUpper(fileSuffix), value, true)...)
+			}
+		}
+	}
+
+	return problems
+}
+
+func applyFlags(leaves []field, flags map[string]string) []string {
+	var problems []string
+	for _, leaf := range leaves {
+		if value, ok := flags[leaf.key]; ok {
+			problems = append(problems, set(leaf, "flag", leaf.key, value, false)...)
+		}
+		if value, ok := flags[leaf.key+fileSuffix]; ok {
+			problems = append(problems, set(leaf, "flag", leaf.key+fileSuffix, value, true)...)
+		}
+	}
+
+	return problems
+}
+
+// set assigns raw to the leaf. When fromFile is set, raw is the path of a
+// file whose trimmed content is the value, as with Docker secrets.
+func set(leaf field, source string, name string, raw string, fromFile bool) []string {
+	if fromFile {
+		content, err := os.ReadFile(strings.TrimSpace(raw))
+		if err != nil {
+			return []string{fmt.Sprintf("%s %s: %v", source, name, err)}
+		}
+		raw = strings.TrimSpace(string(content))
+	}
+
+	if err := leaf.set(raw); err != nil {
+		return []string{fmt.Sprintf("%s %s: %v", source, name, err)}
+	}
+
+	return nil
+}
+
+func parseEnviron(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if key, value, ok := strings.Cut(entry, "="); ok && value != "" {
+			env[key] = value
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"payment-gateway/cmd/infra/config"
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var required = []string{"DB_HOST=mysql", "DB_USER=root", "DB_NAME=gateway", "AUDIT_SECRET=" + auditSecret}
+
+const auditSecret = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should use the defaults when nothing else is given", func(t *testing.T) {
+		cfg, err := config.Load(nil, required)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ":8080", cfg.Server.Addr)
+		assert.Equal(t, 3306, cfg.Database.Port)
+		assert.Equal(t, 15, cfg.Database.Retry.Attempts)
+		assert.Equal(t, 0.1, cfg.Fees.CreditCard)
+		assert.Equal(t, 5*time.Second, cfg.Server.RouteTimeouts["POST /payments"])
+	})
+
+	t.Run("should default the port to the one of the database driver", func(t *testing.T) {
+		postgres, err := config.Load(nil, append(required, "DB_DRIVER=postgres"))
+		assert.NoError(t, err)
+		assert.Equal(t, 5432, postgres.Database.Port)
+
+		custom, err := config.Load(nil, append(required, "DB_DRIVER=postgres", "DB_PORT=6543"))
+		assert.NoError(t, err)
+		assert.Equal(t, 6543, custom.Database.Port)
+	})
+
+	t.Run("should apply the file, then the environment, then the flags", func(t *testing.T) {
+		path := writeFile(t, "gateway.yaml", `
+server:
+  addr: ":9000"
+  shutdown_timeout: 45s
+  route_timeouts:
+    GET /orders/:id: 2s
+database:
+  host: file-host
+  user: file-user
+  name: gateway
+  max_open_conns: 10
+  max_idle_conns: 5
+log:
+  level: debug
+rate_limit:
+  per_key: "1:2"
+fees:
+  cash_slip: 0.15
+`)
+
+		cfg, err := config.Load(
+			[]string{"-config", path, "-server.addr", ":7000"},
+			[]string{"DB_HOST=env-host", "SERVER_ADDR=:8000", "LOG_LEVEL=warn"},
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ":7000", cfg.Server.Addr)
+		assert.Equal(t, "env-host", cfg.Database.Host)
+		assert.Equal(t, "file-user", cfg.Database.User)
+		assert.Equal(t, "warn", cfg.Log.Level)
+		assert.Equal(t, 45*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, map[string]time.Duration{"GET /orders/:id": 2 * time.Second}, cfg.Server.RouteTimeouts)
+		assert.Equal(t, 10, cfg.Database.MaxOpenConns)
+		assert.Equal(t, ratelimit.Limit{Rate: 1, Burst: 2}, cfg.RateLimit.PerKey)
+		assert.Equal(t, 0.15, cfg.Fees.CashSlip)
+	})
+
+	t.Run("should read the file named by CONFIG_FILE in TOML", func(t *testing.T) {
+		path := writeFile(t, "gateway.toml", `
+[database]
+host = "toml-host"
+user = "root"
+name = "gateway"
+
+[database.retry]
+attempts = 3
+interval = "1s"
+
+[audit]
+secret = "0123456789abcdef0123456789abcdef"
+`)
+
+		cfg, err := config.Load(nil, []string{"CONFIG_FILE=" + path})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "toml-host", cfg.Database.Host)
+		assert.Equal(t, 3, cfg.Database.Retry.Attempts)
+		assert.Equal(t, time.Second, cfg.Database.Retry.Interval)
+	})
+
+	t.Run("should read lists from the file or as comma-separated values", func(t *testing.T) {
+		path := writeFile(t, "gateway.yaml", "database:\n  replicas:\n    - replica-1:3306\n    - replica-2:3306\n")
+
+		fromFile, err := config.Load([]string{"-config", path}, required)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica-1:3306", "replica-2:3306"}, fromFile.Database.Replicas)
+
+		fromEnv, err := config.Load(nil, append(required, "DB_REPLICAS=replica-1:3306, replica-2:3306,"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica-1:3306", "replica-2:3306"}, fromEnv.Database.Replicas)
+	})
+
+	t.Run("should read secrets from files", func(t *testing.T) {
+		secret := writeFile(t, "db_password", "s3cr3t\n")
+
+		fromEnv, err := config.Load(nil, append(required, "DB_PASSWORD_FILE="+secret))
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cr3t", fromEnv.Database.Password)
+
+		fromFlag, err := config.Load([]string{"-database.password_file", secret}, required)
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cr3t", fromFlag.Database.Password)
+
+		path := writeFile(t, "gateway.yaml", "database:\n  password_file: "+secret+"\n")
+		fromConfig, err := config.Load([]string{"-config", path}, required)
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cr3t", fromConfig.Database.Password)
+	})
+
+	t.Run("should not read non-secret paths as secrets", func(t *testing.T) {
+		cfg, err := config.Load(nil, append(required, "TRACING_FILE=/tmp/traces.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/tmp/traces.json", cfg.Tracing.File)
+	})
+
+	t.Run("should report every problem at once", func(t *testing.T) {
+		path := writeFile(t, "gateway.yaml", "server:\n  adress: \":9000\"\n")
+
+		cfg, err := config.Load(
+			[]string{"-config", path},
+			[]string{"DB_PORT=abc", "SHUTDOWN_TIMEOUT=soon", "DB_PASSWORD_FILE=/missing/secret"},
+		)
+
+		assert.Nil(t, cfg)
+		cfgErr, ok := err.(*config.Error)
+		assert.True(t, ok)
+		assert.Contains(t, cfgErr.Problems, "file server.adress: unknown key")
+		assert.Contains(t, cfgErr.Problems, `env DB_PORT: invalid integer "abc"`)
+		assert.Contains(t, cfgErr.Problems, `env SHUTDOWN_TIMEOUT: invalid duration "soon"`)
+		assert.Contains(t, cfgErr.Problems, "database.host: is required")
+		assert.Contains(t, err.Error(), "invalid configuration:\n  - ")
+		assert.Len(t, cfgErr.Problems, 7)
+	})
+
+	t.Run("should reject unsupported file formats and unknown flags", func(t *testing.T) {
+		path := writeFile(t, "gateway.json", "{}")
+		_, err := config.Load([]string{"-config", path}, required)
+		assert.EqualError(t, err, "invalid configuration:\n  - "+path+": unsupported configuration format, expected .yaml, .yml or .toml")
+
+		_, err = config.Load([]string{"-server.port", "80"}, required)
+		assert.EqualError(t, err, "invalid configuration:\n  - flag provided but not defined: -server.port")
+	})
+}
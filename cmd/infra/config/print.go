@@ -0,0 +1,55 @@
+package config
+
+import (
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const Redacted = "[REDACTED]"
+
+// Print writes cfg as YAML in the same layout accepted by Load, keeping the
+// declaration order of the sections. When redacted is set, secrets are
+// replaced by a placeholder.
+func Print(w io.Writer, cfg *Configuration, redacted bool) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, leaf := range fields(cfg) {
+		path := strings.Split(leaf.key, ".")
+		table := root
+		for _, name := range path[:len(path)-1] {
+			table = child(table, name)
+		}
+
+		var value any = leaf.display()
+		if leaf.secret && redacted {
+			value = Redacted
+		}
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return err
+		}
+		table.Content = append(table.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[len(path)-1]}, node)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// child returns the mapping stored under name, creating it when missing.
+func child(table *yaml.Node, name string) *yaml.Node {
+	for i := 0; i < len(table.Content); i += 2 {
+		if table.Content[i].Value == name {
+			return table.Content[i+1]
+		}
+	}
+
+	nested := &yaml.Node{Kind: yaml.MappingNode}
+	table.Content = append(table.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, nested)
+	return nested
+}
@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"bytes"
+	"payment-gateway/cmd/infra/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrint(t *testing.T) {
+	cfg, err := config.Load(nil, append(required, "DB_PASSWORD=s3cr3t"))
+	assert.NoError(t, err)
+
+	t.Run("should hide secrets when redacted", func(t *testing.T) {
+		var out bytes.Buffer
+
+		assert.NoError(t, config.Print(&out, cfg, true))
+		assert.NotContains(t, out.String(), "s3cr3t")
+		assert.NotContains(t, out.String(), auditSecret)
+		assert.Contains(t, out.String(), "password: '[REDACTED]'")
+		assert.Contains(t, out.String(), "host: mysql")
+	})
+
+	t.Run("should print a file that loads back to the same configuration", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, config.Print(&out, cfg, false))
+
+		var printed map[string]any
+		assert.NoError(t, yaml.Unmarshal(out.Bytes(), &printed))
+		assert.Equal(t, "5m0s", printed["signature"].(map[string]any)["tolerance"])
+
+		path := writeFile(t, "printed.yaml", out.String())
+		loaded, err := config.Load([]string{"-config", path}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, cfg, loaded)
+	})
+}
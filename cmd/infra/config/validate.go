@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+)
+
+// minAuditSecret is the shortest audit secret accepted, as long as the
+// SHA-256 digest it keys.
+const minAuditSecret = 32
+
+func (c *Configuration) validate() []string {
+	var problems []string
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	_, port, err := net.SplitHostPort(c.Server.Addr)
+	if err == nil {
+		_, err = strconv.ParseUint(port, 10, 16)
+	}
+	check(err == nil, "server.addr", "must be host:port, got %q", c.Server.Addr)
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout", "must be positive")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout", "must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout", "must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout", "must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.PreStopDelay >= 0, "server.pre_stop_delay", "must not be negative")
+	check(c.Server.RequestTimeout >= 0, "server.request_timeout", "must not be negative")
+	for route, timeout := range c.Server.RouteTimeouts {
+		check(timeout > 0, "server.route_timeouts", "timeout for %q must be positive", route)
+	}
+
+	tls := c.Server.TLS
+	if tls.Enabled() {
+		check(tls.CertFile != "" && tls.KeyFile != "", "server.tls", "cert_file and key_file must be set together")
+		for key, path := range map[string]string{"server.tls.cert_file": tls.CertFile, "server.tls.key_file": tls.KeyFile} {
+			if path != "" {
+				_, err := os.Stat(path)
+				check(err == nil, key, "cannot read %q", path)
+			}
+		}
+	}
+
+	db := c.Database
+	check(oneOf(db.Driver, "mysql", "postgres", "sqlite"), "database.driver", "must be mysql, postgres or sqlite, got %q", db.Driver)
+	if db.Driver == "sqlite" {
+		check(db.Path != "", "database.path", "is required")
+	} else {
+		check(db.Host != "", "database.host", "is required")
+		check(db.User != "", "database.user", "is required")
+		check(db.Name != "", "database.name", "is required")
+		check(oneOf(db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"), "database.ssl_mode", "must be a libpq sslmode, got %q", db.SSLMode)
+		check(db.Port > 0 && db.Port <= 65535, "database.port", "must be between 1 and 65535")
+	}
+	check(db.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	check(db.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+	check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns, "database.max_idle_conns", "must not exceed max_open_conns (%d)", db.MaxOpenConns)
+	check(db.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	check(db.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	check(db.Retry.Attempts >= 1, "database.retry.attempts", "must be at least 1")
+	check(db.Retry.Interval >= 0, "database.retry.interval", "must not be negative")
+	check(db.Driver != "sqlite" || len(db.Replicas) == 0, "database.replicas", "are not supported by sqlite")
+	for _, replica := range db.Replicas {
+		_, port, err := net.SplitHostPort(replica)
+		if err == nil {
+			_, err = strconv.ParseUint(port, 10, 16)
+		}
+		check(err == nil, "database.replicas", "must be host:port, got %q", replica)
+	}
+	check(db.ReplicaMaxLag > 0, "database.replica_max_lag", "must be positive")
+
+	check(c.Migrate.LockTimeout > 0, "migrate.lock_timeout", "must be positive")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	check(oneOf(c.Log.Format, "json", "text"), "log.format", "must be json or text, got %q", c.Log.Format)
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "file", "otlp"), "tracing.exporter", "must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file", "is required by the file exporter")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.OTLPEndpoint != "", "tracing.otlp_endpoint", "is required by the otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	check(oneOf(c.RateLimit.Store, "memory", "database", "mysql"), "rate_limit.store", "must be memory or database, got %q", c.RateLimit.Store)
+
+	check(c.Signature.Tolerance > 0, "signature.tolerance", "must be positive")
+	check(c.Signature.MaxBodyBytes > 0, "signature.max_body_bytes", "must be positive")
+	check(c.Readiness.Timeout > 0, "readiness.timeout", "must be positive")
+	check(c.Workers.NoncePurgeInterval > 0, "workers.nonce_purge_interval", "must be positive")
+	check(c.Workers.BalanceCheckInterval > 0, "workers.balance_check_interval", "must be positive")
+	check(c.Workers.BalanceCheckBatchSize > 0, "workers.balance_check_batch_size", "must be positive")
+	check(c.Workers.RateLimitPurgeInterval > 0, "workers.rate_limit_purge_interval", "must be positive")
+	check(c.Workers.ReplicaLagInterval > 0, "workers.replica_lag_interval", "must be positive")
+
+	for key, rate := range map[string]float64{"fees.credit_card": c.Fees.CreditCard, "fees.cash_slip": c.Fees.CashSlip, "fees.cash": c.Fees.Cash} {
+		check(rate >= 0 && rate <= 1, key, "must be between 0 and 1")
+	}
+
+	return problems
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireAuditSecret fails when the audit secret is too short to key the
+// chain. Only the commands that seal or verify the audit log need it, so
+// Load leaves it to them.
+func (c *Configuration) RequireAuditSecret() error {
+	if len(c.Audit.Secret) < minAuditSecret {
+		return &Error{Problems: []string{fmt.Sprintf("audit.secret: must be at least %d characters", minAuditSecret)}}
+	}
+
+	return nil
+}
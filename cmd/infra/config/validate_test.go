@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"payment-gateway/cmd/infra/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func problems(t *testing.T, args []string, environ ...string) []string {
+	_, err := config.Load(args, append(environ, required...))
+	if err == nil {
+		return nil
+	}
+
+	cfgErr, ok := err.(*config.Error)
+	assert.True(t, ok)
+	return cfgErr.Problems
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("should require the database coordinates", func(t *testing.T) {
+		_, err := config.Load(nil, nil)
+
+		assert.EqualError(t, err, "invalid configuration:\n"+
+			"  - database.host: is required\n"+
+			"  - database.user: is required\n"+
+			"  - database.name: is required")
+	})
+
+	t.Run("should validate the server section", func(t *testing.T) {
+		assert.Equal(t, []string{`server.addr: must be host:port, got "8080"`}, problems(t, []string{"-server.addr", "8080"}))
+		assert.Equal(t, []string{"server.shutdown_timeout: must be positive"}, problems(t, nil, "SHUTDOWN_TIMEOUT=0s"))
+		assert.Equal(t, []string{`server.route_timeouts: timeout for "POST /payments" must be positive`}, problems(t, nil, "ROUTE_TIMEOUTS=POST /payments=0s"))
+	})
+
+	t.Run("should require both TLS files and check they exist", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"server.tls: cert_file and key_file must be set together",
+			`server.tls.cert_file: cannot read "/missing/cert.pem"`,
+		}, problems(t, nil, "TLS_CERT_FILE=/missing/cert.pem"))
+
+		cert := writeFile(t, "cert.pem", "cert")
+		key := writeFile(t, "key.pem", "key")
+		assert.Empty(t, problems(t, nil, "TLS_CERT_FILE="+cert, "TLS_KEY_FILE="+key))
+	})
+
+	t.Run("should validate the database driver", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`database.driver: must be mysql, postgres or sqlite, got "oracle"`,
+			`database.ssl_mode: must be a libpq sslmode, got "on"`,
+		}, problems(t, nil, "DB_DRIVER=oracle", "DB_SSL_MODE=on", "DB_PORT=1521"))
+	})
+
+	t.Run("should only require a file path for sqlite", func(t *testing.T) {
+		_, err := config.Load(nil, []string{"DB_DRIVER=sqlite"})
+		assert.NoError(t, err)
+
+		_, err = config.Load([]string{"-database.path", ""}, []string{"DB_DRIVER=sqlite"})
+		assert.EqualError(t, err, "invalid configuration:\n  - database.path: is required")
+	})
+
+	t.Run("should validate the database pool and retry policy", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"database.port: must be between 1 and 65535",
+			"database.max_idle_conns: must not exceed max_open_conns (5)",
+			"database.retry.attempts: must be at least 1",
+		}, problems(t, nil, "DB_PORT=70000", "DB_MAX_OPEN_CONNS=5", "DB_MAX_IDLE_CONNS=10", "DB_RETRY_ATTEMPTS=0"))
+	})
+
+	t.Run("should validate the read replicas", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`database.replicas: must be host:port, got "replica-2"`,
+			"database.replica_max_lag: must be positive",
+		}, problems(t, nil, "DB_REPLICAS=replica-1:3306,replica-2", "DB_REPLICA_MAX_LAG=0s"))
+
+		_, err := config.Load(nil, []string{"DB_DRIVER=sqlite", "DB_REPLICAS=replica-1:3306"})
+		assert.EqualError(t, err, "invalid configuration:\n  - database.replicas: are not supported by sqlite")
+	})
+
+	t.Run("should validate logging and tracing choices", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`log.level: must be debug, info, warn or error, got "verbose"`,
+			`log.format: must be json or text, got "xml"`,
+			`tracing.exporter: must be none, stdout, file or otlp, got "jaeger"`,
+			"tracing.sample_ratio: must be between 0 and 1",
+		}, problems(t, nil, "LOG_LEVEL=verbose", "LOG_FORMAT=xml", "TRACING_EXPORTER=jaeger", "TRACING_SAMPLE_RATIO=2"))
+	})
+
+	t.Run("should leave the audit secret to the commands that need it", func(t *testing.T) {
+		c, err := config.Load([]string{"-audit.secret", "short"}, required)
+		assert.NoError(t, err)
+
+		assert.EqualError(t, c.RequireAuditSecret(), "invalid configuration:\n  - audit.secret: must be at least 32 characters")
+	})
+
+	t.Run("should accept an audit secret of 32 characters", func(t *testing.T) {
+		c, err := config.Load(nil, required)
+		assert.NoError(t, err)
+
+		assert.NoError(t, c.RequireAuditSecret())
+	})
+
+	t.Run("should validate rate limits, workers and fees", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`rate_limit.store: must be memory or database, got "redis"`,
+			"workers.nonce_purge_interval: must be positive",
+			"workers.balance_check_interval: must be positive",
+			"workers.balance_check_batch_size: must be positive",
+			"workers.rate_limit_purge_interval: must be positive",
+			"workers.replica_lag_interval: must be positive",
+			"fees.credit_card: must be between 0 and 1",
+		}, problems(t, nil, "RATE_LIMIT_STORE=redis", "NONCE_PURGE_INTERVAL=0s", "BALANCE_CHECK_INTERVAL=-1s", "BALANCE_CHECK_BATCH_SIZE=0", "RATE_LIMIT_PURGE_INTERVAL=0s", "REPLICA_LAG_INTERVAL=0s", "FEE_CREDIT_CARD=1.5"))
+	})
+}
@@ -1,21 +0,0 @@
-package infra
-
-import "os"
-
-type Configuration struct {
-	DbUser     string
-	DbPassword string
-	DbHost     string
-	DbPort     string
-	DbName     string
-}
-
-func NewConfiguration() *Configuration {
-	return &Configuration{
-		DbUser:     os.Getenv("DB_USER"),
-		DbPassword: os.Getenv("DB_PASSWORD"),
-		DbHost:     os.Getenv("DB_HOST"),
-		DbPort:     os.Getenv("DB_PORT"),
-		DbName:     os.Getenv("DB_NAME"),
-	}
-}
@@ -0,0 +1,194 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
+	"strings"
+	"time"
+)
+
+type ApiKeyModel struct {
+	Id         int64
+	MerchantId int64
+	Name       string
+	Prefix     string
+	Hash       string
+	Secret     string
+	Scopes     string
+	Mode       string
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type ApiKeyDao struct {
+	db      db.Client
+	dialect db.Dialect
+}
+
+func NewApiKeyDao(client db.Client, dialect db.Dialect) *ApiKeyDao {
+	return &ApiKeyDao{db: client, dialect: dialect}
+}
+
+func (p *ApiKeyDao) Insert(ctx context.Context, key *apikey.Entity) (*apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "Insert")
+	query := `INSERT INTO api_keys
+		(merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := p.dialect.Insert(ctx, p.db, query,
+		key.MerchantId(),
+		key.Name(),
+		key.Prefix(),
+		key.Hash(),
+		key.SigningSecret(),
+		key.ScopesString(),
+		key.Mode(),
+		key.CreatedAt(),
+		key.UpdatedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.SetId(id)
+
+	return key, nil
+}
+
+func (p *ApiKeyDao) FindById(ctx context.Context, id int64) (*apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "FindById")
+	query := `SELECT id, merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, last_used_at, revoked_at, created_at, updated_at FROM api_keys WHERE id = ?`
+
+	return p.findOne(ctx, query, id)
+}
+
+func (p *ApiKeyDao) FindByHash(ctx context.Context, hash string) (*apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "FindByHash")
+	query := `SELECT id, merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, last_used_at, revoked_at, created_at, updated_at FROM api_keys WHERE key_hash = ?`
+
+	return p.findOne(ctx, query, hash)
+}
+
+func (p *ApiKeyDao) FindByPrefix(ctx context.Context, prefix string) (*apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "FindByPrefix")
+	query := `SELECT id, merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, last_used_at, revoked_at, created_at, updated_at FROM api_keys WHERE prefix = ?`
+
+	return p.findOne(ctx, query, prefix)
+}
+
+func (p *ApiKeyDao) FindByMerchantId(ctx context.Context, merchantId int64) ([]apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "FindByMerchantId")
+	query := `SELECT id, merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, last_used_at, revoked_at, created_at, updated_at FROM api_keys WHERE merchant_id = ?`
+
+	var keys []apikey.Entity
+	row, err := p.db.QueryContext(ctx, query, merchantId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var model ApiKeyModel
+		err := scanApiKey(row, &model)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *buildApiKey(model))
+	}
+
+	return keys, nil
+}
+
+func (p *ApiKeyDao) Update(ctx context.Context, key *apikey.Entity) (*apikey.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "Update")
+	query := `UPDATE api_keys
+		SET name = ?, prefix = ?, key_hash = ?, signing_secret = ?, scopes = ?, revoked_at = ?, updated_at = ?
+		WHERE id = ?`
+
+	var revokedAt sql.NullTime
+	if key.IsRevoked() {
+		revokedAt = sql.NullTime{Time: key.RevokedAt(), Valid: true}
+	}
+
+	_, err := p.db.ExecContext(ctx, query,
+		key.Name(),
+		key.Prefix(),
+		key.Hash(),
+		key.SigningSecret(),
+		key.ScopesString(),
+		revokedAt,
+		key.UpdatedAt(),
+		key.Id(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (p *ApiKeyDao) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	ctx = metrics.WithOperation(ctx, "ApiKeyDao", "TouchLastUsed")
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+
+	_, err := p.db.ExecContext(ctx, query, at, id)
+
+	return err
+}
+
+func (p *ApiKeyDao) findOne(ctx context.Context, query string, arg any) (*apikey.Entity, error) {
+	var model ApiKeyModel
+
+	row, err := p.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		if err := row.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, apikey.ErrNotFound
+	}
+
+	err = scanApiKey(row, &model)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildApiKey(model), nil
+}
+
+func scanApiKey(row db.Rows, model *ApiKeyModel) error {
+	return row.Scan(&model.Id, &model.MerchantId, &model.Name, &model.Prefix, &model.Hash, &model.Secret, &model.Scopes, &model.Mode,
+		&model.LastUsedAt, &model.RevokedAt, &model.CreatedAt, &model.UpdatedAt)
+}
+
+func buildApiKey(model ApiKeyModel) *apikey.Entity {
+	builder := apikey.NewApiKeyBuilder().
+		WithId(model.Id).
+		WithMerchantId(model.MerchantId).
+		WithName(model.Name).
+		WithPrefix(model.Prefix).
+		WithHash(model.Hash).
+		WithSigningSecret(model.Secret).
+		WithScopes(strings.Split(model.Scopes, ",")...).
+		WithMode(model.Mode).
+		WithCreatedAt(model.CreatedAt)
+
+	if model.LastUsedAt.Valid {
+		builder.WithLastUsedAt(model.LastUsedAt.Time)
+	}
+	if model.RevokedAt.Valid {
+		builder.WithRevokedAt(model.RevokedAt.Time)
+	}
+
+	return builder.WithUpdatedAt(model.UpdatedAt).Build()
+}
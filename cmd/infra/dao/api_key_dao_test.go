@@ -0,0 +1,316 @@
+package dao_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+)
+
+var apiKeyColumns = []string{"id", "merchant_id", "name", "prefix", "key_hash", "signing_secret", "scopes", "mode", "last_used_at", "revoked_at", "created_at", "updated_at"}
+
+func TestApiKeyDao_Insert(t *testing.T) {
+	key, _, _ := apikey.NewApiKey(1, "backend", []string{"read", "write"}, "live")
+
+	t.Run("should insert api key successfully", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO api_keys`).
+			WithArgs(
+				key.MerchantId(),
+				key.Name(),
+				key.Prefix(),
+				key.Hash(),
+				key.SigningSecret(),
+				"read,write",
+				"live",
+				key.CreatedAt(),
+				key.UpdatedAt(),
+			).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), key)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, int64(1), result.Id())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when database operation fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO api_keys`).
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), key)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when failing to get last insert ID", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO api_keys`).
+			WillReturnResult(sqlmock.NewErrorResult(assert.AnError))
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), key)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_FindByHash(t *testing.T) {
+	t.Run("should find api key by hash successfully", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 10, "backend", "abc", "hash", "secret", "read,write", "live", now, nil, now, now)
+
+		mock.ExpectQuery(`SELECT id, merchant_id, name, prefix, key_hash, signing_secret, scopes, mode, last_used_at, revoked_at, created_at, updated_at FROM api_keys WHERE key_hash = \?`).
+			WithArgs("hash").
+			WillReturnRows(rows)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByHash(context.Background(), "hash")
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, int64(1), result.Id())
+			assert.Equal(t, int64(10), result.MerchantId())
+			assert.Equal(t, "secret", result.SigningSecret())
+			assert.Equal(t, []string{"read", "write"}, result.Scopes())
+			assert.Equal(t, "live", result.Mode())
+			assert.Equal(t, now, result.LastUsedAt())
+			assert.False(t, result.IsRevoked())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_hash = \?`).
+			WithArgs("hash").
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByHash(context.Background(), "hash")
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when scan fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_hash = \?`).
+			WithArgs("hash").
+			WillReturnRows(rows)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByHash(context.Background(), "hash")
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_FindById(t *testing.T) {
+	t.Run("should find revoked api key by id", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 10, "backend", "abc", "hash", "secret", "admin", "test", nil, now, now, now)
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE id = \?`).
+			WithArgs(int64(1)).
+			WillReturnRows(rows)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), 1)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result) {
+			assert.True(t, result.IsRevoked())
+			assert.True(t, result.LastUsedAt().IsZero())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE id = \?`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), 1)
+
+		assert.ErrorIs(t, err, apikey.ErrNotFound)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_FindByPrefix(t *testing.T) {
+	t.Run("should find api key by public prefix", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 10, "backend", "abc", "hash", "secret", "write", "live", nil, nil, now, now)
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE prefix = \?`).
+			WithArgs("abc").
+			WillReturnRows(rows)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByPrefix(context.Background(), "abc")
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, int64(1), result.Id())
+			assert.Equal(t, "secret", result.SigningSecret())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_FindByMerchantId(t *testing.T) {
+	t.Run("should find api keys by merchant", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 10, "first", "abc", "hash1", "secret1", "read", "live", nil, nil, now, now).
+			AddRow(2, 10, "second", "def", "hash2", "secret2", "write", "test", nil, nil, now, now)
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE merchant_id = \?`).
+			WithArgs(int64(10)).
+			WillReturnRows(rows)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByMerchantId(context.Background(), 10)
+
+		assert.NoError(t, err)
+		if assert.Len(t, result, 2) {
+			assert.Equal(t, "first", result[0].Name())
+			assert.Equal(t, "second", result[1].Name())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE merchant_id = \?`).
+			WithArgs(int64(10)).
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindByMerchantId(context.Background(), 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_Update(t *testing.T) {
+	t.Run("should update api key successfully", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		key := apikey.NewApiKeyBuilder().WithId(1).WithName("backend").WithScopes("read").Build()
+		key.Revoke()
+
+		mock.ExpectExec(`UPDATE api_keys`).
+			WithArgs("backend", key.Prefix(), key.Hash(), key.SigningSecret(), "read", sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Update(context.Background(), key)
+
+		assert.NoError(t, err)
+		assert.Equal(t, key, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when update fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE api_keys`).
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Update(context.Background(), apikey.NewApiKeyBuilder().WithId(1).Build())
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyDao_TouchLastUsed(t *testing.T) {
+	t.Run("should record last usage", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectExec(`UPDATE api_keys SET last_used_at = \? WHERE id = \?`).
+			WithArgs(now, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		dao := dao.NewApiKeyDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		err = dao.TouchLastUsed(context.Background(), 1, now)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
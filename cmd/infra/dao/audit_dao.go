@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
+)
+
+// errMissingChainHead means the row the migration seeds audit_chain with is
+// gone, so the chain can be neither extended nor checked.
+var errMissingChainHead = errors.New("audit chain head is missing")
+
+type AuditDao struct {
+	db      db.Client
+	dialect db.Dialect
+	key     []byte
+}
+
+// NewAuditDao seals the entries it appends with key.
+func NewAuditDao(client db.Client, dialect db.Dialect, key []byte) *AuditDao {
+	return &AuditDao{db: client, dialect: dialect, key: key}
+}
+
+// Append locks the single row of audit_chain, so concurrent appends queue up
+// behind it and each one links to the entry the previous one wrote.
+func (a *AuditDao) Append(ctx context.Context, entry *audit.Entry) (*audit.Entry, error) {
+	ctx = metrics.WithOperation(ctx, "AuditDao", "Append")
+	seq, hash, err := a.head(ctx, a.dialect.ForUpdate(`SELECT seq, hash FROM audit_chain WHERE id = 1`))
+	if err != nil {
+		return nil, err
+	}
+	entry.Link(seq, hash, a.key)
+
+	_, err = a.db.ExecContext(ctx, `INSERT INTO audit_log
+		(seq, entity, entity_id, action, before_state, after_state, actor, request_id, created_at, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Seq,
+		entry.Entity,
+		entry.EntityId,
+		entry.Action,
+		entry.Before,
+		entry.After,
+		entry.Actor,
+		entry.RequestId,
+		entry.CreatedAt,
+		entry.PrevHash,
+		entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = a.db.ExecContext(ctx, `UPDATE audit_chain SET seq = ?, hash = ? WHERE id = 1`, entry.Seq, entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (a *AuditDao) FindAfter(ctx context.Context, seq int64, limit int) ([]audit.Entry, error) {
+	ctx = metrics.WithOperation(ctx, "AuditDao", "FindAfter")
+	query := `SELECT seq, entity, entity_id, action, before_state, after_state, actor, request_id, created_at, prev_hash, hash
+		FROM audit_log WHERE seq > ? ORDER BY seq LIMIT ?`
+
+	rows, err := a.db.QueryContext(ctx, query, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []audit.Entry
+	for rows.Next() {
+		var entry audit.Entry
+		err := rows.Scan(&entry.Seq, &entry.Entity, &entry.EntityId, &entry.Action, &entry.Before, &entry.After,
+			&entry.Actor, &entry.RequestId, &entry.CreatedAt, &entry.PrevHash, &entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (a *AuditDao) Head(ctx context.Context) (int64, string, error) {
+	ctx = metrics.WithOperation(ctx, "AuditDao", "Head")
+	return a.head(ctx, `SELECT seq, hash FROM audit_chain WHERE id = 1`)
+}
+
+func (a *AuditDao) head(ctx context.Context, query string) (int64, string, error) {
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	var seq int64
+	var hash string
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, "", err
+		}
+
+		return 0, "", errMissingChainHead
+	}
+	if err := rows.Scan(&seq, &hash); err != nil {
+		return 0, "", err
+	}
+
+	return seq, hash, rows.Err()
+}
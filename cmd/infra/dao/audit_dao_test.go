@@ -0,0 +1,128 @@
+package dao_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+)
+
+var auditKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestAuditDao_Append(t *testing.T) {
+	t.Run("should link the entry to the locked head and move the head to it", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		prevHash := audit.Genesis[:63] + "1"
+		entry := &audit.Entry{
+			Entity:    audit.EntityPayment,
+			EntityId:  7,
+			Action:    audit.ActionInsert,
+			After:     `{"id":7}`,
+			Actor:     "api_key:abc123",
+			RequestId: "req-1",
+			CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 900, time.UTC),
+		}
+
+		mock.ExpectQuery(`SELECT seq, hash FROM audit_chain WHERE id = 1 FOR UPDATE`).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "hash"}).AddRow(41, prevHash))
+		mock.ExpectExec(`INSERT INTO audit_log`).
+			WithArgs(int64(42), audit.EntityPayment, int64(7), audit.ActionInsert, "", `{"id":7}`,
+				"api_key:abc123", "req-1", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), prevHash, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE audit_chain SET seq = \?, hash = \? WHERE id = 1`).
+			WithArgs(int64(42), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		saved, err := dao.NewAuditDao(dbclient.NewTxClient(db), dbclient.MySQL, auditKey).Append(context.Background(), entry)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), saved.Seq)
+		assert.Equal(t, prevHash, saved.PrevHash)
+		assert.Equal(t, saved.ComputeHash(auditKey), saved.Hash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should fail when the chain head is missing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT seq, hash FROM audit_chain`).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "hash"}))
+
+		_, err = dao.NewAuditDao(dbclient.NewTxClient(db), dbclient.MySQL, auditKey).Append(context.Background(), &audit.Entry{})
+
+		assert.EqualError(t, err, "audit chain head is missing")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should not move the head when the insert fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT seq, hash FROM audit_chain`).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "hash"}).AddRow(0, audit.Genesis))
+		mock.ExpectExec(`INSERT INTO audit_log`).WillReturnError(assert.AnError)
+
+		_, err = dao.NewAuditDao(dbclient.NewTxClient(db), dbclient.MySQL, auditKey).Append(context.Background(), &audit.Entry{})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditDao_FindAfter(t *testing.T) {
+	t.Run("should page through the entries in chain order", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`SELECT seq, entity, entity_id, action, before_state, after_state, actor, request_id, created_at, prev_hash, hash\s+FROM audit_log WHERE seq > \? ORDER BY seq LIMIT \?`).
+			WithArgs(int64(10), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "entity", "entity_id", "action", "before_state", "after_state", "actor", "request_id", "created_at", "prev_hash", "hash"}).
+				AddRow(11, "payment", 7, "update", `{"status":"pending"}`, `{"status":"approved"}`, "api_key:abc123", "req-1", createdAt, "a", "b").
+				AddRow(12, "order", 3, "update", `{"status":"pending"}`, `{"status":"paid"}`, "api_key:abc123", "req-1", createdAt, "b", "c"))
+
+		entries, err := dao.NewAuditDao(dbclient.NewTxClient(db), dbclient.MySQL, auditKey).FindAfter(context.Background(), 10, 2)
+
+		require.NoError(t, err)
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, audit.Entry{
+				Seq: 11, Entity: "payment", EntityId: 7, Action: "update",
+				Before: `{"status":"pending"}`, After: `{"status":"approved"}`,
+				Actor: "api_key:abc123", RequestId: "req-1", CreatedAt: createdAt, PrevHash: "a", Hash: "b",
+			}, entries[0])
+			assert.Equal(t, int64(12), entries[1].Seq)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditDao_Head(t *testing.T) {
+	t.Run("should read the head without locking it", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT seq, hash FROM audit_chain WHERE id = 1$`).
+			WillReturnRows(sqlmock.NewRows([]string{"seq", "hash"}).AddRow(0, audit.Genesis))
+
+		seq, hash, err := dao.NewAuditDao(dbclient.NewTxClient(db), dbclient.MySQL, auditKey).Head(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), seq)
+		assert.Equal(t, audit.Genesis, hash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
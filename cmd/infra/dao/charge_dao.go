@@ -1,8 +1,10 @@
 package dao
 
 import (
+	"context"
 	"payment-gateway/cmd/domain/charge"
 	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
 	"time"
 )
 
@@ -16,52 +18,58 @@ type ChargeModel struct {
 }
 
 type ChargeDao struct {
-	db db.Client
+	db      db.Client
+	dialect db.Dialect
 }
 
-func NewChargeDao(db db.Client) *ChargeDao {
-	return &ChargeDao{db: db}
+func NewChargeDao(client db.Client, dialect db.Dialect) *ChargeDao {
+	return &ChargeDao{db: client, dialect: dialect}
 }
 
-func (p *ChargeDao) Insert(c *charge.Entity) (*charge.Entity, error) {
+func (p *ChargeDao) Insert(ctx context.Context, c *charge.Entity) (*charge.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ChargeDao", "Insert")
 	query := `INSERT INTO charges 
 		( amount, category, payment_id, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?)`
 
-	res, err := p.db.Exec(query,
+	id, err := p.dialect.Insert(ctx, p.db, query,
 		c.Amount(),
 		c.Category(),
 		c.PaymentId(),
-		c.CreatedAt().Format("2006-01-02 15:04:05"),
-		c.UpdatedAt().Format("2006-01-02 15:04:05"),
+		c.CreatedAt(),
+		c.UpdatedAt(),
 	)
 	if err != nil {
 		return nil, err
 	}
-
-	id, err := res.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
 	c.SetId(id)
 
 	return c, nil
 }
 
-func (p *ChargeDao) FindById(id int64) (*charge.Entity, error) {
+func (p *ChargeDao) FindById(ctx context.Context, id int64) (*charge.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ChargeDao", "FindById")
 	query := `SELECT id, amount, category, payment_id, created_at, updated_at FROM charges WHERE id = ?`
 
 	var model ChargeModel
 
-	row, err := p.db.Query(query, id)
+	row, err := p.db.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
-	for row.Next() {
-		err := row.Scan(&model.Id, &model.Amount, &model.Category, &model.PaymentId, &model.CreatedAt, &model.UpdatedAt)
-		if err != nil {
+	defer row.Close()
+
+	if !row.Next() {
+		if err := row.Err(); err != nil {
 			return nil, err
 		}
+
+		return nil, charge.ErrNotFound
+	}
+
+	err = row.Scan(&model.Id, &model.Amount, &model.Category, &model.PaymentId, &model.CreatedAt, &model.UpdatedAt)
+	if err != nil {
+		return nil, err
 	}
 
 	chargeEntity := charge.NewChargeBuilder().
@@ -76,14 +84,17 @@ func (p *ChargeDao) FindById(id int64) (*charge.Entity, error) {
 	return chargeEntity, nil
 }
 
-func (p *ChargeDao) FindByOrderId(id int64) ([]charge.Entity, error) {
-	query := `SELECT c.id, c.amount, c.category, c.payment_id, c.created_at, c.updated_at FROM charges c inner join payments p on c.payment_id = p.id where p.order_id = ?`
+func (p *ChargeDao) FindByOrderId(ctx context.Context, id int64) ([]charge.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "ChargeDao", "FindByOrderId")
+	query := `SELECT c.id, c.amount, c.category, c.payment_id, c.created_at, c.updated_at FROM charges c inner join payments p on c.payment_id = p.id where p.order_id = ? ORDER BY c.id`
 
 	var charges []charge.Entity
-	row, err := p.db.Query(query, id)
+	row, err := p.db.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
+	defer row.Close()
+
 	for row.Next() {
 		var model ChargeModel
 		err := row.Scan(&model.Id, &model.Amount, &model.Category, &model.PaymentId, &model.CreatedAt, &model.UpdatedAt)
@@ -103,5 +114,39 @@ func (p *ChargeDao) FindByOrderId(id int64) ([]charge.Entity, error) {
 		charges = append(charges, *chargeEntity)
 	}
 
-	return charges, nil
+	return charges, row.Err()
+}
+
+func (p *ChargeDao) SumChargesByOrder(ctx context.Context, orderId int64) (map[string]float64, error) {
+	ctx = metrics.WithOperation(ctx, "ChargeDao", "SumChargesByOrder")
+	query := `SELECT c.category, SUM(c.amount) FROM charges c inner join payments p on c.payment_id = p.id where p.order_id = ? GROUP BY c.category`
+
+	return p.sumByCategory(ctx, query, orderId)
+}
+
+func (p *ChargeDao) SumChargesByOrderAsOf(ctx context.Context, orderId int64, asOf time.Time) (map[string]float64, error) {
+	ctx = metrics.WithOperation(ctx, "ChargeDao", "SumChargesByOrderAsOf")
+	query := `SELECT c.category, SUM(c.amount) FROM charges c inner join payments p on c.payment_id = p.id where p.order_id = ? AND c.created_at <= ? GROUP BY c.category`
+
+	return p.sumByCategory(ctx, query, orderId, asOf.Local())
+}
+
+func (p *ChargeDao) sumByCategory(ctx context.Context, query string, args ...any) (map[string]float64, error) {
+	row, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	sums := map[string]float64{}
+	for row.Next() {
+		var category string
+		var sum float64
+		if err := row.Scan(&category, &sum); err != nil {
+			return nil, err
+		}
+		sums[category] = sum
+	}
+
+	return sums, row.Err()
 }
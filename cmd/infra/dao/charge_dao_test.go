@@ -1,6 +1,7 @@
 package dao_test
 
 import (
+	"context"
 	"payment-gateway/cmd/domain/charge"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
 )
 
 func TestChargeDao_Insert(t *testing.T) {
@@ -24,8 +26,8 @@ func TestChargeDao_Insert(t *testing.T) {
 		assert.NoError(t, err)
 		defer db.Close()
 
-		createdAt := chargeEntity.CreatedAt().Format("2006-01-02 15:04:05")
-		updatedAt := chargeEntity.UpdatedAt().Format("2006-01-02 15:04:05")
+		createdAt := chargeEntity.CreatedAt()
+		updatedAt := chargeEntity.UpdatedAt()
 
 		mock.ExpectExec(`INSERT INTO charges`).
 			WithArgs(
@@ -37,8 +39,8 @@ func TestChargeDao_Insert(t *testing.T) {
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.Insert(chargeEntity)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), chargeEntity)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
@@ -55,8 +57,8 @@ func TestChargeDao_Insert(t *testing.T) {
 		mock.ExpectExec(`INSERT INTO charges`).
 			WillReturnError(assert.AnError)
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.Insert(chargeEntity)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), chargeEntity)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -71,8 +73,8 @@ func TestChargeDao_Insert(t *testing.T) {
 		mock.ExpectExec(`INSERT INTO charges`).
 			WillReturnResult(sqlmock.NewErrorResult(assert.AnError))
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.Insert(chargeEntity)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), chargeEntity)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -95,8 +97,8 @@ func TestChargeDao_FindById(t *testing.T) {
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
@@ -120,15 +122,15 @@ func TestChargeDao_FindById(t *testing.T) {
 			WithArgs(expectedID).
 			WillReturnError(assert.AnError)
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should return empty entity when no rows found", func(t *testing.T) {
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
@@ -140,16 +142,11 @@ func TestChargeDao_FindById(t *testing.T) {
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
-		assert.NoError(t, err)
-		if assert.NotNil(t, result) {
-			assert.Equal(t, int64(0), result.Id())
-			assert.Equal(t, int64(0), result.PaymentId())
-			assert.Equal(t, "", result.Category())
-			assert.Equal(t, 0.0, result.Amount())
-		}
+		assert.ErrorIs(t, err, charge.ErrNotFound)
+		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -166,11 +163,65 @@ func TestChargeDao_FindById(t *testing.T) {
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewChargeDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestChargeDao_SumChargesByOrder(t *testing.T) {
+	t.Run("should sum the charges of the order by category", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT c.category, SUM\(c.amount\) FROM charges c inner join payments p on c.payment_id = p.id where p.order_id = \? GROUP BY c.category`).
+			WithArgs(int64(123)).
+			WillReturnRows(sqlmock.NewRows([]string{"category", "sum"}).
+				AddRow("financial_fee", "10.00").
+				AddRow("process_fee", "40.50"))
+
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		sums, err := dao.SumChargesByOrder(context.Background(), 123)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"financial_fee": 10, "process_fee": 40.5}, sums)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT c.category`).WillReturnError(assert.AnError)
+
+		dao := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		sums, err := dao.SumChargesByOrder(context.Background(), 123)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, sums)
+	})
+}
+
+func TestChargeDao_SumChargesByOrderAsOf(t *testing.T) {
+	t.Run("should sum only the charges created up to the time", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		asOf := time.Date(2024, 1, 31, 23, 59, 59, 0, time.Local)
+		mock.ExpectQuery(`SELECT c.category, SUM\(c.amount\) FROM charges c .+ where p.order_id = \? AND c.created_at <= \? GROUP BY c.category`).
+			WithArgs(int64(123), asOf).
+			WillReturnRows(sqlmock.NewRows([]string{"category", "sum"}).AddRow("financial_fee", "10.00"))
+
+		sums, err := dao.NewChargeDao(dbclient.NewTxClient(db), dbclient.MySQL).SumChargesByOrderAsOf(context.Background(), 123, asOf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"financial_fee": 10}, sums)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
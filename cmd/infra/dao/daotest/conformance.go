@@ -0,0 +1,988 @@
+// Package daotest holds the behaviour every payment.Dao, order.Dao,
+// charge.Dao and audit.Dao implementation must share, whatever stores the
+// rows.
+package daotest
+
+import (
+	"context"
+	"fmt"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/auditlog"
+	"payment-gateway/cmd/infra/logging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingId is never generated by the implementations under test.
+const missingId = int64(1) << 40
+
+// AuditKey is the key the audit DAOs under test seal their entries with.
+var AuditKey = []byte("conformance-audit-key")
+
+type Fixture struct {
+	Payments payment.Dao
+	Orders   order.Dao
+	Charges  charge.Dao
+	Audit    audit.Dao
+	// Transaction runs fn in a transaction of the store under test.
+	Transaction func(ctx context.Context, fn func(ctx context.Context) error) error
+	// NewMerchant stores a merchant and returns its id.
+	NewMerchant func(t *testing.T) int64
+	// NewOrder stores a pending order of the merchant for amount and returns
+	// its id. Orders are created outside the gateway, so order.Dao cannot
+	// insert them.
+	NewOrder func(t *testing.T, merchantId int64, amount float64) int64
+}
+
+// Run checks the DAOs returned by setup, which is called once per scenario.
+func Run(t *testing.T, setup func(t *testing.T) Fixture) {
+	t.Run("payments", func(t *testing.T) { testPayments(t, setup) })
+	t.Run("payment search", func(t *testing.T) { testPaymentSearch(t, setup) })
+	t.Run("orders", func(t *testing.T) { testOrders(t, setup) })
+	t.Run("charges", func(t *testing.T) { testCharges(t, setup) })
+	t.Run("history", func(t *testing.T) { testHistory(t, setup) })
+	t.Run("audit", func(t *testing.T) { testAudit(t, setup) })
+}
+
+func testPayments(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+
+	t.Run("should generate distinct ids on insert", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 300)
+
+		first, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+		second, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 50, "Cash"))
+		require.NoError(t, err)
+
+		assert.NotZero(t, first.Id())
+		assert.Greater(t, second.Id(), first.Id())
+	})
+
+	t.Run("should find a payment by id", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 300)
+		pay := payment.NewPayment(orderId, 120.5, "CreditCard")
+		pay.SetMerchantId(merchantId)
+		pay.SetDetails("card ending 4242")
+		created, err := f.Payments.Insert(ctx, pay)
+		require.NoError(t, err)
+
+		found, err := f.Payments.FindById(ctx, created.Id())
+
+		require.NoError(t, err)
+		assert.Equal(t, created.Id(), found.Id())
+		assert.Equal(t, merchantId, found.MerchantId())
+		assert.Equal(t, orderId, found.OrderID())
+		assert.Equal(t, 120.5, found.Amount())
+		assert.Equal(t, "CreditCard", found.Type())
+		assert.Equal(t, "pending", found.Status())
+		assert.Equal(t, "card ending 4242", found.Details())
+		assert.False(t, found.CreatedAt().IsZero())
+
+		locked, err := f.Payments.FindByIdForUpdate(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, found.Id(), locked.Id())
+		assert.Equal(t, found.Amount(), locked.Amount())
+	})
+
+	t.Run("should return not found for a missing payment", func(t *testing.T) {
+		f := setup(t)
+
+		found, err := f.Payments.FindById(ctx, missingId)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, found)
+
+		_, err = f.Payments.FindByIdForUpdate(ctx, missingId)
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+	})
+
+	t.Run("should persist the status and details on update only", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 300)
+		created, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+
+		loaded, err := f.Payments.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		require.NoError(t, loaded.Process("Success", "authorized"))
+		unchanged, err := f.Payments.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, "pending", unchanged.Status())
+		assert.Empty(t, unchanged.Details())
+
+		_, err = f.Payments.Update(ctx, loaded)
+		require.NoError(t, err)
+		updated, err := f.Payments.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, "approved", updated.Status())
+		assert.Equal(t, "authorized", updated.Details())
+	})
+
+	t.Run("should list only the payments of the order", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 300)
+		otherId := f.NewOrder(t, merchantId, 300)
+		first, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+		second, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 50, "Cash"))
+		require.NoError(t, err)
+		_, err = f.Payments.Insert(ctx, payment.NewPayment(otherId, 10, "Cash"))
+		require.NoError(t, err)
+
+		payments, err := f.Payments.FindByOrderId(ctx, orderId)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{first.Id(), second.Id()}, paymentIds(payments))
+
+		none, err := f.Payments.FindByOrderId(ctx, f.NewOrder(t, merchantId, 10))
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("should reject payments for a missing order", func(t *testing.T) {
+		f := setup(t)
+
+		_, err := f.Payments.Insert(ctx, payment.NewPayment(missingId, 10, "Cash"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should sum only the approved payments of the order", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 500)
+		otherId := f.NewOrder(t, merchantId, 500)
+		for _, p := range []struct {
+			orderId     int64
+			amount      float64
+			processType string
+		}{
+			{orderId, 100.25, "Success"},
+			{orderId, 50.5, "Success"},
+			{orderId, 70, "Failure"},
+			{orderId, 30, ""},
+			{otherId, 200, "Success"},
+		} {
+			pay, err := f.Payments.Insert(ctx, payment.NewPayment(p.orderId, p.amount, "CreditCard"))
+			require.NoError(t, err)
+			if p.processType != "" {
+				require.NoError(t, pay.Process(p.processType, ""))
+				_, err = f.Payments.Update(ctx, pay)
+				require.NoError(t, err)
+			}
+		}
+
+		sum, err := f.Payments.SumApprovedByOrder(ctx, orderId)
+		require.NoError(t, err)
+		assert.InDelta(t, 150.75, sum, 0.001)
+
+		none, err := f.Payments.SumApprovedByOrder(ctx, f.NewOrder(t, merchantId, 10))
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, none)
+	})
+
+	t.Run("should summarize the payments of the order by status and type", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 500)
+		otherId := f.NewOrder(t, merchantId, 500)
+		for _, p := range []struct {
+			orderId     int64
+			amount      float64
+			paymentType string
+			processType string
+		}{
+			{orderId, 100.25, "CreditCard", "Success"},
+			{orderId, 50.5, "CreditCard", "Success"},
+			{orderId, 70, "CashSlip", "Success"},
+			{orderId, 30, "CreditCard", "Failure"},
+			{orderId, 20, "CreditCard", ""},
+			{otherId, 200, "CreditCard", "Success"},
+		} {
+			pay, err := f.Payments.Insert(ctx, payment.NewPayment(p.orderId, p.amount, p.paymentType))
+			require.NoError(t, err)
+			if p.processType != "" {
+				require.NoError(t, pay.Process(p.processType, ""))
+				_, err = f.Payments.Update(ctx, pay)
+				require.NoError(t, err)
+			}
+		}
+
+		summaries, err := f.Payments.SummarizeByOrder(ctx, orderId)
+
+		require.NoError(t, err)
+		require.Len(t, summaries, 4)
+		for i, expected := range []payment.Summary{
+			{Status: "approved", Type: "CashSlip", Count: 1, Amount: 70},
+			{Status: "approved", Type: "CreditCard", Count: 2, Amount: 150.75},
+			{Status: "pending", Type: "CreditCard", Count: 1, Amount: 20},
+			{Status: "reproved", Type: "CreditCard", Count: 1, Amount: 30},
+		} {
+			assert.Equal(t, expected.Status, summaries[i].Status)
+			assert.Equal(t, expected.Type, summaries[i].Type)
+			assert.Equal(t, expected.Count, summaries[i].Count)
+			assert.InDelta(t, expected.Amount, summaries[i].Amount, 0.001)
+		}
+
+		none, err := f.Payments.SummarizeByOrder(ctx, f.NewOrder(t, merchantId, 10))
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("should generate unique ids under concurrent inserts", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 1000)
+
+		var wg sync.WaitGroup
+		ids := make([]int64, 20)
+		for i := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				created, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 1, "Cash"))
+				if assert.NoError(t, err) {
+					ids[i] = created.Id()
+				}
+			}()
+		}
+		wg.Wait()
+
+		payments, err := f.Payments.FindByOrderId(ctx, orderId)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, ids, paymentIds(payments))
+	})
+}
+
+func testPaymentSearch(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	// seed stores payments of the merchant with distinct creation times and a
+	// tie on amount, processing the ones with a processType.
+	seed := func(t *testing.T, f Fixture, merchantId, orderId int64) []int64 {
+		var ids []int64
+		for _, p := range []struct {
+			amount      float64
+			paymentType string
+			created     time.Duration
+			processType string
+		}{
+			{30, "CreditCard", 2 * time.Hour, "Success"},
+			{10, "Cash", 0, ""},
+			{20, "CreditCard", 4 * time.Hour, "Failure"},
+			{10, "CashSlip", time.Hour, "Success"},
+			{50, "CreditCard", 3 * time.Hour, ""},
+		} {
+			pay := payment.NewPaymentBuilder().WithMerchantId(merchantId).WithOrderId(orderId).WithAmount(p.amount).WithType(p.paymentType).WithCreatedAt(base.Add(p.created)).Build()
+			created, err := f.Payments.Insert(ctx, pay)
+			require.NoError(t, err)
+			if p.processType != "" {
+				require.NoError(t, created.Process(p.processType, ""))
+				_, err = f.Payments.Update(ctx, created)
+				require.NoError(t, err)
+			}
+			ids = append(ids, created.Id())
+		}
+
+		return ids
+	}
+
+	t.Run("should filter by order, status, type, amount and creation time", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 1000)
+		ids := seed(t, f, merchantId, orderId)
+		seed(t, f, merchantId, f.NewOrder(t, merchantId, 1000))
+
+		for name, tc := range map[string]struct {
+			search   payment.Search
+			expected []int64
+		}{
+			"order":    {payment.Search{}, ids},
+			"status":   {payment.Search{Status: payment.StatusApproved}, []int64{ids[0], ids[3]}},
+			"type":     {payment.Search{Type: "CreditCard"}, []int64{ids[0], ids[2], ids[4]}},
+			"amount":   {payment.Search{MinAmount: 10, MaxAmount: 20}, []int64{ids[1], ids[2], ids[3]}},
+			"created":  {payment.Search{CreatedFrom: base.Add(time.Hour).UTC(), CreatedTo: base.Add(3 * time.Hour).UTC()}, []int64{ids[0], ids[3]}},
+			"combined": {payment.Search{Type: "CreditCard", MinAmount: 25, Status: payment.StatusPending}, []int64{ids[4]}},
+		} {
+			tc.search.MerchantId = merchantId
+			tc.search.OrderId = orderId
+			tc.search.Limit = 10
+
+			payments, err := f.Payments.Search(ctx, tc.search)
+
+			require.NoError(t, err, name)
+			assert.Equal(t, tc.expected, paymentIds(payments), name)
+		}
+	})
+
+	t.Run("should page through every sort order with a cursor", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 1000)
+		ids := seed(t, f, merchantId, orderId)
+
+		for _, tc := range []struct {
+			sort       string
+			descending bool
+			expected   []int64
+		}{
+			{payment.SortId, false, []int64{ids[0], ids[1], ids[2], ids[3], ids[4]}},
+			{payment.SortId, true, []int64{ids[4], ids[3], ids[2], ids[1], ids[0]}},
+			{payment.SortAmount, false, []int64{ids[1], ids[3], ids[2], ids[0], ids[4]}},
+			{payment.SortAmount, true, []int64{ids[4], ids[0], ids[2], ids[3], ids[1]}},
+			{payment.SortCreatedAt, false, []int64{ids[1], ids[3], ids[0], ids[4], ids[2]}},
+			{payment.SortCreatedAt, true, []int64{ids[2], ids[4], ids[0], ids[3], ids[1]}},
+		} {
+			search := payment.Search{MerchantId: merchantId, OrderId: orderId, Sort: tc.sort, Descending: tc.descending, Limit: 2}
+			var listed []int64
+			for pages := 0; pages < 5; pages++ {
+				payments, err := f.Payments.Search(ctx, search)
+				require.NoError(t, err)
+				listed = append(listed, paymentIds(payments)...)
+				if len(payments) < search.Limit {
+					break
+				}
+				after := payment.CursorOf(payments[len(payments)-1])
+				search.After = &after
+			}
+
+			assert.Equal(t, tc.expected, listed, "sort %s descending %v", tc.sort, tc.descending)
+		}
+	})
+
+	t.Run("should search only the payments of the merchant", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		ids := seed(t, f, merchantId, f.NewOrder(t, merchantId, 1000))
+		otherId := f.NewMerchant(t)
+		seed(t, f, otherId, f.NewOrder(t, otherId, 1000))
+
+		payments, err := f.Payments.Search(ctx, payment.Search{MerchantId: merchantId, Limit: 20})
+		require.NoError(t, err)
+		assert.Equal(t, ids, paymentIds(payments))
+
+		payments, err = f.Payments.Search(ctx, payment.Search{Limit: 20})
+		require.NoError(t, err)
+		assert.Empty(t, payments)
+	})
+}
+
+func testOrders(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+
+	t.Run("should find an order by id", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		id := f.NewOrder(t, merchantId, 80)
+
+		found, err := f.Orders.FindById(ctx, id)
+
+		require.NoError(t, err)
+		assert.Equal(t, id, found.Id())
+		assert.Equal(t, merchantId, found.MerchantId())
+		assert.Equal(t, 80.0, found.Amount())
+		assert.False(t, found.IsPaid())
+		assert.Equal(t, order.Balance{}, found.Balance())
+
+		locked, err := f.Orders.FindByIdForUpdate(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, locked.Id())
+	})
+
+	t.Run("should insert an order", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+
+		inserted, err := f.Orders.Insert(ctx, order.NewOrderBuilder().WithMerchantId(merchantId).WithStatus("pending").WithAmount(120).WithUpdatedAt(time.Now()).Build())
+		require.NoError(t, err)
+		require.NotZero(t, inserted.Id())
+
+		found, err := f.Orders.FindById(ctx, inserted.Id())
+		require.NoError(t, err)
+		assert.Equal(t, merchantId, found.MerchantId())
+		assert.Equal(t, "pending", found.Status())
+		assert.Equal(t, 120.0, found.Amount())
+	})
+
+	t.Run("should return not found for a missing order", func(t *testing.T) {
+		f := setup(t)
+
+		found, err := f.Orders.FindById(ctx, missingId)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, found)
+
+		_, err = f.Orders.FindByIdForUpdate(ctx, missingId)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+	})
+
+	t.Run("should persist the status on update", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		id := f.NewOrder(t, merchantId, 80)
+		found, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+
+		found.SetStatus("paid")
+		_, err = f.Orders.Update(ctx, found)
+		require.NoError(t, err)
+
+		updated, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, updated.IsPaid())
+		assert.Equal(t, 80.0, updated.Amount())
+	})
+
+	t.Run("should persist the balance on update", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		id := f.NewOrder(t, merchantId, 80)
+		found, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+
+		found.SetPaidAmount(50.25)
+		found.SetChargesAmount(2.1)
+		found.SetRefundedAmount(10)
+		_, err = f.Orders.Update(ctx, found)
+		require.NoError(t, err)
+
+		updated, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, order.Balance{Paid: 50.25, Charges: 2.1, Refunded: 10}, updated.Balance())
+		assert.Equal(t, 29.75, updated.RemainingDebt())
+	})
+
+	t.Run("should report orders whose balance drifted from their payments and charges", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		id := f.NewOrder(t, merchantId, 300)
+		untouched := f.NewOrder(t, merchantId, 300)
+		pay, err := f.Payments.Insert(ctx, payment.NewPayment(id, 100, "CreditCard"))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", ""))
+		_, err = f.Payments.Update(ctx, pay)
+		require.NoError(t, err)
+		fee, ok := charge.NewCharge(*pay)
+		require.True(t, ok)
+		_, err = f.Charges.Insert(ctx, fee)
+		require.NoError(t, err)
+
+		drift, found := driftOf(t, f, id)
+		require.True(t, found)
+		assert.Equal(t, order.Balance{}, drift.Stored)
+		assert.InDelta(t, 100, drift.Computed.Paid, 0.001)
+		assert.InDelta(t, fee.Amount(), drift.Computed.Charges, 0.001)
+		_, found = driftOf(t, f, untouched)
+		assert.False(t, found)
+
+		or, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+		require.NoError(t, or.ProcessPayment(*pay))
+		or.AddCharge(fee.Amount())
+		_, err = f.Orders.Update(ctx, or)
+		require.NoError(t, err)
+
+		_, found = driftOf(t, f, id)
+		assert.False(t, found)
+	})
+
+	t.Run("should not report drift for fees with fractional cents", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		id := f.NewOrder(t, merchantId, 300)
+		for range 3 {
+			pay, err := f.Payments.Insert(ctx, payment.NewPayment(id, 3.33, "CreditCard"))
+			require.NoError(t, err)
+			require.NoError(t, pay.Process("Success", ""))
+			_, err = f.Payments.Update(ctx, pay)
+			require.NoError(t, err)
+			fee, ok := charge.NewCharge(*pay)
+			require.True(t, ok)
+			_, err = f.Charges.Insert(ctx, fee)
+			require.NoError(t, err)
+
+			or, err := f.Orders.FindById(ctx, id)
+			require.NoError(t, err)
+			require.NoError(t, or.ProcessPayment(*pay))
+			or.AddCharge(fee.Amount())
+			_, err = f.Orders.Update(ctx, or)
+			require.NoError(t, err)
+		}
+
+		_, found := driftOf(t, f, id)
+		assert.False(t, found)
+	})
+
+	t.Run("should check orders in batches after the given id", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		var ids []int64
+		for range 3 {
+			or, err := f.Orders.FindById(ctx, f.NewOrder(t, merchantId, 100))
+			require.NoError(t, err)
+			or.SetPaidAmount(10)
+			_, err = f.Orders.Update(ctx, or)
+			require.NoError(t, err)
+			ids = append(ids, or.Id())
+		}
+
+		drifts, next, err := f.Orders.FindBalanceDrift(ctx, ids[0]-1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{ids[0], ids[1]}, driftIds(drifts))
+		assert.Equal(t, ids[1], next)
+
+		drifts, next, err = f.Orders.FindBalanceDrift(ctx, next, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{ids[2]}, driftIds(drifts))
+		assert.Equal(t, ids[2], next)
+	})
+
+	t.Run("should start over once it reaches the last order", func(t *testing.T) {
+		f := setup(t)
+		id := f.NewOrder(t, f.NewMerchant(t), 100)
+
+		_, next, err := f.Orders.FindBalanceDrift(ctx, id-1, 1<<20)
+
+		require.NoError(t, err)
+		assert.Zero(t, next)
+	})
+}
+
+// driftOf checks just the given order, since the store may be shared with
+// other scenarios that leave balances behind on purpose.
+func driftOf(t *testing.T, f Fixture, orderId int64) (order.Drift, bool) {
+	drifts, _, err := f.Orders.FindBalanceDrift(context.Background(), orderId-1, 1)
+	require.NoError(t, err)
+	if len(drifts) == 1 && drifts[0].OrderId == orderId {
+		return drifts[0], true
+	}
+
+	return order.Drift{}, false
+}
+
+func driftIds(drifts []order.Drift) []int64 {
+	ids := make([]int64, 0, len(drifts))
+	for _, drift := range drifts {
+		ids = append(ids, drift.OrderId)
+	}
+
+	return ids
+}
+
+func testCharges(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+	approved := func(t *testing.T, f Fixture, orderId int64, amount float64, paymentType string) *payment.Entity {
+		pay, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, amount, paymentType))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", ""))
+		_, err = f.Payments.Update(ctx, pay)
+		require.NoError(t, err)
+
+		return pay
+	}
+
+	t.Run("should join the charges of every payment of the order", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 300)
+		otherId := f.NewOrder(t, merchantId, 300)
+		var inserted []int64
+		expected := 0.0
+		for _, pay := range []*payment.Entity{
+			approved(t, f, orderId, 100, "CreditCard"),
+			approved(t, f, orderId, 100, "CashSlip"),
+		} {
+			fee, ok := charge.NewCharge(*pay)
+			require.True(t, ok)
+			created, err := f.Charges.Insert(ctx, fee)
+			require.NoError(t, err)
+			assert.NotZero(t, created.Id())
+			inserted = append(inserted, created.Id())
+			expected += fee.Amount()
+		}
+		otherFee, _ := charge.NewCharge(*approved(t, f, otherId, 100, "CreditCard"))
+		_, err := f.Charges.Insert(ctx, otherFee)
+		require.NoError(t, err)
+
+		charges, err := f.Charges.FindByOrderId(ctx, orderId)
+
+		require.NoError(t, err)
+		var ids []int64
+		total := 0.0
+		for _, c := range charges {
+			ids = append(ids, c.Id())
+			total += c.Amount()
+		}
+		assert.ElementsMatch(t, inserted, ids)
+		assert.InDelta(t, expected, total, 0.001)
+	})
+
+	t.Run("should sum the charges of the order by category", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 500)
+		otherId := f.NewOrder(t, merchantId, 500)
+		expected := map[string]float64{}
+		for _, pay := range []*payment.Entity{
+			approved(t, f, orderId, 100, "CreditCard"),
+			approved(t, f, orderId, 50, "CreditCard"),
+			approved(t, f, orderId, 100, "CashSlip"),
+			approved(t, f, otherId, 100, "CashSlip"),
+		} {
+			fee, ok := charge.NewCharge(*pay)
+			require.True(t, ok)
+			_, err := f.Charges.Insert(ctx, fee)
+			require.NoError(t, err)
+			if pay.OrderID() == orderId {
+				expected[fee.Category()] += fee.Amount()
+			}
+		}
+
+		sums, err := f.Charges.SumChargesByOrder(ctx, orderId)
+
+		require.NoError(t, err)
+		assert.Len(t, sums, len(expected))
+		for category, amount := range expected {
+			assert.InDelta(t, amount, sums[category], 0.001, category)
+		}
+
+		none, err := f.Charges.SumChargesByOrder(ctx, f.NewOrder(t, merchantId, 10))
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("should return no charges for an order without payments", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+
+		charges, err := f.Charges.FindByOrderId(ctx, f.NewOrder(t, merchantId, 10))
+
+		require.NoError(t, err)
+		assert.Empty(t, charges)
+	})
+
+	t.Run("should reject charges for a missing payment", func(t *testing.T) {
+		f := setup(t)
+
+		_, err := f.Charges.Insert(ctx, charge.NewChargeBuilder().WithAmount(1).WithCategory("financial_fee").WithPaymentId(missingId).Build())
+
+		assert.Error(t, err)
+	})
+}
+
+func paymentIds(payments []payment.Entity) []int64 {
+	var ids []int64
+	for _, pay := range payments {
+		ids = append(ids, pay.Id())
+	}
+
+	return ids
+}
+
+func testHistory(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	at := func(offset time.Duration) time.Time { return base.Add(offset) }
+	// store creates a payment at created and processes it at processed,
+	// charging it when approved.
+	store := func(t *testing.T, f Fixture, orderId int64, amount float64, paymentType string, created time.Duration, processType string, processed time.Duration) int64 {
+		pay, err := f.Payments.Insert(ctx, payment.NewPaymentBuilder().WithOrderId(orderId).WithAmount(amount).WithType(paymentType).
+			WithStatus(payment.StatusPending).WithCreatedAt(at(created)).WithUpdatedAt(at(created)).Build())
+		require.NoError(t, err)
+		if processType == "" {
+			return pay.Id()
+		}
+
+		require.NoError(t, pay.Process(processType, ""))
+		pay.SetUpdatedAt(at(processed))
+		_, err = f.Payments.Update(ctx, pay)
+		require.NoError(t, err)
+		if fee, ok := charge.NewCharge(*pay); ok {
+			fee.SetCreatedAt(at(processed))
+			_, err = f.Charges.Insert(ctx, fee)
+			require.NoError(t, err)
+		}
+
+		return pay.Id()
+	}
+
+	f := setup(t)
+	merchantId := f.NewMerchant(t)
+	orderId := f.NewOrder(t, merchantId, 300)
+	card := store(t, f, orderId, 100, "CreditCard", 0, "Success", 2*time.Hour)
+	slip := store(t, f, orderId, 50, "CashSlip", time.Hour, "Failure", 3*time.Hour)
+	pending := store(t, f, orderId, 30, "CreditCard", 4*time.Hour, "", 0)
+	store(t, f, f.NewOrder(t, merchantId, 300), 200, "CreditCard", 0, "Success", time.Hour)
+
+	for name, tc := range map[string]struct {
+		asOf     time.Time
+		statuses map[int64]string
+		charges  map[string]float64
+	}{
+		"before the first payment": {at(-time.Hour), map[int64]string{}, map[string]float64{}},
+		"before processing":        {at(time.Hour), map[int64]string{card: "pending", slip: "pending"}, map[string]float64{}},
+		"at the approval":          {at(2 * time.Hour), map[int64]string{card: "approved", slip: "pending"}, map[string]float64{"financial_fee": 10}},
+		"after every change": {
+			at(5 * time.Hour),
+			map[int64]string{card: "approved", slip: "reproved", pending: "pending"},
+			map[string]float64{"financial_fee": 10},
+		},
+		"in another time zone": {at(2 * time.Hour).UTC(), map[int64]string{card: "approved", slip: "pending"}, map[string]float64{"financial_fee": 10}},
+	} {
+		t.Run("should rebuild the payments and charges "+name, func(t *testing.T) {
+			payments, err := f.Payments.FindByOrderIdAsOf(ctx, orderId, tc.asOf)
+			require.NoError(t, err)
+			statuses := map[int64]string{}
+			for _, pay := range payments {
+				statuses[pay.Id()] = pay.Status()
+			}
+			assert.Equal(t, tc.statuses, statuses)
+
+			charges, err := f.Charges.SumChargesByOrderAsOf(ctx, orderId, tc.asOf)
+			require.NoError(t, err)
+			assert.Len(t, charges, len(tc.charges))
+			for category, amount := range tc.charges {
+				assert.InDelta(t, amount, charges[category], 0.001, category)
+			}
+		})
+	}
+
+	t.Run("should record who changed the payment and the order, and why", func(t *testing.T) {
+		f := setup(t)
+		merchantId := f.NewMerchant(t)
+		orderId := f.NewOrder(t, merchantId, 100)
+		ctx := logging.WithActor(logging.WithRequestId(ctx, "req-1"), "api_key:abc123")
+		pay, err := f.Payments.Insert(ctx, payment.NewPayment(orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+		or, err := f.Orders.FindById(ctx, orderId)
+		require.NoError(t, err)
+
+		require.NoError(t, pay.Process("Success", "authorized"))
+		require.NoError(t, or.ProcessPayment(*pay))
+		_, err = f.Payments.Update(ctx, pay)
+		require.NoError(t, err)
+		_, err = f.Orders.Update(ctx, or)
+		require.NoError(t, err)
+		_, err = f.Payments.Update(ctx, pay)
+		require.NoError(t, err)
+
+		payments, err := f.Payments.FindTransitions(ctx, pay.Id())
+		require.NoError(t, err)
+		require.Len(t, payments, 1)
+		assert.NotZero(t, payments[0].Id)
+		assert.Equal(t, pay.Id(), payments[0].PaymentId)
+		assert.Equal(t, "pending", payments[0].From)
+		assert.Equal(t, "approved", payments[0].To)
+		assert.Equal(t, "authorized", payments[0].Reason)
+		assert.Equal(t, "api_key:abc123", payments[0].Actor)
+		assert.Equal(t, "req-1", payments[0].RequestId)
+		assert.WithinDuration(t, pay.UpdatedAt(), payments[0].CreatedAt, time.Second)
+
+		orders, err := f.Orders.FindTransitions(ctx, orderId)
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, orderId, orders[0].OrderId)
+		assert.Equal(t, "pending", orders[0].From)
+		assert.Equal(t, "paid", orders[0].To)
+		assert.Equal(t, fmt.Sprintf("payment %d approved", pay.Id()), orders[0].Reason)
+		assert.Equal(t, "api_key:abc123", orders[0].Actor)
+
+		none, err := f.Orders.FindTransitions(ctx, f.NewOrder(t, merchantId, 10))
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+}
+
+// transactorFunc adapts Fixture.Transaction to auditlog.Transactor.
+type transactorFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+
+func (f transactorFunc) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f(ctx, fn)
+}
+
+// testAudit starts from the head it finds, since SQL stores share the log
+// between scenarios.
+func testAudit(t *testing.T, setup func(t *testing.T) Fixture) {
+	ctx := context.Background()
+	entry := func(entityId int64) *audit.Entry {
+		return &audit.Entry{
+			Entity:    audit.EntityPayment,
+			EntityId:  entityId,
+			Action:    audit.ActionUpdate,
+			Before:    `{"status":"pending"}`,
+			After:     `{"status":"approved"}`,
+			Actor:     "api_key:abc123",
+			RequestId: "req-1",
+			CreatedAt: time.Now(),
+		}
+	}
+	// walk reads every entry after seq and checks they link from hash on.
+	walk := func(t *testing.T, f Fixture, seq int64, hash string) []audit.Entry {
+		entries, err := f.Audit.FindAfter(ctx, seq, 1000)
+		require.NoError(t, err)
+		for _, e := range entries {
+			require.Empty(t, e.Follows(seq, hash, AuditKey))
+			seq, hash = e.Seq, e.Hash
+		}
+
+		return entries
+	}
+
+	t.Run("should link every entry to the head and move the head", func(t *testing.T) {
+		f := setup(t)
+		seq, hash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+
+		first, err := f.Audit.Append(ctx, entry(1))
+		require.NoError(t, err)
+		second, err := f.Audit.Append(ctx, entry(2))
+		require.NoError(t, err)
+
+		assert.Equal(t, seq+1, first.Seq)
+		assert.Equal(t, hash, first.PrevHash)
+		assert.Equal(t, first.Hash, second.PrevHash)
+		headSeq, headHash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, second.Seq, headSeq)
+		assert.Equal(t, second.Hash, headHash)
+	})
+
+	t.Run("should read the entries back as they were hashed", func(t *testing.T) {
+		f := setup(t)
+		seq, hash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+		appended, err := f.Audit.Append(ctx, entry(7))
+		require.NoError(t, err)
+
+		entries := walk(t, f, seq, hash)
+
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, appended.Hash, entries[0].Hash)
+			assert.Equal(t, int64(7), entries[0].EntityId)
+			assert.Equal(t, `{"status":"approved"}`, entries[0].After)
+			assert.Equal(t, "api_key:abc123", entries[0].Actor)
+			assert.True(t, appended.CreatedAt.Equal(entries[0].CreatedAt))
+		}
+	})
+
+	t.Run("should page after a seq in chain order", func(t *testing.T) {
+		f := setup(t)
+		seq, _, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+		for i := int64(1); i <= 3; i++ {
+			_, err := f.Audit.Append(ctx, entry(i))
+			require.NoError(t, err)
+		}
+
+		page, err := f.Audit.FindAfter(ctx, seq+1, 1)
+
+		require.NoError(t, err)
+		if assert.Len(t, page, 1) {
+			assert.Equal(t, seq+2, page[0].Seq)
+		}
+	})
+
+	t.Run("should not fork the chain under concurrent appends", func(t *testing.T) {
+		f := setup(t)
+		seq, hash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := int64(1); i <= 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- f.Transaction(ctx, func(ctx context.Context) error {
+					_, err := f.Audit.Append(ctx, entry(i))
+					return err
+				})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, walk(t, f, seq, hash), 10)
+	})
+
+	t.Run("should read the rows back in the state their last entries recorded", func(t *testing.T) {
+		f := setup(t)
+		seq, hash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+		recorder := auditlog.NewRecorder(f.Audit, transactorFunc(f.Transaction))
+		payments := auditlog.NewPaymentDao(f.Payments, recorder)
+		orders := auditlog.NewOrderDao(f.Orders, recorder)
+		charges := auditlog.NewChargeDao(f.Charges, recorder)
+		id := f.NewOrder(t, f.NewMerchant(t), 100)
+
+		pay, err := payments.Insert(ctx, payment.NewPayment(id, 33.33, "CreditCard"))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", `{"authorization":"A1"}`))
+		_, err = payments.Update(ctx, pay)
+		require.NoError(t, err)
+		fee, ok := charge.NewCharge(*pay)
+		require.True(t, ok)
+		_, err = charges.Insert(ctx, fee)
+		require.NoError(t, err)
+		or, err := f.Orders.FindById(ctx, id)
+		require.NoError(t, err)
+		require.NoError(t, or.ProcessPayment(*pay))
+		or.AddCharge(fee.Amount())
+		_, err = orders.Update(ctx, or)
+		require.NoError(t, err)
+
+		// The log is shared between scenarios, so keep the last entry of
+		// each entity by hand instead of verifying from the genesis.
+		latest := map[string]audit.Entry{}
+		for _, entry := range walk(t, f, seq, hash) {
+			latest[entry.Entity] = entry
+		}
+		require.Len(t, latest, 3)
+		states := auditlog.NewStates(f.Payments, f.Orders, f.Charges, nil)
+		verification := audit.NewVerification(AuditKey)
+		for _, entry := range latest {
+			current, found, err := states.State(ctx, entry.Entity, entry.EntityId)
+			require.NoError(t, err)
+			require.NoError(t, verification.Compare(entry, current, found))
+		}
+		assert.Empty(t, verification.Drifts)
+	})
+
+	t.Run("should drop the entry with a rolled back transaction", func(t *testing.T) {
+		f := setup(t)
+		seq, hash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+
+		err = f.Transaction(ctx, func(ctx context.Context) error {
+			if _, err := f.Audit.Append(ctx, entry(1)); err != nil {
+				return err
+			}
+			return fmt.Errorf("rolled back")
+		})
+		require.EqualError(t, err, "rolled back")
+
+		headSeq, headHash, err := f.Audit.Head(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, seq, headSeq)
+		assert.Equal(t, hash, headHash)
+		assert.Empty(t, walk(t, f, seq, hash))
+	})
+}
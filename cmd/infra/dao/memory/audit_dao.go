@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+)
+
+type AuditDao struct {
+	store *Store
+	key   []byte
+}
+
+func NewAuditDao(store *Store, key []byte) *AuditDao {
+	return &AuditDao{store: store, key: key}
+}
+
+func (a *AuditDao) Append(_ context.Context, entry *audit.Entry) (*audit.Entry, error) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	seq, hash := a.head()
+	entry.Link(seq, hash, a.key)
+	a.store.auditLog = append(a.store.auditLog, *entry)
+
+	return entry, nil
+}
+
+func (a *AuditDao) FindAfter(_ context.Context, seq int64, limit int) ([]audit.Entry, error) {
+	a.store.mu.RLock()
+	defer a.store.mu.RUnlock()
+
+	var entries []audit.Entry
+	for _, entry := range a.store.auditLog {
+		if entry.Seq > seq && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (a *AuditDao) Head(_ context.Context) (int64, string, error) {
+	a.store.mu.RLock()
+	defer a.store.mu.RUnlock()
+
+	seq, hash := a.head()
+	return seq, hash, nil
+}
+
+func (a *AuditDao) head() (int64, string) {
+	if len(a.store.auditLog) == 0 {
+		return 0, audit.Genesis
+	}
+
+	last := a.store.auditLog[len(a.store.auditLog)-1]
+	return last.Seq, last.Hash
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/payment"
+	"time"
+)
+
+type ChargeDao struct {
+	store *Store
+}
+
+func NewChargeDao(store *Store) *ChargeDao {
+	return &ChargeDao{store: store}
+}
+
+func (c *ChargeDao) Insert(_ context.Context, ch *charge.Entity) (*charge.Entity, error) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if _, ok := c.store.payments[ch.PaymentId()]; !ok {
+		return nil, payment.ErrNotFound
+	}
+
+	c.store.lastChargeId++
+	ch.SetId(c.store.lastChargeId)
+	c.store.charges[ch.Id()] = *ch
+
+	return ch, nil
+}
+
+func (c *ChargeDao) FindById(_ context.Context, id int64) (*charge.Entity, error) {
+	c.store.mu.RLock()
+	defer c.store.mu.RUnlock()
+
+	stored, ok := c.store.charges[id]
+	if !ok {
+		return nil, charge.ErrNotFound
+	}
+
+	return &stored, nil
+}
+
+// FindByOrderId returns the charges of every payment of the order.
+func (c *ChargeDao) FindByOrderId(_ context.Context, id int64) ([]charge.Entity, error) {
+	c.store.mu.RLock()
+	defer c.store.mu.RUnlock()
+
+	var charges []charge.Entity
+	for _, chargeId := range sortedIds(c.store.charges) {
+		stored := c.store.charges[chargeId]
+		if pay, ok := c.store.payments[stored.PaymentId()]; ok && pay.OrderID() == id {
+			charges = append(charges, stored)
+		}
+	}
+
+	return charges, nil
+}
+
+func (c *ChargeDao) SumChargesByOrder(_ context.Context, orderId int64) (map[string]float64, error) {
+	c.store.mu.RLock()
+	defer c.store.mu.RUnlock()
+
+	sums := map[string]float64{}
+	for _, stored := range c.store.charges {
+		if pay, ok := c.store.payments[stored.PaymentId()]; ok && pay.OrderID() == orderId {
+			sums[stored.Category()] += stored.Amount()
+		}
+	}
+
+	return sums, nil
+}
+
+func (c *ChargeDao) SumChargesByOrderAsOf(_ context.Context, orderId int64, asOf time.Time) (map[string]float64, error) {
+	c.store.mu.RLock()
+	defer c.store.mu.RUnlock()
+
+	sums := map[string]float64{}
+	for _, stored := range c.store.charges {
+		if pay, ok := c.store.payments[stored.PaymentId()]; ok && pay.OrderID() == orderId && !stored.CreatedAt().After(asOf) {
+			sums[stored.Category()] += stored.Amount()
+		}
+	}
+
+	return sums, nil
+}
@@ -0,0 +1,38 @@
+package memory_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/dao/daotest"
+	"payment-gateway/cmd/infra/dao/memory"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	daotest.Run(t, func(t *testing.T) daotest.Fixture {
+		store := memory.NewStore()
+		orders := memory.NewOrderDao(store)
+		var lastMerchantId int64
+
+		return daotest.Fixture{
+			Payments:    memory.NewPaymentDao(store),
+			Orders:      orders,
+			Charges:     memory.NewChargeDao(store),
+			Audit:       memory.NewAuditDao(store, daotest.AuditKey),
+			Transaction: store.Transaction,
+			NewMerchant: func(t *testing.T) int64 {
+				lastMerchantId++
+
+				return lastMerchantId
+			},
+			NewOrder: func(t *testing.T, merchantId int64, amount float64) int64 {
+				created, err := orders.Insert(context.Background(), order.NewOrderBuilder().WithMerchantId(merchantId).WithStatus("pending").WithAmount(amount).Build())
+				require.NoError(t, err)
+
+				return created.Id()
+			},
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/logging"
+)
+
+type OrderDao struct {
+	store *Store
+}
+
+func NewOrderDao(store *Store) *OrderDao {
+	return &OrderDao{store: store}
+}
+
+func (o *OrderDao) Insert(_ context.Context, or *order.Entity) (*order.Entity, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+
+	o.store.lastOrderId++
+	or.SetId(o.store.lastOrderId)
+	o.store.orders[or.Id()] = *or
+
+	return or, nil
+}
+
+func (o *OrderDao) FindById(_ context.Context, id int64) (*order.Entity, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	stored, ok := o.store.orders[id]
+	if !ok {
+		return nil, order.ErrNotFound
+	}
+
+	return &stored, nil
+}
+
+// FindByIdForUpdate needs no lock of its own: Store.Transaction already runs
+// one transaction at a time.
+func (o *OrderDao) FindByIdForUpdate(ctx context.Context, id int64) (*order.Entity, error) {
+	return o.FindById(ctx, id)
+}
+
+func (o *OrderDao) Update(ctx context.Context, or *order.Entity) (*order.Entity, error) {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+
+	if stored, ok := o.store.orders[or.Id()]; ok {
+		stored.SetStatus(or.Status())
+		stored.SetPaidAmount(or.PaidAmount())
+		stored.SetChargesAmount(or.ChargesAmount())
+		stored.SetRefundedAmount(or.RefundedAmount())
+		stored.SetUpdateAt(or.UpdatedAt())
+		o.store.orders[or.Id()] = stored
+		for _, transition := range or.Transitions() {
+			transition.Id = int64(len(o.store.orderHistory) + 1)
+			transition.OrderId = or.Id()
+			transition.Actor = logging.Actor(ctx)
+			transition.RequestId = logging.RequestId(ctx)
+			transition.CreatedAt = or.UpdatedAt()
+			o.store.orderHistory = append(o.store.orderHistory, transition)
+		}
+	}
+	or.ClearTransitions()
+
+	return or, nil
+}
+
+func (o *OrderDao) FindTransitions(_ context.Context, orderId int64) ([]order.Transition, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	var transitions []order.Transition
+	for _, transition := range o.store.orderHistory {
+		if transition.OrderId == orderId {
+			transitions = append(transitions, transition)
+		}
+	}
+
+	return transitions, nil
+}
+
+func (o *OrderDao) FindBalanceDrift(_ context.Context, afterId int64, limit int) ([]order.Drift, int64, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	computed := map[int64]order.Balance{}
+	for _, pay := range o.store.payments {
+		balance := computed[pay.OrderID()]
+		switch pay.Status() {
+		case payment.StatusApproved:
+			balance.Paid += pay.Amount()
+		case payment.StatusRefunded:
+			balance.Refunded += pay.Amount()
+		}
+		computed[pay.OrderID()] = balance
+	}
+	for _, ch := range o.store.charges {
+		pay := o.store.payments[ch.PaymentId()]
+		balance := computed[pay.OrderID()]
+		balance.Charges += ch.Amount()
+		computed[pay.OrderID()] = balance
+	}
+
+	var drifts []order.Drift
+	var checked int
+	var last int64
+	for _, id := range sortedIds(o.store.orders) {
+		if id <= afterId {
+			continue
+		}
+		if checked == limit {
+			return drifts, last, nil
+		}
+		checked++
+		last = id
+		or := o.store.orders[id]
+		stored := or.Balance()
+		if !stored.Matches(computed[id]) {
+			drifts = append(drifts, order.Drift{OrderId: id, Stored: stored, Computed: computed[id]})
+		}
+	}
+	if checked < limit {
+		last = 0
+	}
+
+	return drifts, last, nil
+}
@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/logging"
+	"sort"
+	"time"
+)
+
+type PaymentDao struct {
+	store *Store
+}
+
+func NewPaymentDao(store *Store) *PaymentDao {
+	return &PaymentDao{store: store}
+}
+
+func (p *PaymentDao) Insert(_ context.Context, pay *payment.Entity) (*payment.Entity, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	if _, ok := p.store.orders[pay.OrderID()]; !ok {
+		return nil, order.ErrNotFound
+	}
+
+	p.store.lastPaymentId++
+	pay.SetId(p.store.lastPaymentId)
+	p.store.payments[pay.Id()] = *pay
+
+	return pay, nil
+}
+
+func (p *PaymentDao) FindById(_ context.Context, id int64) (*payment.Entity, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	stored, ok := p.store.payments[id]
+	if !ok {
+		return nil, payment.ErrNotFound
+	}
+
+	return &stored, nil
+}
+
+// FindByIdForUpdate needs no lock of its own: Store.Transaction already runs
+// one transaction at a time.
+func (p *PaymentDao) FindByIdForUpdate(ctx context.Context, id int64) (*payment.Entity, error) {
+	return p.FindById(ctx, id)
+}
+
+func (p *PaymentDao) FindByOrderId(_ context.Context, id int64) ([]payment.Entity, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var payments []payment.Entity
+	for _, paymentId := range sortedIds(p.store.payments) {
+		if stored := p.store.payments[paymentId]; stored.OrderID() == id {
+			payments = append(payments, stored)
+		}
+	}
+
+	return payments, nil
+}
+
+func (p *PaymentDao) Search(_ context.Context, search payment.Search) ([]payment.Entity, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var payments []payment.Entity
+	for _, stored := range p.store.payments {
+		if search.Matches(stored) {
+			payments = append(payments, stored)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return search.Less(payment.CursorOf(payments[i]), payment.CursorOf(payments[j]))
+	})
+	if len(payments) > search.Limit {
+		payments = payments[:search.Limit]
+	}
+
+	return payments, nil
+}
+
+// Update persists the same columns as the SQL DAO, the status and the
+// details, and appends the transitions to the history.
+func (p *PaymentDao) Update(ctx context.Context, pay *payment.Entity) (*payment.Entity, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	if stored, ok := p.store.payments[pay.Id()]; ok {
+		stored.SetStatus(pay.Status())
+		stored.SetDetails(pay.Details())
+		p.store.payments[pay.Id()] = stored
+		for _, transition := range pay.Transitions() {
+			transition.Id = int64(len(p.store.paymentHistory) + 1)
+			transition.PaymentId = pay.Id()
+			transition.Actor = logging.Actor(ctx)
+			transition.RequestId = logging.RequestId(ctx)
+			transition.CreatedAt = pay.UpdatedAt()
+			p.store.paymentHistory = append(p.store.paymentHistory, transition)
+		}
+	}
+	pay.ClearTransitions()
+
+	return pay, nil
+}
+
+func (p *PaymentDao) FindTransitions(_ context.Context, paymentId int64) ([]payment.Transition, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var transitions []payment.Transition
+	for _, transition := range p.store.paymentHistory {
+		if transition.PaymentId == paymentId {
+			transitions = append(transitions, transition)
+		}
+	}
+
+	return transitions, nil
+}
+
+func (p *PaymentDao) FindByOrderIdAsOf(_ context.Context, orderId int64, asOf time.Time) ([]payment.Entity, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var payments []payment.Entity
+	for _, id := range sortedIds(p.store.payments) {
+		stored := p.store.payments[id]
+		if stored.OrderID() != orderId || stored.CreatedAt().After(asOf) {
+			continue
+		}
+
+		past := payment.NewPaymentBuilder().
+			WithId(stored.Id()).
+			WithOrderId(stored.OrderID()).
+			WithMerchantId(stored.MerchantId()).
+			WithStatus(payment.StatusPending).
+			WithType(stored.Type()).
+			WithAmount(stored.Amount()).
+			WithCreatedAt(stored.CreatedAt()).
+			WithUpdatedAt(stored.CreatedAt()).
+			Build()
+		for _, transition := range p.store.paymentHistory {
+			if transition.PaymentId == id && !transition.CreatedAt.After(asOf) {
+				past.SetStatus(transition.To)
+				past.SetUpdatedAt(transition.CreatedAt)
+			}
+		}
+		payments = append(payments, *past)
+	}
+
+	return payments, nil
+}
+
+func (p *PaymentDao) SumApprovedByOrder(_ context.Context, orderId int64) (float64, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var sum float64
+	for _, stored := range p.store.payments {
+		if stored.OrderID() == orderId && stored.Status() == payment.StatusApproved {
+			sum += stored.Amount()
+		}
+	}
+
+	return sum, nil
+}
+
+func (p *PaymentDao) SummarizeByOrder(_ context.Context, orderId int64) ([]payment.Summary, error) {
+	p.store.mu.RLock()
+	defer p.store.mu.RUnlock()
+
+	var payments []payment.Entity
+	for _, id := range sortedIds(p.store.payments) {
+		if stored := p.store.payments[id]; stored.OrderID() == orderId {
+			payments = append(payments, stored)
+		}
+	}
+
+	return payment.Summarize(payments), nil
+}
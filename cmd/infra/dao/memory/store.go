@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"sort"
+	"sync"
+)
+
+// Store holds the rows shared by the in-memory DAOs, so payments can only
+// reference existing orders and charges can be joined to their payment's
+// order, as the foreign keys of the SQL schema enforce. Entities are copied in
+// and out, so changes only become visible through Insert and Update.
+type Store struct {
+	txMu     sync.Mutex
+	mu       sync.RWMutex
+	orders   map[int64]order.Entity
+	payments map[int64]payment.Entity
+	charges  map[int64]charge.Entity
+	// The histories and the audit log are append-only, like their tables.
+	paymentHistory []payment.Transition
+	orderHistory   []order.Transition
+	auditLog       []audit.Entry
+
+	lastOrderId   int64
+	lastPaymentId int64
+	lastChargeId  int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		orders:   map[int64]order.Entity{},
+		payments: map[int64]payment.Entity{},
+		charges:  map[int64]charge.Entity{},
+	}
+}
+
+type txKey struct{}
+
+// Transaction runs fn while holding every other transaction off and restores
+// the rows it changed when fn fails. Writes made outside a transaction are
+// not isolated from it.
+func (s *Store) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.RLock()
+	snapshot := Store{
+		orders:         maps.Clone(s.orders),
+		payments:       maps.Clone(s.payments),
+		charges:        maps.Clone(s.charges),
+		paymentHistory: s.paymentHistory,
+		orderHistory:   s.orderHistory,
+		auditLog:       s.auditLog,
+		lastOrderId:    s.lastOrderId,
+		lastPaymentId:  s.lastPaymentId,
+		lastChargeId:   s.lastChargeId,
+	}
+	s.mu.RUnlock()
+
+	err := fn(context.WithValue(ctx, txKey{}, true))
+	if err != nil {
+		s.mu.Lock()
+		s.orders, s.payments, s.charges = snapshot.orders, snapshot.payments, snapshot.charges
+		s.paymentHistory, s.orderHistory = snapshot.paymentHistory, snapshot.orderHistory
+		s.auditLog = snapshot.auditLog
+		s.lastOrderId, s.lastPaymentId, s.lastChargeId = snapshot.lastOrderId, snapshot.lastPaymentId, snapshot.lastChargeId
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+// sortedIds returns the keys of rows in insertion order.
+func sortedIds[T any](rows map[int64]T) []int64 {
+	ids := make([]int64, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
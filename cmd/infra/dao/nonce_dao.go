@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
+	"time"
+)
+
+type NonceDao struct {
+	db      db.Client
+	dialect db.Dialect
+}
+
+func NewNonceDao(client db.Client, dialect db.Dialect) *NonceDao {
+	return &NonceDao{db: client, dialect: dialect}
+}
+
+func (n *NonceDao) Register(ctx context.Context, keyId int64, nonce string, expiresAt time.Time) (bool, error) {
+	ctx = metrics.WithOperation(ctx, "NonceDao", "Register")
+	query := n.dialect.InsertIgnore(`INSERT INTO api_key_nonces (api_key_id, nonce, expires_at) VALUES (?, ?, ?)`)
+
+	res, err := n.db.ExecContext(ctx, query, keyId, nonce, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (n *NonceDao) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx = metrics.WithOperation(ctx, "NonceDao", "PurgeExpired")
+	query := `DELETE FROM api_key_nonces WHERE expires_at < ?`
+
+	res, err := n.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
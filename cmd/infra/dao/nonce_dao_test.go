@@ -0,0 +1,101 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+)
+
+func TestNonceDao_Register(t *testing.T) {
+	expiresAt := time.Now().Add(5 * time.Minute)
+
+	t.Run("should register a new nonce", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT IGNORE INTO api_key_nonces`).
+			WithArgs(int64(1), "nonce", expiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		dao := dao.NewNonceDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		registered, err := dao.Register(context.Background(), 1, "nonce", expiresAt)
+
+		assert.NoError(t, err)
+		assert.True(t, registered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should not register a replayed nonce", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT IGNORE INTO api_key_nonces`).
+			WithArgs(int64(1), "nonce", expiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		dao := dao.NewNonceDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		registered, err := dao.Register(context.Background(), 1, "nonce", expiresAt)
+
+		assert.NoError(t, err)
+		assert.False(t, registered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when insert fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT IGNORE INTO api_key_nonces`).
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewNonceDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		registered, err := dao.Register(context.Background(), 1, "nonce", expiresAt)
+
+		assert.Error(t, err)
+		assert.False(t, registered)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNonceDao_PurgeExpired(t *testing.T) {
+	t.Run("should delete expired nonces", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectExec(`DELETE FROM api_key_nonces WHERE expires_at < \?`).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		dao := dao.NewNonceDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		purged, err := dao.PurgeExpired(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when delete fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`DELETE FROM api_key_nonces`).
+			WillReturnError(assert.AnError)
+
+		dao := dao.NewNonceDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		_, err = dao.PurgeExpired(context.Background(), time.Now())
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
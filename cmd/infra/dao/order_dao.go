@@ -1,60 +1,121 @@
 package dao
 
 import (
+	"context"
 	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/infra/metrics"
 	"time"
 )
 
 type OrderModel struct {
-	Id        int64
-	Amount    float64
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id             int64
+	MerchantId     int64
+	Amount         float64
+	Status         string
+	PaidAmount     float64
+	ChargesAmount  float64
+	RefundedAmount float64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type OrderDao struct {
-	db db.Client
+	db      db.Client
+	dialect db.Dialect
 }
 
-func NewOrderDao(db db.Client) *OrderDao {
-	return &OrderDao{db: db}
+func NewOrderDao(client db.Client, dialect db.Dialect) *OrderDao {
+	return &OrderDao{db: client, dialect: dialect}
 }
 
-func (p *OrderDao) FindById(id int64) (*order.Entity, error) {
-	query := `SELECT id, status, amount, created_at, updated_at FROM orders WHERE id = ?`
+func (p *OrderDao) Insert(ctx context.Context, or *order.Entity) (*order.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "Insert")
+	query := `INSERT INTO orders
+		(merchant_id, status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
-	var pay OrderModel
+	id, err := p.dialect.Insert(ctx, p.db, query,
+		nullableId(or.MerchantId()),
+		or.Status(),
+		or.Amount(),
+		or.PaidAmount(),
+		or.ChargesAmount(),
+		or.RefundedAmount(),
+		or.CreatedAt(),
+		or.UpdatedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	or.SetId(id)
+
+	return or, nil
+}
 
-	row, err := p.db.Query(query, id)
+func (p *OrderDao) FindById(ctx context.Context, id int64) (*order.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "FindById")
+	query := `SELECT id, COALESCE(merchant_id, 0), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`
+
+	return p.findOne(ctx, query, id)
+}
+
+func (p *OrderDao) FindByIdForUpdate(ctx context.Context, id int64) (*order.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "FindByIdForUpdate")
+	query := p.dialect.ForUpdate(`SELECT id, COALESCE(merchant_id, 0), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`)
+
+	return p.findOne(ctx, query, id)
+}
+
+func (p *OrderDao) findOne(ctx context.Context, query string, id int64) (*order.Entity, error) {
+	var or OrderModel
+
+	row, err := p.db.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
-	for row.Next() {
-		err := row.Scan(&pay.Id, &pay.Status, &pay.Amount, &pay.CreatedAt, &pay.UpdatedAt)
-		if err != nil {
+	defer row.Close()
+
+	if !row.Next() {
+		if err := row.Err(); err != nil {
 			return nil, err
 		}
+
+		return nil, order.ErrNotFound
 	}
 
-	orderEntity := order.NewOrderBuilder().WithId(pay.Id).
-		WithStatus(pay.Status).
-		WithAmount(pay.Amount).
-		WithCreatedAt(pay.CreatedAt).
-		WithUpdatedAt(pay.UpdatedAt).
+	err = row.Scan(&or.Id, &or.MerchantId, &or.Status, &or.Amount, &or.PaidAmount, &or.ChargesAmount, &or.RefundedAmount, &or.CreatedAt, &or.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	orderEntity := order.NewOrderBuilder().WithId(or.Id).
+		WithMerchantId(or.MerchantId).
+		WithStatus(or.Status).
+		WithAmount(or.Amount).
+		WithPaidAmount(or.PaidAmount).
+		WithChargesAmount(or.ChargesAmount).
+		WithRefundedAmount(or.RefundedAmount).
+		WithCreatedAt(or.CreatedAt).
+		WithUpdatedAt(or.UpdatedAt).
 		Build()
 
 	return orderEntity, nil
 }
 
-func (p *OrderDao) Update(or *order.Entity) (*order.Entity, error) {
-	query := `UPDATE orders 
-		SET status = ?, updated_at = ?
+func (p *OrderDao) Update(ctx context.Context, or *order.Entity) (*order.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "Update")
+	query := `UPDATE orders
+		SET status = ?, paid_amount = ?, charges_amount = ?, refunded_amount = ?, updated_at = ?
 		WHERE id = ?`
 
-	_, err := p.db.Exec(query,
+	_, err := p.db.ExecContext(ctx, query,
 		or.Status(),
+		or.PaidAmount(),
+		or.ChargesAmount(),
+		or.RefundedAmount(),
 		or.UpdatedAt(),
 		or.Id(),
 	)
@@ -62,5 +123,92 @@ func (p *OrderDao) Update(or *order.Entity) (*order.Entity, error) {
 		return nil, err
 	}
 
+	for _, transition := range or.Transitions() {
+		_, err = p.db.ExecContext(ctx, `INSERT INTO order_status_history
+			(order_id, from_status, status, reason, actor, request_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			or.Id(),
+			transition.From,
+			transition.To,
+			transition.Reason,
+			logging.Actor(ctx),
+			logging.RequestId(ctx),
+			or.UpdatedAt(),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	or.ClearTransitions()
+
 	return or, nil
 }
+
+func (p *OrderDao) FindTransitions(ctx context.Context, orderId int64) ([]order.Transition, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "FindTransitions")
+	query := `SELECT id, order_id, from_status, status, reason, actor, request_id, created_at FROM order_status_history WHERE order_id = ? ORDER BY id`
+
+	rows, err := p.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []order.Transition
+	for rows.Next() {
+		var transition order.Transition
+		err := rows.Scan(&transition.Id, &transition.OrderId, &transition.From, &transition.To,
+			&transition.Reason, &transition.Actor, &transition.RequestId, &transition.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+
+	return transitions, rows.Err()
+}
+
+// FindBalanceDrift compares the stored balances of a batch of orders with
+// sums over their payments and charges.
+func (p *OrderDao) FindBalanceDrift(ctx context.Context, afterId int64, limit int) ([]order.Drift, int64, error) {
+	ctx = metrics.WithOperation(ctx, "OrderDao", "FindBalanceDrift")
+	query := `SELECT o.id, o.paid_amount, o.charges_amount, o.refunded_amount,
+			COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = o.id AND p.status = ?), 0) AS paid,
+			COALESCE((SELECT SUM(c.amount) FROM charges c INNER JOIN payments p ON c.payment_id = p.id WHERE p.order_id = o.id), 0) AS charges,
+			COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = o.id AND p.status = ?), 0) AS refunded
+		FROM (SELECT id, paid_amount, charges_amount, refunded_amount FROM orders WHERE id > ? ORDER BY id LIMIT ?) o
+		ORDER BY o.id`
+
+	rows, err := p.db.QueryContext(ctx, query, payment.StatusApproved, payment.StatusRefunded, afterId, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var drifts []order.Drift
+	var checked int
+	var last int64
+	for rows.Next() {
+		var drift order.Drift
+		err := rows.Scan(&drift.OrderId,
+			&drift.Stored.Paid, &drift.Stored.Charges, &drift.Stored.Refunded,
+			&drift.Computed.Paid, &drift.Computed.Charges, &drift.Computed.Refunded,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		checked++
+		last = drift.OrderId
+		if !drift.Stored.Matches(drift.Computed) {
+			drifts = append(drifts, drift)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if checked < limit {
+		last = 0
+	}
+
+	return drifts, last, nil
+}
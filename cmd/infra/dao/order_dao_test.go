@@ -1,14 +1,56 @@
 package dao_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/logging"
 )
 
+func TestOrderDao_Insert(t *testing.T) {
+	now := time.Now()
+	orderEntity := order.NewOrderBuilder().WithMerchantId(4).WithStatus("pending").WithAmount(100.5).WithCreatedAt(now).WithUpdatedAt(now).Build()
+
+	t.Run("should insert order successfully", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO orders`).
+			WithArgs(int64(4), "pending", 100.5, 0.0, 0.0, 0.0, now, now).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).Insert(context.Background(), orderEntity)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, int64(7), result.Id())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when database operation fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`INSERT INTO orders`).
+			WillReturnError(assert.AnError)
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).Insert(context.Background(), orderEntity)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+}
+
 func TestOrderDao_FindById(t *testing.T) {
 	t.Run("should find payment by ID successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -17,66 +59,88 @@ func TestOrderDao_FindById(t *testing.T) {
 
 		now := time.Now()
 		expectedID := int64(1)
-		rows := sqlmock.NewRows([]string{"id", "status", "amount", "created_at", "updated_at"}).
-			AddRow(expectedID, "approved", 100.5, now, now)
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "status", "amount", "paid_amount", "charges_amount", "refunded_amount", "created_at", "updated_at"}).
+			AddRow(expectedID, 4, "approved", 100.5, 60.25, 3.1, 0.0, now, now)
 
-		mock.ExpectQuery(`SELECT id, status, amount, created_at, updated_at FROM orders WHERE id = ?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewOrderDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
 			assert.Equal(t, expectedID, result.Id())
+			assert.Equal(t, int64(4), result.MerchantId())
 			assert.Equal(t, "approved", result.Status())
 			assert.Equal(t, 100.5, result.Amount())
+			assert.Equal(t, 60.25, result.PaidAmount())
+			assert.Equal(t, 3.1, result.ChargesAmount())
+			assert.Equal(t, 0.0, result.RefundedAmount())
 			assert.NotNil(t, result.CreatedAt())
 			assert.NotNil(t, result.UpdatedAt())
 		}
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("should stop waiting when the context deadline expires", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`).
+			WithArgs(int64(1)).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "merchant_id", "status", "amount", "paid_amount", "charges_amount", "refunded_amount", "created_at", "updated_at"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		dao := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(ctx, 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
 	t.Run("should return error when query fails", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		expectedID := int64(1)
-		mock.ExpectQuery(`SELECT id, status, amount, created_at, updated_at FROM orders WHERE id = ?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`).
 			WithArgs(expectedID).
 			WillReturnError(assert.AnError)
 
-		dao := dao.NewOrderDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should return empty entity when no rows found", func(t *testing.T) {
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		expectedID := int64(1)
-		rows := sqlmock.NewRows([]string{"id", "status", "amount", "created_at", "updated_at"})
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "status", "amount", "paid_amount", "charges_amount", "refunded_amount", "created_at", "updated_at"})
 
-		mock.ExpectQuery(`SELECT id, status, amount, created_at, updated_at FROM orders WHERE id = ?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewOrderDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
-		assert.NoError(t, err)
-		if assert.NotNil(t, result) {
-			assert.Equal(t, int64(0), result.Id())
-			assert.Equal(t, "", result.Status())
-			assert.Equal(t, 0.0, result.Amount())
-		}
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -89,15 +153,199 @@ func TestOrderDao_FindById(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "order_id"}).
 			AddRow(expectedID, 123)
 
-		mock.ExpectQuery(`SELECT id, status, amount, created_at, updated_at FROM orders WHERE id = ?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewOrderDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOrderDao_FindByIdForUpdate(t *testing.T) {
+	columns := []string{"id", "merchant_id", "status", "amount", "paid_amount", "charges_amount", "refunded_amount", "created_at", "updated_at"}
+
+	t.Run("should lock the order row on engines with row locks", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`FROM orders WHERE id = \? FOR UPDATE$`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 4, "pending", 100.0, 40.0, 2.0, 0.0, time.Now(), time.Now()))
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindByIdForUpdate(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 60.0, result.RemainingDebt())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`FROM orders WHERE id = \?$`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.SQLite).FindByIdForUpdate(context.Background(), 1)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOrderDao_Update(t *testing.T) {
+	now := time.Now()
+	or := order.NewOrderBuilder().WithId(1).WithStatus("paid").WithAmount(100).
+		WithPaidAmount(100).
+		WithChargesAmount(4.5).
+		WithUpdatedAt(now).
+		Build()
+
+	t.Run("should persist the status and the balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE orders`).
+			WithArgs("paid", 100.0, 4.5, 0.0, now, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).Update(context.Background(), or)
+
+		assert.NoError(t, err)
+		assert.Equal(t, or, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when update fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(`UPDATE orders`).WillReturnError(assert.AnError)
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).Update(context.Background(), or)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
+	})
+
+	t.Run("should append the transitions of the order to its history", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		pending := order.NewOrderBuilder().WithId(1).WithStatus("pending").WithAmount(100).Build()
+		assert.NoError(t, pending.ProcessPayment(*payment.NewPaymentBuilder().WithId(7).WithAmount(100).WithStatus("approved").Build()))
+		ctx := logging.WithActor(logging.WithRequestId(context.Background(), "req-1"), "api_key:abc123")
+
+		mock.ExpectExec(`UPDATE orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO order_status_history\s+\(order_id, from_status, status, reason, actor, request_id, created_at\)`).
+			WithArgs(int64(1), "pending", "paid", "payment 7 approved", "api_key:abc123", "req-1", pending.UpdatedAt()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		result, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).Update(ctx, pending)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Transitions())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOrderDao_FindTransitions(t *testing.T) {
+	t.Run("should list the history of the order in order", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(`SELECT id, order_id, from_status, status, reason, actor, request_id, created_at FROM order_status_history WHERE order_id = \? ORDER BY id`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "from_status", "status", "reason", "actor", "request_id", "created_at"}).
+				AddRow(3, 1, "pending", "paid", "payment 7 approved", "api_key:abc123", "req-1", now))
+
+		transitions, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindTransitions(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []order.Transition{{
+			Id: 3, OrderId: 1, From: "pending", To: "paid", Reason: "payment 7 approved", Actor: "api_key:abc123", RequestId: "req-1", CreatedAt: now,
+		}}, transitions)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, order_id, from_status`).WillReturnError(assert.AnError)
+
+		transitions, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindTransitions(context.Background(), 1)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, transitions)
+	})
+}
+
+func TestOrderDao_FindBalanceDrift(t *testing.T) {
+	columns := []string{"id", "paid_amount", "charges_amount", "refunded_amount", "paid", "charges", "refunded"}
+
+	t.Run("should return the stored and recomputed balance of drifted orders in the batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT o.id, o.paid_amount, o.charges_amount, o.refunded_amount, (.+) FROM \(SELECT id, paid_amount, charges_amount, refunded_amount FROM orders WHERE id > \? ORDER BY id LIMIT \?\) o`).
+			WithArgs("approved", "refunded", int64(2), 2).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, 50.0, 1.0, 0.0, 80.0, 1.5, 0.0).
+				AddRow(4, 10.0, 1.0, 0.0, 10.0, 1.001, 0.0))
+
+		drifts, next, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindBalanceDrift(context.Background(), 2, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []order.Drift{{
+			OrderId:  3,
+			Stored:   order.Balance{Paid: 50, Charges: 1},
+			Computed: order.Balance{Paid: 80, Charges: 1.5},
+		}}, drifts)
+		assert.Equal(t, int64(4), next)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should start over after a short batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT o.id`).WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 50.0, 1.0, 0.0, 50.0, 1.0, 0.0))
+
+		drifts, next, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindBalanceDrift(context.Background(), 2, 10)
+
+		assert.NoError(t, err)
+		assert.Empty(t, drifts)
+		assert.Zero(t, next)
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, paid_amount`).WillReturnError(assert.AnError)
+
+		drifts, _, err := dao.NewOrderDao(dbclient.NewTxClient(db), dbclient.MySQL).FindBalanceDrift(context.Background(), 0, 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, drifts)
+	})
 }
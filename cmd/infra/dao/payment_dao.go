@@ -1,121 +1,256 @@
 package dao
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/infra/metrics"
+	"strings"
 	"time"
 )
 
 type PaymentModel struct {
-	Id        int64
-	OrderID   int64
-	Amount    float64
-	Status    string
-	Type      string
-	Details   string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id         int64
+	MerchantId int64
+	OrderID    int64
+	Amount     float64
+	Status     string
+	Type       string
+	Details    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 type PaymentDao struct {
-	db db.Client
+	db      db.Client
+	dialect db.Dialect
 }
 
-func NewPaymentDao(db db.Client) *PaymentDao {
-	return &PaymentDao{db: db}
+func NewPaymentDao(client db.Client, dialect db.Dialect) *PaymentDao {
+	return &PaymentDao{db: client, dialect: dialect}
 }
 
-func (p *PaymentDao) Insert(pay *payment.Entity) (*payment.Entity, error) {
+func (p *PaymentDao) Insert(ctx context.Context, pay *payment.Entity) (*payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "Insert")
 	query := `INSERT INTO payments 
-		(order_id, status, payment_type, amount, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)`
+		(merchant_id, order_id, status, payment_type, amount, details, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
-	res, err := p.db.Exec(query,
+	id, err := p.dialect.Insert(ctx, p.db, query,
+		nullableId(pay.MerchantId()),
 		pay.OrderID(),
 		pay.Status(),
 		pay.Type(),
 		pay.Amount(),
-		pay.CreatedAt().Format("2006-01-02 15:04:05"),
-		pay.UpdatedAt().Format("2006-01-02 15:04:05"),
+		pay.Details(),
+		pay.CreatedAt(),
+		pay.UpdatedAt(),
 	)
 	if err != nil {
 		return nil, err
 	}
-
-	id, err := res.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
 	pay.SetId(id)
 
 	return pay, nil
 }
 
-func (p *PaymentDao) FindById(id int64) (*payment.Entity, error) {
-	query := `SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL(details, '') as details, amount FROM payments WHERE id = ?`
+func (p *PaymentDao) FindById(ctx context.Context, id int64) (*payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "FindById")
+	query := `SELECT id, COALESCE(merchant_id, 0), order_id, status, payment_type, created_at, updated_at, COALESCE(details, '') AS details, amount FROM payments WHERE id = ?`
+
+	return p.findOne(ctx, query, id)
+}
+
+func (p *PaymentDao) FindByIdForUpdate(ctx context.Context, id int64) (*payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "FindByIdForUpdate")
+	query := p.dialect.ForUpdate(`SELECT id, COALESCE(merchant_id, 0), order_id, status, payment_type, created_at, updated_at, COALESCE(details, '') AS details, amount FROM payments WHERE id = ?`)
 
-	var pay PaymentModel
+	return p.findOne(ctx, query, id)
+}
 
-	row, err := p.db.Query(query, id)
+func (p *PaymentDao) findOne(ctx context.Context, query string, id int64) (*payment.Entity, error) {
+	payments, err := p.find(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
-	for row.Next() {
-		err := row.Scan(&pay.Id, &pay.OrderID, &pay.Status, &pay.Type, &pay.CreatedAt, &pay.UpdatedAt, &pay.Details, &pay.Amount)
-		if err != nil {
-			return nil, err
-		}
+	if len(payments) == 0 {
+		return nil, payment.ErrNotFound
 	}
 
-	paymentEntity := payment.NewPaymentBuilder().WithId(pay.Id).
-		WithOrderId(pay.OrderID).
-		WithStatus(pay.Status).
-		WithType(pay.Type).
-		WithCreatedAt(pay.CreatedAt).
-		WithDetails(pay.Details).
-		WithAmount(pay.Amount).
-		Build()
+	return &payments[0], nil
+}
+
+func (p *PaymentDao) FindByOrderId(ctx context.Context, id int64) ([]payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "FindByOrderId")
+	query := `SELECT id, COALESCE(merchant_id, 0), order_id, status, payment_type, created_at, updated_at, COALESCE(details, '') AS details, amount FROM payments WHERE order_id = ? ORDER BY id`
+
+	return p.find(ctx, query, id)
+}
 
-	return paymentEntity, nil
+// searchColumns maps the sort orders to the columns they sort on.
+var searchColumns = map[string]string{
+	payment.SortId:        "id",
+	payment.SortAmount:    "amount",
+	payment.SortCreatedAt: "created_at",
 }
 
-func (p *PaymentDao) FindByOrderId(id int64) ([]payment.Entity, error) {
-	query := `SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL(details, '') as details, amount FROM payments WHERE order_id = ?`
+// Search pages with the keyset of the sort column and the id, which the
+// indexes hold after the merchant, so every page is an index range scan
+// however deep it goes. The creation range is moved to
+// the local zone the rows were written in, since SQLite compares times as
+// text and Postgres timestamps drop the zone; cursor times already come from
+// the rows.
+func (p *PaymentDao) Search(ctx context.Context, search payment.Search) ([]payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "Search")
+	var conditions []string
+	var args []any
+	where := func(condition string, values ...any) {
+		conditions = append(conditions, condition)
+		args = append(args, values...)
+	}
 
-	var payments []payment.Entity
-	row, err := p.db.Query(query, id)
+	where("merchant_id = ?", search.MerchantId)
+	if search.OrderId != 0 {
+		where("order_id = ?", search.OrderId)
+	}
+	if search.Status != "" {
+		where("status = ?", search.Status)
+	}
+	if search.Type != "" {
+		where("payment_type = ?", search.Type)
+	}
+	if search.MinAmount != 0 {
+		where("amount >= ?", search.MinAmount)
+	}
+	if search.MaxAmount != 0 {
+		where("amount <= ?", search.MaxAmount)
+	}
+	if !search.CreatedFrom.IsZero() {
+		where("created_at >= ?", search.CreatedFrom.Local())
+	}
+	if !search.CreatedTo.IsZero() {
+		where("created_at < ?", search.CreatedTo.Local())
+	}
+
+	column, ok := searchColumns[search.Sort]
+	if !ok {
+		column = "id"
+	}
+	operator, direction := ">", "ASC"
+	if search.Descending {
+		operator, direction = "<", "DESC"
+	}
+
+	if after := search.After; after != nil {
+		keyset := fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, operator)
+		switch column {
+		case "amount":
+			where(keyset, after.Amount, after.Amount, after.Id)
+		case "created_at":
+			where(keyset, after.CreatedAt, after.CreatedAt, after.Id)
+		default:
+			where("id "+operator+" ?", after.Id)
+		}
+	}
+
+	order := column + " " + direction
+	if column != "id" {
+		order += ", id " + direction
+	}
+
+	query := `SELECT id, COALESCE(merchant_id, 0), order_id, status, payment_type, created_at, updated_at, COALESCE(details, '') AS details, amount FROM payments WHERE `
+	query += strings.Join(conditions, " AND ") + " ORDER BY " + order + " LIMIT ?"
+	args = append(args, search.Limit)
+
+	return p.find(ctx, query, args...)
+}
+
+func (p *PaymentDao) find(ctx context.Context, query string, args ...any) ([]payment.Entity, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	for row.Next() {
+	defer rows.Close()
+
+	var payments []payment.Entity
+	for rows.Next() {
 		var pay PaymentModel
-		err := row.Scan(&pay.Id, &pay.OrderID, &pay.Status, &pay.Type, &pay.CreatedAt, &pay.UpdatedAt, &pay.Details, &pay.Amount)
+		err := rows.Scan(&pay.Id, &pay.MerchantId, &pay.OrderID, &pay.Status, &pay.Type, &pay.CreatedAt, &pay.UpdatedAt, &pay.Details, &pay.Amount)
 		if err != nil {
 			return nil, err
 		}
 
 		paymentEntity := payment.NewPaymentBuilder().WithId(pay.Id).
+			WithMerchantId(pay.MerchantId).
 			WithOrderId(pay.OrderID).
 			WithStatus(pay.Status).
 			WithType(pay.Type).
 			WithCreatedAt(pay.CreatedAt).
 			WithDetails(pay.Details).
 			WithAmount(pay.Amount).
+			WithUpdatedAt(pay.UpdatedAt).
 			Build()
 
 		payments = append(payments, *paymentEntity)
 	}
 
-	return payments, nil
+	return payments, rows.Err()
+}
+
+func (p *PaymentDao) SumApprovedByOrder(ctx context.Context, orderId int64) (float64, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "SumApprovedByOrder")
+	query := `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = ? AND status = ?`
+
+	row, err := p.db.QueryContext(ctx, query, orderId, payment.StatusApproved)
+	if err != nil {
+		return 0, err
+	}
+	defer row.Close()
+
+	var sum float64
+	if row.Next() {
+		if err := row.Scan(&sum); err != nil {
+			return 0, err
+		}
+	}
+
+	return sum, row.Err()
+}
+
+func (p *PaymentDao) SummarizeByOrder(ctx context.Context, orderId int64) ([]payment.Summary, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "SummarizeByOrder")
+	query := `SELECT status, payment_type, COUNT(*), SUM(amount) FROM payments WHERE order_id = ? GROUP BY status, payment_type ORDER BY status, payment_type`
+
+	rows, err := p.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []payment.Summary
+	for rows.Next() {
+		var summary payment.Summary
+		if err := rows.Scan(&summary.Status, &summary.Type, &summary.Count, &summary.Amount); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
 }
 
-func (p *PaymentDao) Update(pay *payment.Entity) (*payment.Entity, error) {
+func (p *PaymentDao) Update(ctx context.Context, pay *payment.Entity) (*payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "Update")
 	query := `UPDATE payments 
-		SET status = ?, updated_at = ?
+		SET status = ?, details = ?, updated_at = ?
 		WHERE id = ?`
 
-	_, err := p.db.Exec(query,
+	_, err := p.db.ExecContext(ctx, query,
 		pay.Status(),
+		pay.Details(),
 		pay.UpdatedAt(),
 		pay.Id(),
 	)
@@ -123,5 +258,101 @@ func (p *PaymentDao) Update(pay *payment.Entity) (*payment.Entity, error) {
 		return nil, err
 	}
 
+	for _, transition := range pay.Transitions() {
+		_, err = p.db.ExecContext(ctx, `INSERT INTO payment_status_history
+			(payment_id, from_status, status, reason, actor, request_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			pay.Id(),
+			transition.From,
+			transition.To,
+			transition.Reason,
+			logging.Actor(ctx),
+			logging.RequestId(ctx),
+			pay.UpdatedAt(),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pay.ClearTransitions()
+
 	return pay, nil
 }
+
+func (p *PaymentDao) FindTransitions(ctx context.Context, paymentId int64) ([]payment.Transition, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "FindTransitions")
+	query := `SELECT id, payment_id, from_status, status, reason, actor, request_id, created_at FROM payment_status_history WHERE payment_id = ? ORDER BY id`
+
+	rows, err := p.db.QueryContext(ctx, query, paymentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []payment.Transition
+	for rows.Next() {
+		var transition payment.Transition
+		err := rows.Scan(&transition.Id, &transition.PaymentId, &transition.From, &transition.To,
+			&transition.Reason, &transition.Actor, &transition.RequestId, &transition.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+
+	return transitions, rows.Err()
+}
+
+// FindByOrderIdAsOf reads the status of each payment from the last entry of
+// its history up to asOf. Payments without one were still pending.
+func (p *PaymentDao) FindByOrderIdAsOf(ctx context.Context, orderId int64, asOf time.Time) ([]payment.Entity, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentDao", "FindByOrderIdAsOf")
+	query := `SELECT p.id, COALESCE(p.merchant_id, 0), p.order_id, COALESCE(h.status, ?), p.payment_type, p.amount, p.created_at, h.created_at
+		FROM payments p
+		LEFT JOIN payment_status_history h ON h.id = (
+			SELECT MAX(l.id) FROM payment_status_history l WHERE l.payment_id = p.id AND l.created_at <= ?
+		)
+		WHERE p.order_id = ? AND p.created_at <= ?
+		ORDER BY p.id`
+
+	asOf = asOf.Local()
+	rows, err := p.db.QueryContext(ctx, query, payment.StatusPending, asOf, orderId, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []payment.Entity
+	for rows.Next() {
+		var model PaymentModel
+		var changedAt sql.NullTime
+		err := rows.Scan(&model.Id, &model.MerchantId, &model.OrderID, &model.Status, &model.Type, &model.Amount, &model.CreatedAt, &changedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		model.UpdatedAt = model.CreatedAt
+		if changedAt.Valid {
+			model.UpdatedAt = changedAt.Time
+		}
+
+		payments = append(payments, *payment.NewPaymentBuilder().
+			WithId(model.Id).
+			WithMerchantId(model.MerchantId).
+			WithOrderId(model.OrderID).
+			WithStatus(model.Status).
+			WithType(model.Type).
+			WithAmount(model.Amount).
+			WithCreatedAt(model.CreatedAt).
+			WithUpdatedAt(model.UpdatedAt).
+			Build())
+	}
+
+	return payments, rows.Err()
+}
+
+// nullableId stores a zero id, as payments of unowned orders have for their
+// merchant, as NULL.
+func nullableId(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -1,6 +1,8 @@
 package dao_test
 
 import (
+	"context"
+	"regexp"
 	"testing"
 	"time"
 
@@ -8,32 +10,37 @@ import (
 	"github.com/stretchr/testify/assert"
 	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/logging"
 )
 
 func TestPaymentDao_Insert(t *testing.T) {
 	paymentEntity := payment.NewPayment(123, 100.5, "credit_card")
+	paymentEntity.SetMerchantId(4)
 
 	t.Run("should insert payment successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
-		createdAt := paymentEntity.CreatedAt().Format("2006-01-02 15:04:05")
-		updatedAt := paymentEntity.UpdatedAt().Format("2006-01-02 15:04:05")
+		createdAt := paymentEntity.CreatedAt()
+		updatedAt := paymentEntity.UpdatedAt()
 
 		mock.ExpectExec(`INSERT INTO payments`).
 			WithArgs(
+				int64(4),
 				paymentEntity.OrderID(),
 				paymentEntity.Status(),
 				paymentEntity.Type(),
 				paymentEntity.Amount(),
+				paymentEntity.Details(),
 				createdAt,
 				updatedAt,
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.Insert(paymentEntity)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), paymentEntity)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
@@ -50,8 +57,8 @@ func TestPaymentDao_Insert(t *testing.T) {
 		mock.ExpectExec(`INSERT INTO payments`).
 			WillReturnError(assert.AnError)
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.Insert(paymentEntity)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), paymentEntity)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -66,8 +73,8 @@ func TestPaymentDao_Insert(t *testing.T) {
 		mock.ExpectExec(`INSERT INTO payments`).
 			WillReturnResult(sqlmock.NewErrorResult(assert.AnError))
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.Insert(paymentEntity)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.Insert(context.Background(), paymentEntity)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -83,19 +90,20 @@ func TestPaymentDao_FindById(t *testing.T) {
 
 		now := time.Now()
 		expectedID := int64(1)
-		rows := sqlmock.NewRows([]string{"id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}).
-			AddRow(expectedID, 123, "approved", "credit_card", now, now, "test details", 100.5)
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}).
+			AddRow(expectedID, 4, 123, "approved", "credit_card", now, now, "test details", 100.5)
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE id = \?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
 			assert.Equal(t, expectedID, result.Id())
+			assert.Equal(t, int64(4), result.MerchantId())
 			assert.Equal(t, int64(123), result.OrderID())
 			assert.Equal(t, "approved", result.Status())
 			assert.Equal(t, "credit_card", result.Type())
@@ -113,42 +121,35 @@ func TestPaymentDao_FindById(t *testing.T) {
 		defer db.Close()
 
 		expectedID := int64(1)
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE id = \?`).
 			WithArgs(expectedID).
 			WillReturnError(assert.AnError)
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("should return empty entity when no rows found", func(t *testing.T) {
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		expectedID := int64(1)
-		rows := sqlmock.NewRows([]string{"id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"})
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"})
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE id = \?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
-		assert.NoError(t, err)
-		if assert.NotNil(t, result) {
-			assert.Equal(t, int64(0), result.Id())
-			assert.Equal(t, int64(0), result.OrderID())
-			assert.Equal(t, "", result.Status())
-			assert.Equal(t, "", result.Type())
-			assert.Equal(t, "", result.Details())
-			assert.Equal(t, 0.0, result.Amount())
-		}
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -161,12 +162,12 @@ func TestPaymentDao_FindById(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "order_id"}).
 			AddRow(expectedID, 123)
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE id = \?`).
 			WithArgs(expectedID).
 			WillReturnRows(rows)
 
-		dao := dao.NewPaymentDao(db)
-		result, err := dao.FindById(expectedID)
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := dao.FindById(context.Background(), expectedID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -174,6 +175,41 @@ func TestPaymentDao_FindById(t *testing.T) {
 	})
 }
 
+func TestPaymentDao_FindByIdForUpdate(t *testing.T) {
+	t.Run("should lock the payment row on engines with row locks", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}).
+			AddRow(1, 4, 123, "pending", "credit_card", time.Now(), time.Now(), "", 100.5)
+		mock.ExpectQuery(`FROM payments WHERE id = \? FOR UPDATE$`).
+			WithArgs(int64(1)).
+			WillReturnRows(rows)
+
+		result, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.Postgres).FindByIdForUpdate(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", result.Status())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found error when no rows found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`FROM payments WHERE id = \? FOR UPDATE$`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		result, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL).FindByIdForUpdate(context.Background(), 1)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
 func TestPaymentDao_FindByOrderId(t *testing.T) {
 	t.Run("should find payments by order ID successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -182,16 +218,16 @@ func TestPaymentDao_FindByOrderId(t *testing.T) {
 
 		now := time.Now()
 		orderID := int64(123)
-		rows := sqlmock.NewRows([]string{"id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}).
-			AddRow(1, orderID, "approved", "credit_card", now, now, "test details 1", 100.5).
-			AddRow(2, orderID, "pending", "pix", now, now, "test details 2", 200.0)
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}).
+			AddRow(1, 4, orderID, "approved", "credit_card", now, now, "test details 1", 100.5).
+			AddRow(2, 4, orderID, "pending", "pix", now, now, "test details 2", 200.0)
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE order_id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE order_id = \?`).
 			WithArgs(orderID).
 			WillReturnRows(rows)
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.FindByOrderId(orderID)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.FindByOrderId(context.Background(), orderID)
 
 		assert.NoError(t, err)
 		if assert.Len(t, result, 2) {
@@ -218,14 +254,14 @@ func TestPaymentDao_FindByOrderId(t *testing.T) {
 		defer db.Close()
 
 		orderID := int64(999)
-		rows := sqlmock.NewRows([]string{"id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"})
+		rows := sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"})
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE order_id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE order_id = \?`).
 			WithArgs(orderID).
 			WillReturnRows(rows)
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.FindByOrderId(orderID)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.FindByOrderId(context.Background(), orderID)
 
 		assert.NoError(t, err)
 		assert.Empty(t, result)
@@ -239,12 +275,12 @@ func TestPaymentDao_FindByOrderId(t *testing.T) {
 
 		orderID := int64(123)
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE order_id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE order_id = \?`).
 			WithArgs(orderID).
 			WillReturnError(assert.AnError)
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.FindByOrderId(orderID)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.FindByOrderId(context.Background(), orderID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -259,12 +295,12 @@ func TestPaymentDao_FindByOrderId(t *testing.T) {
 		orderID := int64(123)
 		rows := sqlmock.NewRows([]string{"id", "order_id"}).AddRow(1, orderID)
 
-		mock.ExpectQuery(`SELECT id, order_id, status, payment_type, created_at, updated_at, IFNULL\(details, ''\) as details, amount FROM payments WHERE order_id = \?`).
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), order_id, status, payment_type, created_at, updated_at, COALESCE\(details, ''\) AS details, amount FROM payments WHERE order_id = \?`).
 			WithArgs(orderID).
 			WillReturnRows(rows)
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.FindByOrderId(orderID)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.FindByOrderId(context.Background(), orderID)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -272,31 +308,106 @@ func TestPaymentDao_FindByOrderId(t *testing.T) {
 	})
 }
 
+func TestPaymentDao_Search(t *testing.T) {
+	columns := []string{"id", "merchant_id", "order_id", "status", "payment_type", "created_at", "updated_at", "details", "amount"}
+
+	t.Run("should filter and page on the id by default", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM payments WHERE merchant_id = ? AND order_id = ? AND status = ? AND amount >= ? AND created_at >= ? AND id > ? ORDER BY id ASC LIMIT ?`)).
+			WithArgs(int64(4), int64(123), "approved", 10.0, from.Local(), int64(7), 20).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(8, 4, 123, "approved", "Cash", from, from, "", 15.0))
+
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.Search(context.Background(), payment.Search{
+			MerchantId:  4,
+			OrderId:     123,
+			Status:      "approved",
+			MinAmount:   10,
+			CreatedFrom: from,
+			After:       &payment.Cursor{Id: 7},
+			Limit:       20,
+		})
+
+		assert.NoError(t, err)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, int64(8), result[0].Id())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should page on the sort column with the id breaking ties", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM payments WHERE merchant_id = $1 AND (amount < $2 OR (amount = $3 AND id < $4)) ORDER BY amount DESC, id DESC LIMIT $5`)).
+			WithArgs(int64(4), 50.0, 50.0, int64(7), 2).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		paymentDao := dao.NewPaymentDao(dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.Postgres), dbclient.Postgres)
+		result, err := paymentDao.Search(context.Background(), payment.Search{
+			MerchantId: 4,
+			Sort:       payment.SortAmount,
+			Descending: true,
+			After:      &payment.Cursor{Id: 7, Amount: 50},
+			Limit:      2,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`FROM payments`).WillReturnError(assert.AnError)
+
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.Search(context.Background(), payment.Search{Limit: 20})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
 func TestPaymentDao_Update(t *testing.T) {
-	t.Run("should update payment successfully", func(t *testing.T) {
+	t.Run("should update payment and append its transitions to the history", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		paymentEntity := payment.NewPayment(123, 100.5, "credit_card")
 		paymentEntity.SetId(1)
-		paymentEntity.SetStatus("approved")
+		assert.NoError(t, paymentEntity.Process("Failure", "insufficient funds"))
+		ctx := logging.WithActor(logging.WithRequestId(context.Background(), "req-1"), "api_key:abc123")
 
 		mock.ExpectExec(`UPDATE payments`).
 			WithArgs(
 				paymentEntity.Status(),
+				paymentEntity.Details(),
 				sqlmock.AnyArg(), // updated_at
 				paymentEntity.Id(),
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO payment_status_history\s+\(payment_id, from_status, status, reason, actor, request_id, created_at\)`).
+			WithArgs(paymentEntity.Id(), "pending", "reproved", "insufficient funds", "api_key:abc123", "req-1", paymentEntity.UpdatedAt()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.Update(paymentEntity)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.Update(ctx, paymentEntity)
 
 		assert.NoError(t, err)
 		if assert.NotNil(t, result) {
 			assert.Equal(t, paymentEntity.Id(), result.Id())
 			assert.Equal(t, paymentEntity.Status(), result.Status())
+			assert.Empty(t, result.Transitions())
 		}
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -313,11 +424,179 @@ func TestPaymentDao_Update(t *testing.T) {
 		mock.ExpectExec(`UPDATE payments`).
 			WillReturnError(assert.AnError)
 
-		paymentDao := dao.NewPaymentDao(db)
-		result, err := paymentDao.Update(paymentEntity)
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.Update(context.Background(), paymentEntity)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("should return error when the history cannot be written", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		paymentEntity := payment.NewPayment(123, 100.5, "credit_card")
+		paymentEntity.SetId(1)
+		assert.NoError(t, paymentEntity.Process("Success", ""))
+
+		mock.ExpectExec(`UPDATE payments`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO payment_status_history`).WillReturnError(assert.AnError)
+
+		paymentDao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		result, err := paymentDao.Update(context.Background(), paymentEntity)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPaymentDao_FindByOrderIdAsOf(t *testing.T) {
+	t.Run("should read the status of each payment from its history", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		asOf := time.Date(2024, 1, 31, 23, 59, 59, 0, time.Local)
+		createdAt := asOf.Add(-48 * time.Hour)
+		approvedAt := asOf.Add(-24 * time.Hour)
+		mock.ExpectQuery(`SELECT p.id, COALESCE\(p.merchant_id, 0\), p.order_id, COALESCE\(h.status, \?\).+LEFT JOIN payment_status_history h.+WHERE p.order_id = \? AND p.created_at <= \?`).
+			WithArgs("pending", asOf, int64(123), asOf).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "merchant_id", "order_id", "status", "payment_type", "amount", "created_at", "changed_at"}).
+				AddRow(1, 4, 123, "approved", "CreditCard", "100.50", createdAt, approvedAt).
+				AddRow(2, 4, 123, "pending", "CashSlip", "20", createdAt, nil))
+
+		payments, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL).FindByOrderIdAsOf(context.Background(), 123, asOf)
+
+		assert.NoError(t, err)
+		if assert.Len(t, payments, 2) {
+			assert.Equal(t, "approved", payments[0].Status())
+			assert.Equal(t, 100.5, payments[0].Amount())
+			assert.Equal(t, approvedAt, payments[0].UpdatedAt())
+			assert.Equal(t, "pending", payments[1].Status())
+			assert.Equal(t, createdAt, payments[1].UpdatedAt())
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT p.id`).WillReturnError(assert.AnError)
+
+		payments, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL).FindByOrderIdAsOf(context.Background(), 123, time.Now())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, payments)
+	})
+}
+
+func TestPaymentDao_SumApprovedByOrder(t *testing.T) {
+	t.Run("should sum the approved payments in the database", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM payments WHERE order_id = \? AND status = \?`).
+			WithArgs(int64(123), "approved").
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("150.50"))
+
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		sum, err := dao.SumApprovedByOrder(context.Background(), 123)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 150.5, sum)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM payments`).WillReturnError(assert.AnError)
+
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		sum, err := dao.SumApprovedByOrder(context.Background(), 123)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0.0, sum)
+	})
+}
+
+func TestPaymentDao_SummarizeByOrder(t *testing.T) {
+	t.Run("should group the payments of the order by status and type", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT status, payment_type, COUNT\(\*\), SUM\(amount\) FROM payments WHERE order_id = \? GROUP BY status, payment_type`).
+			WithArgs(int64(123)).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "payment_type", "count", "sum"}).
+				AddRow("approved", "CreditCard", 2, "150.50").
+				AddRow("reproved", "CashSlip", 1, "30"))
+
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		summaries, err := dao.SummarizeByOrder(context.Background(), 123)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []payment.Summary{
+			{Status: "approved", Type: "CreditCard", Count: 2, Amount: 150.5},
+			{Status: "reproved", Type: "CashSlip", Count: 1, Amount: 30},
+		}, summaries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT status, payment_type`).WillReturnError(assert.AnError)
+
+		dao := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL)
+		summaries, err := dao.SummarizeByOrder(context.Background(), 123)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, summaries)
+	})
+}
+
+func TestPaymentDao_FindTransitions(t *testing.T) {
+	t.Run("should list the history of the payment in order", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(`SELECT id, payment_id, from_status, status, reason, actor, request_id, created_at FROM payment_status_history WHERE payment_id = \? ORDER BY id`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "payment_id", "from_status", "status", "reason", "actor", "request_id", "created_at"}).
+				AddRow(5, 1, "pending", "approved", "", "api_key:abc123", "req-1", now))
+
+		transitions, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL).FindTransitions(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []payment.Transition{{
+			Id: 5, PaymentId: 1, From: "pending", To: "approved", Actor: "api_key:abc123", RequestId: "req-1", CreatedAt: now,
+		}}, transitions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, payment_id, from_status`).WillReturnError(assert.AnError)
+
+		transitions, err := dao.NewPaymentDao(dbclient.NewTxClient(db), dbclient.MySQL).FindTransitions(context.Background(), 1)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, transitions)
+	})
 }
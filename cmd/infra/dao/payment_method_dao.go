@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
+)
+
+type PaymentMethodDao struct {
+	db db.Client
+}
+
+func NewPaymentMethodDao(db db.Client) *PaymentMethodDao {
+	return &PaymentMethodDao{db: db}
+}
+
+func (p *PaymentMethodDao) FindSettingsByMerchantId(ctx context.Context, merchantId int64) (map[string]bool, error) {
+	ctx = metrics.WithOperation(ctx, "PaymentMethodDao", "FindSettingsByMerchantId")
+	query := `SELECT code, enabled FROM merchant_payment_methods WHERE merchant_id = ?`
+
+	row, err := p.db.QueryContext(ctx, query, merchantId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	settings := map[string]bool{}
+	for row.Next() {
+		var code string
+		var enabled bool
+		err := row.Scan(&code, &enabled)
+		if err != nil {
+			return nil, err
+		}
+
+		settings[code] = enabled
+	}
+
+	return settings, row.Err()
+}
@@ -0,0 +1,49 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+)
+
+func TestPaymentMethodDao_FindSettingsByMerchantId(t *testing.T) {
+	t.Run("should return merchant overrides by code", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"code", "enabled"}).
+			AddRow("CreditCard", true).
+			AddRow("CashSlip", false)
+
+		mock.ExpectQuery(`SELECT code, enabled FROM merchant_payment_methods WHERE merchant_id = \?`).
+			WithArgs(int64(1)).
+			WillReturnRows(rows)
+
+		dao := dao.NewPaymentMethodDao(dbclient.NewTxClient(db))
+		result, err := dao.FindSettingsByMerchantId(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"CreditCard": true, "CashSlip": false}, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT code, enabled FROM merchant_payment_methods`).WillReturnError(assert.AnError)
+
+		dao := dao.NewPaymentMethodDao(dbclient.NewTxClient(db))
+		result, err := dao.FindSettingsByMerchantId(context.Background(), 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,8 +1,21 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows is the subset of *sql.Rows the DAOs use, so clients can wrap the
+// rows they return and act when they are closed.
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Columns() ([]string, error)
+	Err() error
+	Close() error
+}
 
 type Client interface {
-	Exec(query string, args ...any) (sql.Result, error)
-	Query(query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
 }
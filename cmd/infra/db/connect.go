@@ -0,0 +1,51 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type ConnectOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	RetryAttempts   int
+	RetryInterval   time.Duration
+}
+
+// Connect opens a pool with the given limits and pings it until the server
+// answers or the retry attempts run out.
+func Connect(driver string, dsn string, options ConnectOptions, logger *slog.Logger) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(options.MaxOpenConns)
+	db.SetMaxIdleConns(options.MaxIdleConns)
+	db.SetConnMaxLifetime(options.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(options.ConnMaxIdleTime)
+
+	for attempt := 1; ; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= options.RetryAttempts {
+			break
+		}
+
+		logger.Warn("failed to reach database, retrying",
+			slog.String("driver", driver),
+			slog.Int("attempt", attempt),
+			slog.Duration("interval", options.RetryInterval),
+			slog.Any("error", err),
+		)
+		time.Sleep(options.RetryInterval)
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("database unreachable after %d attempts: %w", options.RetryAttempts, err)
+}
@@ -0,0 +1,398 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Dialect covers the SQL that differs between the supported engines. DAOs
+// write queries with ? placeholders, which Rebind turns into the engine's
+// own syntax.
+type Dialect interface {
+	Name() string
+	Rebind(query string) string
+	// Insert runs an INSERT and returns the id generated for the new row.
+	Insert(ctx context.Context, client Client, query string, args ...any) (int64, error)
+	// InsertIgnore turns an INSERT into one that skips rows conflicting with
+	// a unique key instead of failing.
+	InsertIgnore(query string) string
+	// ForUpdate makes a SELECT run inside a transaction lock the rows it
+	// reads until the transaction ends.
+	ForUpdate(query string) string
+	// Lock takes the named advisory lock for the session of conn, waiting up
+	// to timeout. It reports false when the lock is held elsewhere.
+	Lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (bool, error)
+	Unlock(ctx context.Context, conn *sql.Conn, name string) error
+	// Atomic runs fn, which issues its statements on conn, so that they all
+	// apply or none does. Engines whose DDL commits implicitly just run fn.
+	Atomic(ctx context.Context, conn *sql.Conn, fn func() error) error
+	// ReplicaLag reports how far behind the primary the replica reached
+	// through client is. It fails when client is not a running replica.
+	ReplicaLag(ctx context.Context, client Client) (time.Duration, error)
+}
+
+var ErrNotReplicating = errors.New("database is not replicating")
+
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case DriverMySQL:
+		return MySQL, nil
+	case DriverPostgres:
+		return Postgres, nil
+	case DriverSQLite:
+		return SQLite, nil
+	}
+
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+var (
+	MySQL    Dialect = mysqlDialect{}
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return DriverMySQL
+}
+
+func (mysqlDialect) Rebind(query string) string {
+	return query
+}
+
+func (mysqlDialect) Insert(ctx context.Context, client Client, query string, args ...any) (int64, error) {
+	res, err := client.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (mysqlDialect) InsertIgnore(query string) string {
+	return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+}
+
+func (mysqlDialect) ForUpdate(query string) string {
+	return query + " FOR UPDATE"
+}
+
+func (mysqlDialect) Lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (bool, error) {
+	var acquired sql.NullInt64
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&acquired); err != nil {
+		return false, err
+	}
+
+	return acquired.Int64 == 1, nil
+}
+
+func (mysqlDialect) Unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", name)
+	return err
+}
+
+// Atomic just runs fn: MySQL commits the open transaction before every DDL
+// statement, so it cannot be rolled back.
+func (mysqlDialect) Atomic(_ context.Context, _ *sql.Conn, fn func() error) error {
+	return fn()
+}
+
+// ReplicaLag reads Seconds_Behind_Source from SHOW REPLICA STATUS, or
+// Seconds_Behind_Master on servers older than 8.0.22, which name the
+// statement SHOW SLAVE STATUS.
+func (mysqlDialect) ReplicaLag(ctx context.Context, client Client) (time.Duration, error) {
+	rows, err := client.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = client.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, ErrNotReplicating
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, ErrNotReplicating
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, ErrNotReplicating
+}
+
+type postgresDialect struct{}
+
+// lockPollInterval is how often Lock retries, since Postgres has no timed
+// wait for advisory locks.
+const lockPollInterval = 250 * time.Millisecond
+
+func (postgresDialect) Name() string {
+	return DriverPostgres
+}
+
+// Rebind numbers the ? placeholders as $1, $2, ..., leaving quoted text
+// untouched.
+func (postgresDialect) Rebind(query string) string {
+	var out strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			out.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		out.WriteRune(r)
+	}
+
+	return out.String()
+}
+
+func (postgresDialect) Insert(ctx context.Context, client Client, query string, args ...any) (int64, error) {
+	rows, err := client.QueryContext(ctx, query+" RETURNING id", args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, sql.ErrNoRows
+	}
+
+	var id int64
+	if err := rows.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, rows.Err()
+}
+
+func (postgresDialect) InsertIgnore(query string) string {
+	return query + " ON CONFLICT DO NOTHING"
+}
+
+func (postgresDialect) ForUpdate(query string) string {
+	return query + " FOR UPDATE"
+}
+
+func (postgresDialect) Lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired); err != nil {
+			return false, err
+		}
+		if acquired || !time.Now().Before(deadline) {
+			return acquired, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (postgresDialect) Unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name)
+	return err
+}
+
+func (postgresDialect) Atomic(ctx context.Context, conn *sql.Conn, fn func() error) error {
+	if _, err := conn.ExecContext(ctx, "BEGIN"); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		_, rollbackErr := conn.ExecContext(context.Background(), "ROLLBACK")
+		return errors.Join(err, rollbackErr)
+	}
+
+	_, err := conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+// ReplicaLag is the age of the last replayed transaction, or zero once the
+// replica has replayed everything it received, so an idle primary does not
+// look like lag.
+func (postgresDialect) ReplicaLag(ctx context.Context, client Client) (time.Duration, error) {
+	query := `SELECT pg_is_in_recovery(),
+		CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END::float8`
+
+	rows, err := client.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, sql.ErrNoRows
+	}
+
+	var recovering bool
+	var seconds float64
+	if err := rows.Scan(&recovering, &seconds); err != nil {
+		return 0, err
+	}
+	if !recovering {
+		return 0, ErrNotReplicating
+	}
+
+	return time.Duration(seconds * float64(time.Second)), rows.Err()
+}
+
+type sqliteDialect struct{}
+
+// sqliteBusy is the result code SQLite returns when another connection holds
+// the write lock.
+const sqliteBusy = 5
+
+func (sqliteDialect) Name() string {
+	return DriverSQLite
+}
+
+func (sqliteDialect) Rebind(query string) string {
+	return query
+}
+
+func (sqliteDialect) Insert(ctx context.Context, client Client, query string, args ...any) (int64, error) {
+	return MySQL.Insert(ctx, client, query, args...)
+}
+
+func (sqliteDialect) InsertIgnore(query string) string {
+	return strings.Replace(query, "INSERT INTO", "INSERT OR IGNORE INTO", 1)
+}
+
+// ForUpdate leaves the query alone: SQLite has no row locks, and transactions
+// are opened with BEGIN IMMEDIATE, so they already hold the database write
+// lock.
+func (sqliteDialect) ForUpdate(query string) string {
+	return query
+}
+
+// Lock has no advisory lock to take, so it opens a write transaction on conn
+// instead, which keeps every other connection from writing until Unlock
+// commits it. The connection's busy timeout is swapped for timeout while it
+// waits for the write lock.
+func (sqliteDialect) Lock(ctx context.Context, conn *sql.Conn, _ string, timeout time.Duration) (acquired bool, err error) {
+	var busyTimeout int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+		return false, err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", timeout.Milliseconds())); err != nil {
+		return false, err
+	}
+	defer func() {
+		_, restoreErr := conn.ExecContext(context.Background(), fmt.Sprintf("PRAGMA busy_timeout = %d", busyTimeout))
+		err = errors.Join(err, restoreErr)
+	}()
+
+	_, err = conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) && coded.Code()&0xff == sqliteBusy {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (sqliteDialect) Unlock(ctx context.Context, conn *sql.Conn, _ string) error {
+	_, err := conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+// Atomic uses a savepoint, since Lock already keeps a transaction open on
+// conn. A failed fn is rolled back to it and the work before it survives.
+func (sqliteDialect) Atomic(ctx context.Context, conn *sql.Conn, fn func() error) error {
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT atomic"); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		_, rollbackErr := conn.ExecContext(context.Background(), "ROLLBACK TO atomic")
+		_, releaseErr := conn.ExecContext(context.Background(), "RELEASE atomic")
+		return errors.Join(err, rollbackErr, releaseErr)
+	}
+
+	_, err := conn.ExecContext(ctx, "RELEASE atomic")
+	return err
+}
+
+func (sqliteDialect) ReplicaLag(context.Context, Client) (time.Duration, error) {
+	return 0, errors.New("sqlite does not support read replicas")
+}
+
+// ReboundClient rewrites the placeholders of every statement for the dialect
+// before running it.
+type ReboundClient struct {
+	client  Client
+	dialect Dialect
+}
+
+func NewReboundClient(client Client, dialect Dialect) *ReboundClient {
+	return &ReboundClient{client: client, dialect: dialect}
+}
+
+func (c *ReboundClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.client.ExecContext(ctx, c.dialect.Rebind(query), args...)
+}
+
+func (c *ReboundClient) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return c.client.QueryContext(ctx, c.dialect.Rebind(query), args...)
+}
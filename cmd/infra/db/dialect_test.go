@@ -0,0 +1,262 @@
+package db_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/db"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectFor(t *testing.T) {
+	t.Run("should resolve the supported drivers", func(t *testing.T) {
+		mysql, err := db.DialectFor("mysql")
+		assert.NoError(t, err)
+		assert.Equal(t, "mysql", mysql.Name())
+
+		postgres, err := db.DialectFor("postgres")
+		assert.NoError(t, err)
+		assert.Equal(t, "postgres", postgres.Name())
+
+		sqlite, err := db.DialectFor("sqlite")
+		assert.NoError(t, err)
+		assert.Equal(t, "sqlite", sqlite.Name())
+
+		_, err = db.DialectFor("oracle")
+		assert.EqualError(t, err, `unsupported database driver "oracle"`)
+	})
+}
+
+func TestRebind(t *testing.T) {
+	t.Run("should keep question marks on mysql", func(t *testing.T) {
+		assert.Equal(t, "SELECT * FROM orders WHERE id = ?", db.MySQL.Rebind("SELECT * FROM orders WHERE id = ?"))
+	})
+
+	t.Run("should number placeholders on postgres outside quoted text", func(t *testing.T) {
+		query := db.Postgres.Rebind(`UPDATE payments SET details = 'why?', status = ? WHERE id = ? AND "col?" = ?`)
+
+		assert.Equal(t, `UPDATE payments SET details = 'why?', status = $1 WHERE id = $2 AND "col?" = $3`, query)
+	})
+}
+
+func TestInsertIgnore(t *testing.T) {
+	query := "INSERT INTO api_key_nonces (api_key_id, nonce) VALUES (?, ?)"
+
+	assert.Equal(t, "INSERT IGNORE INTO api_key_nonces (api_key_id, nonce) VALUES (?, ?)", db.MySQL.InsertIgnore(query))
+	assert.Equal(t, query+" ON CONFLICT DO NOTHING", db.Postgres.InsertIgnore(query))
+	assert.Equal(t, "INSERT OR IGNORE INTO api_key_nonces (api_key_id, nonce) VALUES (?, ?)", db.SQLite.InsertIgnore(query))
+}
+
+func TestForUpdate(t *testing.T) {
+	query := "SELECT tokens FROM rate_limit_buckets WHERE bucket_key = ?"
+
+	assert.Equal(t, query+" FOR UPDATE", db.MySQL.ForUpdate(query))
+	assert.Equal(t, query+" FOR UPDATE", db.Postgres.ForUpdate(query))
+	assert.Equal(t, query, db.SQLite.ForUpdate(query))
+}
+
+func TestInsert(t *testing.T) {
+	t.Run("should read the last insert id on mysql", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO orders (amount) VALUES (?)")).WithArgs(10.0).WillReturnResult(sqlmock.NewResult(7, 1))
+
+		id, err := db.MySQL.Insert(context.Background(), db.NewTxClient(conn), "INSERT INTO orders (amount) VALUES (?)", 10.0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+	})
+
+	t.Run("should return the id on postgres", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO orders (amount) VALUES ($1) RETURNING id")).
+			WithArgs(10.0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+		client := db.NewReboundClient(db.NewTxClient(conn), db.Postgres)
+		id, err := db.Postgres.Insert(context.Background(), client, "INSERT INTO orders (amount) VALUES (?)", 10.0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLock(t *testing.T) {
+	t.Run("should wait with GET_LOCK on mysql", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WithArgs("name", int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+		conn, _ := pool.Conn(context.Background())
+
+		acquired, err := db.MySQL.Lock(context.Background(), conn, "name", 1500*time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("should poll pg_try_advisory_lock until the timeout on postgres", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		query := regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")
+		mock.ExpectQuery(query).WithArgs("name").WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(false))
+		mock.ExpectQuery(query).WithArgs("name").WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+		conn, _ := pool.Conn(context.Background())
+
+		acquired, err := db.Postgres.Lock(context.Background(), conn, "name", time.Second)
+
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should give up on postgres once the timeout expires", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		mock.ExpectQuery("pg_try_advisory_lock").WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(false))
+		conn, _ := pool.Conn(context.Background())
+
+		acquired, err := db.Postgres.Lock(context.Background(), conn, "name", 0)
+
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+	})
+}
+
+func TestAtomic(t *testing.T) {
+	t.Run("should commit the statements on postgres", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE TABLE orders").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("COMMIT").WillReturnResult(sqlmock.NewResult(0, 0))
+		conn, _ := pool.Conn(context.Background())
+
+		err := db.Postgres.Atomic(context.Background(), conn, func() error {
+			_, err := conn.ExecContext(context.Background(), "CREATE TABLE orders (id BIGINT)")
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back the statements on postgres when fn fails", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+		conn, _ := pool.Conn(context.Background())
+
+		err := db.Postgres.Atomic(context.Background(), conn, func() error { return assert.AnError })
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back to a savepoint on sqlite when fn fails", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		mock.ExpectExec("SAVEPOINT atomic").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ROLLBACK TO atomic").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("RELEASE atomic").WillReturnResult(sqlmock.NewResult(0, 0))
+		conn, _ := pool.Conn(context.Background())
+
+		err := db.SQLite.Atomic(context.Background(), conn, func() error { return assert.AnError })
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should only run fn on mysql", func(t *testing.T) {
+		pool, mock, _ := sqlmock.New()
+		defer pool.Close()
+		conn, _ := pool.Conn(context.Background())
+
+		err := db.MySQL.Atomic(context.Background(), conn, func() error { return nil })
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReplicaLag(t *testing.T) {
+	t.Run("should read Seconds_Behind_Source on mysql", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Replica_IO_State", "Seconds_Behind_Source"}).AddRow("Waiting for source", "3"))
+
+		lag, err := db.MySQL.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3*time.Second, lag)
+	})
+
+	t.Run("should fall back to SHOW SLAVE STATUS on older mysql", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(assert.AnError)
+		mock.ExpectQuery("SHOW SLAVE STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Seconds_Behind_Master"}).AddRow("0"))
+
+		lag, err := db.MySQL.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), lag)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should fail on mysql when replication is not running", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows([]string{"Seconds_Behind_Source"}).AddRow(nil))
+
+		_, err := db.MySQL.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.ErrorIs(t, err, db.ErrNotReplicating)
+	})
+
+	t.Run("should fail on a mysql server that is not a replica", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(sqlmock.NewRows([]string{"Seconds_Behind_Source"}))
+
+		_, err := db.MySQL.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.ErrorIs(t, err, db.ErrNotReplicating)
+	})
+
+	t.Run("should read the replay delay on postgres", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("pg_is_in_recovery").WillReturnRows(sqlmock.NewRows([]string{"recovering", "lag"}).AddRow(true, 1.5))
+
+		lag, err := db.Postgres.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1500*time.Millisecond, lag)
+	})
+
+	t.Run("should fail on a postgres server that is not in recovery", func(t *testing.T) {
+		conn, mock, _ := sqlmock.New()
+		defer conn.Close()
+		mock.ExpectQuery("pg_is_in_recovery").WillReturnRows(sqlmock.NewRows([]string{"recovering", "lag"}).AddRow(false, 0.0))
+
+		_, err := db.Postgres.ReplicaLag(context.Background(), db.NewTxClient(conn))
+
+		assert.ErrorIs(t, err, db.ErrNotReplicating)
+	})
+
+	t.Run("should not support replicas on sqlite", func(t *testing.T) {
+		_, err := db.SQLite.ReplicaLag(context.Background(), nil)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LoggedClient logs every statement with its duration. Arguments are never
+// logged so bound values such as card data cannot leak.
+type LoggedClient struct {
+	client Client
+	logger *slog.Logger
+}
+
+func NewLoggedClient(client Client, logger *slog.Logger) *LoggedClient {
+	return &LoggedClient{client: client, logger: logger}
+}
+
+func (c *LoggedClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := c.client.ExecContext(ctx, query, args...)
+	c.log(ctx, query, start, err)
+
+	return result, err
+}
+
+func (c *LoggedClient) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	start := time.Now()
+	rows, err := c.client.QueryContext(ctx, query, args...)
+	c.log(ctx, query, start, err)
+
+	return rows, err
+}
+
+func (c *LoggedClient) log(ctx context.Context, query string, start time.Time, err error) {
+	attrs := []any{
+		slog.String("query", strings.Join(strings.Fields(query), " ")),
+		slog.Duration("duration", time.Since(start)),
+	}
+
+	if err != nil {
+		c.logger.WarnContext(ctx, "query failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+
+	c.logger.DebugContext(ctx, "query executed", attrs...)
+}
@@ -3,38 +3,16 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
-	"payment-gateway/cmd/infra"
-	"time"
+	"log/slog"
+	"payment-gateway/cmd/infra/config"
+	dbclient "payment-gateway/cmd/infra/db"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
-func NewMySQLClient(cfg *infra.Configuration) (*sql.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		cfg.DbUser, cfg.DbPassword, cfg.DbHost, cfg.DbPort, cfg.DbName)
+func NewMySQLClient(cfg config.Database, logger *slog.Logger) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
 
-	var db *sql.DB
-	var err error
-	for i := 0; i < 15; i++ {
-		db, err = sql.Open("mysql", dsn)
-
-		if err == nil {
-			if err := db.Ping(); err == nil {
-				break
-			}
-
-			db.Close()
-		}
-
-		fmt.Printf("failed to open database: %v\n", err)
-
-		fmt.Printf("waiting for 5 seconds before retrying...\n")
-		time.Sleep(5 * time.Second)
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	return db, nil
+	return dbclient.Connect("mysql", dsn, cfg.ConnectOptions(), logger)
 }
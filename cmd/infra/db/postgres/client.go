@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"database/sql"
+	"log/slog"
+	"net"
+	"net/url"
+	"payment-gateway/cmd/infra/config"
+	dbclient "payment-gateway/cmd/infra/db"
+	"strconv"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func NewPostgresClient(cfg config.Database, logger *slog.Logger) (*sql.DB, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.Name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+
+	return dbclient.Connect("pgx", dsn.String(), cfg.ConnectOptions(), logger)
+}
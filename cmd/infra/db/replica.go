@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Replica is a read-only copy of the primary, usable while its replication
+// lag stays within bounds.
+type Replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+	checked atomic.Bool
+}
+
+func NewReplica(name string, db *sql.DB) *Replica {
+	return &Replica{name: name, db: db}
+}
+
+func (r *Replica) Name() string {
+	return r.name
+}
+
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+func (r *Replica) Close() error {
+	return r.db.Close()
+}
+
+// ReplicaClient sends queries to the replicas in turn and statements that
+// write to the primary. Replicas start out unhealthy, and while none is
+// healthy the primary serves the reads too.
+type ReplicaClient struct {
+	primary  Client
+	replicas []*Replica
+	dialect  Dialect
+	maxLag   time.Duration
+	logger   *slog.Logger
+	next     atomic.Uint64
+}
+
+func NewReplicaClient(primary Client, replicas []*Replica, dialect Dialect, maxLag time.Duration, logger *slog.Logger) *ReplicaClient {
+	return &ReplicaClient{
+		primary:  primary,
+		replicas: replicas,
+		dialect:  dialect,
+		maxLag:   maxLag,
+		logger:   logger,
+	}
+}
+
+func (c *ReplicaClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+// QueryContext keeps queries inside a transaction on the primary, where the
+// transaction lives.
+func (c *ReplicaClient) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return c.primary.QueryContext(ctx, query, args...)
+	}
+	if replica := c.pick(); replica != nil {
+		return rowsOf(replica.db.QueryContext(ctx, query, args...))
+	}
+
+	return c.primary.QueryContext(ctx, query, args...)
+}
+
+func (c *ReplicaClient) pick() *Replica {
+	start := c.next.Add(1)
+	for i := range c.replicas {
+		replica := c.replicas[(start+uint64(i))%uint64(len(c.replicas))]
+		if replica.Healthy() {
+			return replica
+		}
+	}
+
+	return nil
+}
+
+// CheckLag measures every replica and takes out of rotation the ones that
+// lag behind more than maxLag or cannot report their lag.
+func (c *ReplicaClient) CheckLag(ctx context.Context) {
+	for _, replica := range c.replicas {
+		lag, err := c.dialect.ReplicaLag(ctx, NewTxClient(replica.db))
+		healthy := err == nil && lag <= c.maxLag
+		if replica.healthy.Swap(healthy) == healthy && replica.checked.Swap(true) {
+			continue
+		}
+
+		attrs := []any{slog.String("replica", replica.name), slog.Duration("lag", lag), slog.Duration("max_lag", c.maxLag)}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		if healthy {
+			c.logger.InfoContext(ctx, "replica in rotation", attrs...)
+		} else {
+			c.logger.WarnContext(ctx, "replica out of rotation, reading from the primary", attrs...)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package db_test
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/infra/db"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaClient(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	lagRows := func(seconds string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"Seconds_Behind_Source"}).AddRow(seconds)
+	}
+
+	t.Run("should read from the primary until a replica is checked", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		defer primary.Close()
+		replicaDB, replicaMock, _ := sqlmock.New()
+		defer replicaDB.Close()
+		primaryMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		client := db.NewReplicaClient(db.NewTxClient(primary), []*db.Replica{db.NewReplica("replica-1", replicaDB)}, db.MySQL, time.Second, logger)
+		rows, err := client.QueryContext(context.Background(), "SELECT 1")
+
+		assert.NoError(t, err)
+		rows.Close()
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+
+	t.Run("should send reads to healthy replicas in turn and writes to the primary", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		defer primary.Close()
+		first, firstMock, _ := sqlmock.New()
+		defer first.Close()
+		second, secondMock, _ := sqlmock.New()
+		defer second.Close()
+		firstMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("0"))
+		secondMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("1"))
+		firstMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+		secondMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+		primaryMock.ExpectExec("UPDATE orders").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		client := db.NewReplicaClient(db.NewTxClient(primary), []*db.Replica{db.NewReplica("replica-1", first), db.NewReplica("replica-2", second)}, db.MySQL, time.Second, logger)
+		client.CheckLag(context.Background())
+		for range 2 {
+			rows, err := client.QueryContext(context.Background(), "SELECT 1")
+			assert.NoError(t, err)
+			rows.Close()
+		}
+		_, err := client.ExecContext(context.Background(), "UPDATE orders SET status = 'paid'")
+
+		assert.NoError(t, err)
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, firstMock.ExpectationsWereMet())
+		assert.NoError(t, secondMock.ExpectationsWereMet())
+	})
+
+	t.Run("should fall back to the primary when the replica lags too far behind", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		defer primary.Close()
+		replicaDB, replicaMock, _ := sqlmock.New()
+		defer replicaDB.Close()
+		replica := db.NewReplica("replica-1", replicaDB)
+		replicaMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("0"))
+		replicaMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("30"))
+		primaryMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		client := db.NewReplicaClient(db.NewTxClient(primary), []*db.Replica{replica}, db.MySQL, 5*time.Second, logger)
+		client.CheckLag(context.Background())
+		assert.True(t, replica.Healthy())
+		client.CheckLag(context.Background())
+		assert.False(t, replica.Healthy())
+
+		rows, err := client.QueryContext(context.Background(), "SELECT 1")
+		assert.NoError(t, err)
+		rows.Close()
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+
+	t.Run("should take a replica out of rotation when its lag cannot be read", func(t *testing.T) {
+		primary, _, _ := sqlmock.New()
+		defer primary.Close()
+		replicaDB, replicaMock, _ := sqlmock.New()
+		defer replicaDB.Close()
+		replica := db.NewReplica("replica-1", replicaDB)
+		replicaMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("0"))
+		replicaMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(assert.AnError)
+		replicaMock.ExpectQuery("SHOW SLAVE STATUS").WillReturnError(assert.AnError)
+
+		client := db.NewReplicaClient(db.NewTxClient(primary), []*db.Replica{replica}, db.MySQL, time.Second, logger)
+		client.CheckLag(context.Background())
+		client.CheckLag(context.Background())
+
+		assert.False(t, replica.Healthy())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+
+	t.Run("should keep reads inside a transaction on the primary", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		defer primary.Close()
+		replicaDB, replicaMock, _ := sqlmock.New()
+		defer replicaDB.Close()
+		replicaMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(lagRows("0"))
+		primaryMock.ExpectBegin()
+		primaryMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+		primaryMock.ExpectCommit()
+
+		client := db.NewReplicaClient(db.NewTxClient(primary), []*db.Replica{db.NewReplica("replica-1", replicaDB)}, db.MySQL, time.Second, logger)
+		client.CheckLag(context.Background())
+		err := db.NewTransactor(primary).Transaction(context.Background(), func(ctx context.Context) error {
+			rows, err := client.QueryContext(ctx, "SELECT 1")
+			if err != nil {
+				return err
+			}
+			return rows.Close()
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+}
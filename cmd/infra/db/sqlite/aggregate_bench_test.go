@@ -0,0 +1,111 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const paymentsPerOrder = 10_000
+
+// seeded stores one order with paymentsPerOrder payments, every other one
+// approved with a charge, next to another order of the same size.
+func seeded(b *testing.B) (*sql.DB, int64) {
+	db := migrated(b)
+	tx, err := db.Begin()
+	require.NoError(b, err)
+	defer tx.Rollback()
+
+	now := time.Now()
+	var orderId int64
+	for range 2 {
+		res, err := tx.Exec("INSERT INTO orders (status, amount, created_at, updated_at) VALUES (?, ?, ?, ?)", "pending", 1e9, now, now)
+		require.NoError(b, err)
+		orderId, _ = res.LastInsertId()
+
+		for i := range paymentsPerOrder {
+			status := "reproved"
+			if i%2 == 0 {
+				status = "approved"
+			}
+			res, err := tx.Exec("INSERT INTO payments (order_id, status, payment_type, amount, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+				orderId, status, "CreditCard", 10.5, now, now)
+			require.NoError(b, err)
+			if status == "approved" {
+				paymentId, _ := res.LastInsertId()
+				_, err = tx.Exec("INSERT INTO charges (amount, category, payment_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+					1.05, "financial_fee", paymentId, now, now)
+				require.NoError(b, err)
+			}
+		}
+	}
+	require.NoError(b, tx.Commit())
+
+	return db, orderId
+}
+
+// BenchmarkPaidAmount compares loading every payment of the order to sum the
+// approved ones in Go with summing and summarizing them in SQL.
+func BenchmarkPaidAmount(b *testing.B) {
+	ctx := context.Background()
+	db, orderId := seeded(b)
+	paymentDao := dao.NewPaymentDao(dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.SQLite), dbclient.SQLite)
+
+	b.Run("FindByOrderId", func(b *testing.B) {
+		for b.Loop() {
+			payments, err := paymentDao.FindByOrderId(ctx, orderId)
+			require.NoError(b, err)
+			var sum float64
+			for _, pay := range payments {
+				if pay.IsValid() {
+					sum += pay.Amount()
+				}
+			}
+		}
+	})
+
+	b.Run("SumApprovedByOrder", func(b *testing.B) {
+		for b.Loop() {
+			_, err := paymentDao.SumApprovedByOrder(ctx, orderId)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("SummarizeByOrder", func(b *testing.B) {
+		for b.Loop() {
+			_, err := paymentDao.SummarizeByOrder(ctx, orderId)
+			require.NoError(b, err)
+		}
+	})
+}
+
+// BenchmarkCharges compares loading every charge of the order with summing
+// them by category in SQL.
+func BenchmarkCharges(b *testing.B) {
+	ctx := context.Background()
+	db, orderId := seeded(b)
+	chargeDao := dao.NewChargeDao(dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.SQLite), dbclient.SQLite)
+
+	b.Run("FindByOrderId", func(b *testing.B) {
+		for b.Loop() {
+			charges, err := chargeDao.FindByOrderId(ctx, orderId)
+			require.NoError(b, err)
+			sums := map[string]float64{}
+			for _, c := range charges {
+				sums[c.Category()] += c.Amount()
+			}
+		}
+	})
+
+	b.Run("SumChargesByOrder", func(b *testing.B) {
+		for b.Loop() {
+			_, err := chargeDao.SumChargesByOrder(ctx, orderId)
+			require.NoError(b, err)
+		}
+	})
+}
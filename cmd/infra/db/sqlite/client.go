@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"payment-gateway/cmd/infra/config"
+	dbclient "payment-gateway/cmd/infra/db"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// busyTimeout is how long a write waits for the connection holding the
+// database write lock before failing with SQLITE_BUSY.
+const busyTimeout = 5 * time.Second
+
+// NewSQLiteClient opens the database file in WAL mode, so readers never block
+// the writer. SQLite allows a single writer at a time: every transaction
+// starts with BEGIN IMMEDIATE to take the write lock up front, and writers
+// queue on the busy timeout instead of failing when the lock is held.
+func NewSQLiteClient(cfg config.Database, logger *slog.Logger) (*sql.DB, error) {
+	params := url.Values{
+		"_pragma": {
+			fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
+			"journal_mode(WAL)",
+			"synchronous(NORMAL)",
+			"foreign_keys(ON)",
+		},
+		"_txlock": {"immediate"},
+	}
+
+	return dbclient.Connect("sqlite", cfg.Path+"?"+params.Encode(), cfg.ConnectOptions(), logger)
+}
@@ -0,0 +1,248 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/config"
+	"payment-gateway/cmd/infra/dao"
+	"payment-gateway/cmd/infra/dao/daotest"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/db/sqlite"
+	"payment-gateway/cmd/infra/migrate"
+	"payment-gateway/cmd/infra/ratelimit"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t testing.TB) *sql.DB {
+	cfg := config.Defaults().Database
+	cfg.Driver = dbclient.DriverSQLite
+	cfg.Path = filepath.Join(t.TempDir(), "payment-gateway.db")
+
+	db, err := sqlite.NewSQLiteClient(cfg, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func migrated(t testing.TB) *sql.DB {
+	db := open(t)
+	migrations, err := migrate.Embedded(dbclient.DriverSQLite)
+	require.NoError(t, err)
+
+	migrator := migrate.New(db, dbclient.SQLite, migrations, migrate.Options{LockTimeout: time.Second}, slog.New(slog.DiscardHandler))
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, migrator.Check(context.Background()))
+
+	return db
+}
+
+func TestNewSQLiteClient(t *testing.T) {
+	t.Run("should open the file in WAL mode with foreign keys enforced", func(t *testing.T) {
+		db := open(t)
+
+		var journalMode string
+		var foreignKeys int
+		require.NoError(t, db.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+		require.NoError(t, db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys))
+
+		assert.Equal(t, "wal", journalMode)
+		assert.Equal(t, 1, foreignKeys)
+	})
+
+	t.Run("should store orders, payments and charges", func(t *testing.T) {
+		ctx := context.Background()
+		db := migrated(t)
+		client := dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.SQLite)
+		orderId, err := dbclient.SQLite.Insert(ctx, client,
+			"INSERT INTO orders (status, amount, created_at, updated_at) VALUES (?, ?, ?, ?)",
+			"pending", 100.0, time.Now(), time.Now())
+		require.NoError(t, err)
+
+		paymentDao := dao.NewPaymentDao(client, dbclient.SQLite)
+		created, err := paymentDao.Insert(ctx, payment.NewPayment(orderId, 100, "CreditCard"))
+		require.NoError(t, err)
+		require.NoError(t, created.Process("Success", ""))
+		_, err = paymentDao.Update(ctx, created)
+		require.NoError(t, err)
+
+		found, err := paymentDao.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, "approved", found.Status())
+		assert.False(t, found.CreatedAt().IsZero())
+
+		orderDao := dao.NewOrderDao(client, dbclient.SQLite)
+		pending, err := orderDao.FindById(ctx, orderId)
+		require.NoError(t, err)
+		pending.SetStatus("paid")
+		_, err = orderDao.Update(ctx, pending)
+		require.NoError(t, err)
+		paid, err := orderDao.FindById(ctx, orderId)
+		require.NoError(t, err)
+		assert.True(t, paid.IsPaid())
+
+		_, err = orderDao.FindById(ctx, 999999)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+
+		_, err = paymentDao.Insert(ctx, payment.NewPayment(999999, 10, "Cash"))
+		assert.Error(t, err, "foreign keys must be enforced")
+	})
+
+	t.Run("should backfill order balances from stored payments and charges", func(t *testing.T) {
+		ctx := context.Background()
+		db := migrated(t)
+		migrations, err := migrate.Embedded(dbclient.DriverSQLite)
+		require.NoError(t, err)
+		migrator := migrate.New(db, dbclient.SQLite, migrations, migrate.Options{LockTimeout: time.Second}, slog.New(slog.DiscardHandler))
+		steps := 0
+		for _, migration := range migrations {
+			if !migration.Dev && migration.Version >= 6 {
+				steps++
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), reverted[len(reverted)-1].Version)
+
+		_, err = db.Exec(`INSERT INTO orders (id, status, amount, created_at, updated_at) VALUES (1, 'pending', 300, ?, ?)`, time.Now(), time.Now())
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO payments (id, order_id, status, payment_type, amount, created_at, updated_at) VALUES
+			(1, 1, 'approved', 'CreditCard', 100, ?, ?), (2, 1, 'reproved', 'Cash', 50, ?, ?), (3, 1, 'approved', 'CashSlip', 25.5, ?, ?)`,
+			time.Now(), time.Now(), time.Now(), time.Now(), time.Now(), time.Now())
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO charges (payment_id, category, amount, created_at, updated_at) VALUES (1, 'financial_fee', 5, ?, ?)`, time.Now(), time.Now())
+		require.NoError(t, err)
+
+		_, err = migrator.Up(ctx)
+		require.NoError(t, err)
+
+		or, err := dao.NewOrderDao(dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.SQLite), dbclient.SQLite).FindById(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, order.Balance{Paid: 125.5, Charges: 5}, or.Balance())
+	})
+
+	t.Run("should serialize concurrent write transactions", func(t *testing.T) {
+		db := migrated(t)
+		store := ratelimit.NewSQLStore(db, dbclient.SQLite)
+		limit := ratelimit.Limit{Rate: 0.001, Burst: 10}
+		now := time.Now()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for range 30 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results, err := store.Take(context.Background(), []ratelimit.Bucket{{Key: "concurrent", Limit: limit}}, now)
+				assert.NoError(t, err)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if len(results) == 1 && results[0].Allowed {
+					allowed++
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 10, allowed)
+	})
+
+	t.Run("should let only one migrator hold the lock", func(t *testing.T) {
+		ctx := context.Background()
+		db := open(t)
+		holder, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer holder.Close()
+		other, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer other.Close()
+
+		acquired, err := dbclient.SQLite.Lock(ctx, holder, "migrations", time.Second)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = dbclient.SQLite.Lock(ctx, other, "migrations", 0)
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+
+		require.NoError(t, dbclient.SQLite.Unlock(ctx, holder, "migrations"))
+		acquired, err = dbclient.SQLite.Lock(ctx, other, "migrations", 0)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		assert.NoError(t, dbclient.SQLite.Unlock(ctx, other, "migrations"))
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	t.Run("should roll back only the failing migration", func(t *testing.T) {
+		ctx := context.Background()
+		db := open(t)
+		migrations, err := migrate.Load(fstest.MapFS{
+			"0001_create_orders.up.sql":     {Data: []byte("CREATE TABLE orders (id BIGINT);\n")},
+			"0001_create_orders.down.sql":   {Data: []byte("DROP TABLE orders;\n")},
+			"0002_create_payments.up.sql":   {Data: []byte("CREATE TABLE payments (id BIGINT);\n\nINSERT INTO missing (id) VALUES (1);\n")},
+			"0002_create_payments.down.sql": {Data: []byte("DROP TABLE payments;\n")},
+		})
+		require.NoError(t, err)
+
+		applied, err := migrate.New(db, dbclient.SQLite, migrations, migrate.Options{LockTimeout: time.Second}, slog.New(slog.DiscardHandler)).Up(ctx)
+
+		assert.ErrorContains(t, err, "migration 0002_create_payments")
+		assert.Len(t, applied, 1)
+		var tables []string
+		rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+		require.NoError(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			tables = append(tables, name)
+		}
+		assert.Equal(t, []string{"orders", "schema_migrations"}, tables)
+		var versions int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&versions))
+		assert.Equal(t, 1, versions)
+	})
+}
+
+func TestConformance(t *testing.T) {
+	db := migrated(t)
+	client := dbclient.NewReboundClient(dbclient.NewTxClient(db), dbclient.SQLite)
+
+	daotest.Run(t, func(t *testing.T) daotest.Fixture {
+		return daotest.Fixture{
+			Payments:    dao.NewPaymentDao(client, dbclient.SQLite),
+			Orders:      dao.NewOrderDao(client, dbclient.SQLite),
+			Charges:     dao.NewChargeDao(client, dbclient.SQLite),
+			Audit:       dao.NewAuditDao(client, dbclient.SQLite, daotest.AuditKey),
+			Transaction: dbclient.NewTransactor(db).Transaction,
+			NewMerchant: func(t *testing.T) int64 {
+				id, err := dbclient.SQLite.Insert(context.Background(), client, "INSERT INTO merchants (name) VALUES (?)", "merchant")
+				require.NoError(t, err)
+
+				return id
+			},
+			NewOrder: func(t *testing.T, merchantId int64, amount float64) int64 {
+				id, err := dbclient.SQLite.Insert(context.Background(), client,
+					"INSERT INTO orders (merchant_id, status, amount, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+					merchantId, "pending", amount, time.Now(), time.Now())
+				require.NoError(t, err)
+
+				return id
+			},
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Transactor runs functions inside a database transaction carried by the
+// context, so every DAO built on a TxClient joins it without knowing.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Transaction commits when fn returns nil and rolls back otherwise. Calls
+// nested inside fn join the outer transaction.
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TxClient runs statements in the transaction of the context, or on the pool
+// when there is none.
+type TxClient struct {
+	db *sql.DB
+}
+
+func NewTxClient(db *sql.DB) *TxClient {
+	return &TxClient{db: db}
+}
+
+func (c *TxClient) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx.ExecContext(ctx, query, args...)
+	}
+
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+func (c *TxClient) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return rowsOf(tx.QueryContext(ctx, query, args...))
+	}
+
+	return rowsOf(c.db.QueryContext(ctx, query, args...))
+}
+
+// rowsOf keeps a failed query from returning a nil *sql.Rows wrapped in a
+// non-nil Rows.
+func rowsOf(rows *sql.Rows, err error) (Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
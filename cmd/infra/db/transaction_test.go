@@ -0,0 +1,74 @@
+package db_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/db"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactor(t *testing.T) {
+	t.Run("should run every statement of the function in one committed transaction", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE payments").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE orders").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		client := db.NewTxClient(conn)
+		transactor := db.NewTransactor(conn)
+		err = transactor.Transaction(context.Background(), func(ctx context.Context) error {
+			if _, err := client.ExecContext(ctx, "UPDATE payments SET status = 'approved'"); err != nil {
+				return err
+			}
+
+			return transactor.Transaction(ctx, func(ctx context.Context) error {
+				_, err := client.ExecContext(ctx, "UPDATE orders SET status = 'paid'")
+				return err
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when the function fails", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE payments").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		client := db.NewTxClient(conn)
+		err = db.NewTransactor(conn).Transaction(context.Background(), func(ctx context.Context) error {
+			if _, err := client.ExecContext(ctx, "UPDATE payments SET status = 'approved'"); err != nil {
+				return err
+			}
+
+			return assert.AnError
+		})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should use the pool outside a transaction", func(t *testing.T) {
+		conn, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		rows, err := db.NewTxClient(conn).QueryContext(context.Background(), "SELECT 1")
+		assert.NoError(t, err)
+		rows.Close()
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
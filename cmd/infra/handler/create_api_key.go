@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateApiKeyUseCase interface {
+	Execute(ctx context.Context, merchantId int64, name string, scopes []string, mode string) (*apikey.Entity, string, error)
+}
+
+type CreateApiKeyHandler struct {
+	useCase CreateApiKeyUseCase
+}
+
+func NewCreateApiKeyHandler(useCase CreateApiKeyUseCase) *CreateApiKeyHandler {
+	return &CreateApiKeyHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *CreateApiKeyHandler) Execute(ctx *gin.Context) {
+	var request struct {
+		Name   string   `json:"name" binding:"required,max=100"`
+		Scopes []string `json:"scopes" binding:"required,min=1"`
+		Mode   string   `json:"mode" binding:"required"`
+	}
+
+	if err := validation.BindJSON(ctx, &request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	key, token, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), request.Name, request.Scopes, request.Mode)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := apiKeyResponse(*key)
+	response["key"] = token
+	response["signing_secret"] = key.SigningSecret()
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func apiKeyResponse(key apikey.Entity) gin.H {
+	response := gin.H{
+		"id":           key.Id(),
+		"name":         key.Name(),
+		"prefix":       key.Prefix(),
+		"scopes":       key.Scopes(),
+		"mode":         key.Mode(),
+		"revoked":      key.IsRevoked(),
+		"created_at":   key.CreatedAt(),
+		"last_used_at": nil,
+		"revoked_at":   nil,
+	}
+
+	if !key.LastUsedAt().IsZero() {
+		response["last_used_at"] = key.LastUsedAt()
+	}
+	if key.IsRevoked() {
+		response["revoked_at"] = key.RevokedAt()
+	}
+
+	return response
+}
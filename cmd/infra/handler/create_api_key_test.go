@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCreateApiKeyUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCreateApiKeyUseCase) Execute(_ context.Context, merchantId int64, name string, scopes []string, mode string) (*apikey.Entity, string, error) {
+	args := m.Called(merchantId, name, scopes, mode)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*apikey.Entity), args.String(1), args.Error(2)
+}
+
+func withMerchant(merchantId int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		middleware.SetApiKey(ctx, apikey.NewApiKeyBuilder().WithId(99).WithMerchantId(merchantId).WithScopes("admin").Build())
+		ctx.Next()
+	}
+}
+
+func setupCreateApiKeyTestRouter(h *handler.CreateApiKeyHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/api-keys", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestCreateApiKeyHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockCreateApiKeyUseCase)
+	h := handler.NewCreateApiKeyHandler(mockUC)
+	r := setupCreateApiKeyTestRouter(h)
+
+	key := apikey.NewApiKeyBuilder().WithId(1).WithName("backend").WithPrefix("abc").WithScopes("read").WithMode("live").WithSigningSecret("pgw_sig_abc").Build()
+	mockUC.On("Execute", int64(10), "backend", []string{"read"}, "live").Return(key, "pgw_live_abc", nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "backend",
+		"scopes": []string{"read"},
+		"mode":   "live",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, float64(1), resp["id"])
+	assert.Equal(t, "pgw_live_abc", resp["key"])
+	assert.Equal(t, "pgw_sig_abc", resp["signing_secret"])
+	assert.Equal(t, "abc", resp["prefix"])
+	assert.Equal(t, "live", resp["mode"])
+	assert.Nil(t, resp["last_used_at"])
+	assert.NotContains(t, resp, "hash")
+}
+
+func TestCreateApiKeyHandler_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewCreateApiKeyHandler(nil)
+	r := setupCreateApiKeyTestRouter(h)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString("{invalid json}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateApiKeyHandler_UseCaseError_400(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockCreateApiKeyUseCase)
+	h := handler.NewCreateApiKeyHandler(mockUC)
+	r := setupCreateApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), "backend", []string{"root"}, "live").Return(nil, "", exceptions.NewDomainError(exceptions.CodeInvalidScope, "Invalid api key scope"))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "backend",
+		"scopes": []string{"root"},
+		"mode":   "live",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreateApiKeyHandler_UseCaseError_500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockCreateApiKeyUseCase)
+	h := handler.NewCreateApiKeyHandler(mockUC)
+	r := setupCreateApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), "backend", []string{"read"}, "live").Return(nil, "", assert.AnError)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "backend",
+		"scopes": []string{"read"},
+		"mode":   "live",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -1,15 +1,16 @@
 package handler
 
 import (
-	"errors"
+	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	exceptions "payment-gateway/cmd/domain/err"
 	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/validation"
 )
 
 type UseCase interface {
-	Execute(orderId int64, amount float64, status string) (*payment.Entity, error)
+	Execute(ctx context.Context, merchantId int64, orderId int64, amount float64, status string) (*payment.Entity, error)
 }
 
 type CreatePaymentHandler struct {
@@ -24,26 +25,19 @@ func NewCreatePaymentHandler(useCase UseCase) *CreatePaymentHandler {
 
 func (c *CreatePaymentHandler) Execute(ctx *gin.Context) {
 	var request struct {
-		OrderID     int64   `json:"order_id"`
-		Amount      float64 `json:"amount"`
-		PaymentType string  `json:"payment_type"`
+		OrderID     int64   `json:"order_id" binding:"required,gt=0"`
+		Amount      float64 `json:"amount" binding:"required,gt=0,max_decimals=2"`
+		PaymentType string  `json:"payment_type" binding:"required,payment_type"`
 	}
 
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := validation.BindJSON(ctx, &request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	pay, err := c.UseCase.Execute(request.OrderID, request.Amount, request.PaymentType)
+	pay, err := c.UseCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), request.OrderID, request.Amount, request.PaymentType)
 	if err != nil {
-		var ex *exceptions.DomainError
-
-		if errors.As(err, &ex) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": ex.Error()})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,16 +12,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
 )
 
 type MockCreatePaymentUseCase struct {
 	mock.Mock
 }
 
-func (m *MockCreatePaymentUseCase) Execute(orderId int64, amount float64, status string) (*payment.Entity, error) {
-	args := m.Called(orderId, amount, status)
+func (m *MockCreatePaymentUseCase) Execute(_ context.Context, merchantId int64, orderId int64, amount float64, status string) (*payment.Entity, error) {
+	args := m.Called(merchantId, orderId, amount, status)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -29,7 +32,8 @@ func (m *MockCreatePaymentUseCase) Execute(orderId int64, amount float64, status
 
 func setupTestRouter(h *handler.CreatePaymentHandler) *gin.Engine {
 	r := gin.Default()
-	r.POST("/payments", h.Execute)
+	r.Use(middleware.ErrorHandler())
+	r.POST("/payments", withMerchant(10), h.Execute)
 	return r
 }
 
@@ -42,11 +46,11 @@ func TestCreatePaymentHandler_Success(t *testing.T) {
 
 	orderID := int64(123)
 	amount := 100.50
-	paymentType := "credit_card"
+	paymentType := "CreditCard"
 	expectedPayment := payment.NewPayment(orderID, amount, paymentType)
 	expectedPayment.SetId(1)
 
-	mockUC.On("Execute", orderID, amount, paymentType).Return(expectedPayment, nil)
+	mockUC.On("Execute", int64(10), orderID, amount, paymentType).Return(expectedPayment, nil)
 
 	reqBody := map[string]interface{}{
 		"order_id":     orderID,
@@ -96,8 +100,8 @@ func TestCreatePaymentHandler_UseCaseError_500(t *testing.T) {
 
 	orderID := int64(123)
 	amount := 100.50
-	paymentType := "credit_card"
-	mockUC.On("Execute", orderID, amount, paymentType).Return(nil, assert.AnError)
+	paymentType := "CreditCard"
+	mockUC.On("Execute", int64(10), orderID, amount, paymentType).Return(nil, assert.AnError)
 
 	reqBody := map[string]interface{}{
 		"order_id":     orderID,
@@ -115,7 +119,7 @@ func TestCreatePaymentHandler_UseCaseError_500(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
-func TestCreatePaymentHandler_UseCaseError_400(t *testing.T) {
+func TestCreatePaymentHandler_UseCaseError_422(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockUC := new(MockCreatePaymentUseCase)
@@ -124,8 +128,8 @@ func TestCreatePaymentHandler_UseCaseError_400(t *testing.T) {
 
 	orderID := int64(123)
 	amount := 100.50
-	paymentType := "credit_card"
-	mockUC.On("Execute", orderID, amount, paymentType).Return(nil, exceptions.NewDomainError("error creating payment"))
+	paymentType := "CreditCard"
+	mockUC.On("Execute", int64(10), orderID, amount, paymentType).Return(nil, exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, "error creating payment"))
 
 	reqBody := map[string]interface{}{
 		"order_id":     orderID,
@@ -139,6 +143,54 @@ func TestCreatePaymentHandler_UseCaseError_400(t *testing.T) {
 
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreatePaymentHandler_OrderNotFound_404(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockCreatePaymentUseCase)
+	h := handler.NewCreatePaymentHandler(mockUC)
+	r := setupTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(999999), 10.0, "Cash").Return(nil, order.ErrNotFound)
+
+	body := bytes.NewBufferString(`{"order_id": 999999, "amount": 10, "payment_type": "Cash"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/payments", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "order_not_found")
 	mockUC.AssertExpectations(t)
 }
+
+func TestCreatePaymentHandler_ValidationErrors_422(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockCreatePaymentUseCase)
+	h := handler.NewCreatePaymentHandler(mockUC)
+	r := setupTestRouter(h)
+
+	body := bytes.NewBufferString(`{"order_id": 0, "amount": 10.555, "payment_type": "Pix"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/payments", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp struct {
+		Code   string `json:"code"`
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "validation_failed", resp.Code)
+	assert.Len(t, resp.Errors, 3)
+	mockUC.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,17 +1,21 @@
 package handler
 
 import (
-	"errors"
+	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	exceptions "payment-gateway/cmd/domain/err"
 	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/validation"
 	"payment-gateway/cmd/usecases"
 	"strconv"
+	"time"
 )
 
 type GetCashoutUseCase interface {
-	Execute(orderId int64) (order.Entity, usecases.CashoutView, error)
+	Execute(ctx context.Context, merchantId int64, orderId int64) (order.Entity, usecases.CashoutView, error)
+	ExecuteAsOf(ctx context.Context, merchantId int64, orderId int64, asOf time.Time) (order.Entity, usecases.CashoutView, error)
 }
 
 type GetCashoutHandler struct {
@@ -24,23 +28,34 @@ func NewGetCashoutHandler(useCase GetCashoutUseCase) *GetCashoutHandler {
 	}
 }
 
+// Execute returns the current cashout, or the one at as_of when the query
+// string has an RFC 3339 timestamp.
 func (c *GetCashoutHandler) Execute(ctx *gin.Context) {
 	orderID := ctx.Param("id")
 	orderId64, err := strconv.ParseInt(orderID, 10, 64)
 
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, err.Error()))
 		return
 	}
-	or, view, err := c.UseCase.Execute(orderId64)
-	if err != nil {
-		var ex *exceptions.DomainError
 
-		if errors.As(err, &ex) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": ex.Error()})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var request struct {
+		AsOf time.Time `form:"as_of"`
+	}
+	if err := validation.BindQuery(ctx, &request); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var or order.Entity
+	var view usecases.CashoutView
+	if request.AsOf.IsZero() {
+		or, view, err = c.UseCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), orderId64)
+	} else {
+		or, view, err = c.UseCase.ExecuteAsOf(ctx.Request.Context(), middleware.MerchantId(ctx), orderId64, request.AsOf)
+	}
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
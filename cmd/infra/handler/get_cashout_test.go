@@ -1,26 +1,37 @@
 package handler_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/usecases"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
 )
 
 type MockGetCheckoutUseCase struct {
 	mock.Mock
 }
 
-func (m *MockGetCheckoutUseCase) Execute(orderId int64) (order.Entity, usecases.CashoutView, error) {
+func (m *MockGetCheckoutUseCase) Execute(_ context.Context, merchantId int64, orderId int64) (order.Entity, usecases.CashoutView, error) {
 
-	args := m.Called(orderId)
+	args := m.Called(merchantId, orderId)
+	if args.Get(0) == nil {
+		return order.Entity{}, usecases.CashoutView{}, args.Error(1)
+	}
+	return args.Get(0).(order.Entity), args.Get(1).(usecases.CashoutView), args.Error(2)
+}
+
+func (m *MockGetCheckoutUseCase) ExecuteAsOf(_ context.Context, merchantId int64, orderId int64, asOf time.Time) (order.Entity, usecases.CashoutView, error) {
+	args := m.Called(merchantId, orderId, asOf)
 	if args.Get(0) == nil {
 		return order.Entity{}, usecases.CashoutView{}, args.Error(1)
 	}
@@ -29,7 +40,8 @@ func (m *MockGetCheckoutUseCase) Execute(orderId int64) (order.Entity, usecases.
 
 func setupGetCashoutTestRouter(h *handler.GetCashoutHandler) *gin.Engine {
 	r := gin.Default()
-	r.GET("/orders/:id", h.Execute)
+	r.Use(middleware.ErrorHandler())
+	r.GET("/orders/:id", withMerchant(10), h.Execute)
 	return r
 }
 
@@ -43,14 +55,17 @@ func TestGetCashoutHandler_Success(t *testing.T) {
 	orderID := int64(123)
 	orderExpected := *order.NewOrderBuilder().WithId(1).WithStatus("approved").WithAmount(100).Build()
 	cashoutExpected := usecases.CashoutView{
-		OrderId:       orderID,
-		CashedDebt:    0,
-		RemainingDebt: 100,
-		Charges:       0,
-		IsPaid:        false,
+		OrderId:          orderID,
+		CashedDebt:       0,
+		RemainingDebt:    100,
+		Charges:          0,
+		IsPaid:           false,
+		NetAmount:        0,
+		PaidByType:       map[string]float64{},
+		PaymentsByStatus: map[string]int{"pending": 1},
 	}
 
-	mockUC.On("Execute", orderID).Return(orderExpected, cashoutExpected, nil)
+	mockUC.On("Execute", int64(10), orderID).Return(orderExpected, cashoutExpected, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/orders/123", nil)
 	w := httptest.NewRecorder()
@@ -71,4 +86,71 @@ func TestGetCashoutHandler_Success(t *testing.T) {
 	assert.Equal(t, cashoutExpected.Charges, cashoutView["charges"])
 	assert.Equal(t, cashoutExpected.RemainingDebt, cashoutView["remaining_debt"])
 	assert.Equal(t, cashoutExpected.CashedDebt, cashoutView["cashed_debt"])
+	assert.Equal(t, cashoutExpected.NetAmount, cashoutView["net_amount"])
+	assert.Equal(t, map[string]interface{}{}, cashoutView["paid_by_type"])
+	assert.Equal(t, map[string]interface{}{"pending": float64(1)}, cashoutView["payments_by_status"])
+}
+
+func TestGetCashoutHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetCheckoutUseCase)
+	h := handler.NewGetCashoutHandler(mockUC)
+	r := setupGetCashoutTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(999999)).Return(nil, order.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/999999", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "order_not_found", resp["code"])
+	assert.Equal(t, "/orders/999999", resp["instance"])
+}
+
+func TestGetCashoutHandler_AsOf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should return the cashout at the time given", func(t *testing.T) {
+		mockUC := new(MockGetCheckoutUseCase)
+		r := setupGetCashoutTestRouter(handler.NewGetCashoutHandler(mockUC))
+
+		asOf := time.Date(2024, 1, 31, 23, 59, 59, 0, time.FixedZone("", -3*60*60))
+		past := *order.NewOrderBuilder().WithId(123).WithStatus("pending").WithAmount(100).WithPaidAmount(40).Build()
+		mockUC.On("ExecuteAsOf", int64(10), int64(123), mock.MatchedBy(asOf.Equal)).
+			Return(past, usecases.CashoutView{OrderId: 123, CashedDebt: 40, RemainingDebt: 60}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/123?as_of=2024-01-31T23:59:59-03:00", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUC.AssertExpectations(t)
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "pending", resp["status"])
+		assert.Equal(t, float64(40), resp["cashout"].(map[string]interface{})["cashed_debt"])
+	})
+
+	t.Run("should reject a timestamp that is not RFC 3339", func(t *testing.T) {
+		mockUC := new(MockGetCheckoutUseCase)
+		r := setupGetCashoutTestRouter(handler.NewGetCashoutHandler(mockUC))
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/123?as_of=31/01/2024", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockUC.AssertNotCalled(t, "ExecuteAsOf", mock.Anything, mock.Anything)
+		mockUC.AssertNotCalled(t, "Execute", mock.Anything)
+	})
 }
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetOrderTimelineUseCase interface {
+	Execute(ctx context.Context, merchantId int64, orderId int64) ([]order.Transition, error)
+}
+
+type GetOrderTimelineHandler struct {
+	useCase GetOrderTimelineUseCase
+}
+
+func NewGetOrderTimelineHandler(useCase GetOrderTimelineUseCase) *GetOrderTimelineHandler {
+	return &GetOrderTimelineHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *GetOrderTimelineHandler) Execute(ctx *gin.Context) {
+	orderId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid order id"))
+		return
+	}
+
+	transitions, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), orderId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(transitions))
+	for _, t := range transitions {
+		response = append(response, transitionResponse(t.Id, t.From, t.To, t.Reason, t.Actor, t.RequestId, t.CreatedAt))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"timeline": response})
+}
@@ -0,0 +1,78 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGetOrderTimelineUseCase struct {
+	mock.Mock
+}
+
+func (m *MockGetOrderTimelineUseCase) Execute(_ context.Context, merchantId int64, orderId int64) ([]order.Transition, error) {
+	args := m.Called(merchantId, orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]order.Transition), args.Error(1)
+}
+
+func setupGetOrderTimelineTestRouter(h *handler.GetOrderTimelineHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/orders/:id/timeline", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestGetOrderTimelineHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetOrderTimelineUseCase)
+	r := setupGetOrderTimelineTestRouter(handler.NewGetOrderTimelineHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return([]order.Transition{
+		{Id: 1, OrderId: 3, From: "pending", To: "paid", Reason: "payment 5 approved", Actor: "api_key:abc123", RequestId: "req-1", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/timeline", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["timeline"], 1) {
+		assert.Equal(t, "pending", resp["timeline"][0]["from"])
+		assert.Equal(t, "paid", resp["timeline"][0]["to"])
+		assert.Equal(t, "payment 5 approved", resp["timeline"][0]["reason"])
+		assert.Equal(t, "api_key:abc123", resp["timeline"][0]["actor"])
+	}
+}
+
+func TestGetOrderTimelineHandler_OrderNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetOrderTimelineUseCase)
+	r := setupGetOrderTimelineTestRouter(handler.NewGetOrderTimelineHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return(nil, order.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/timeline", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetPaymentUseCase interface {
+	Execute(ctx context.Context, merchantId int64, paymentId int64) (*payment.Entity, error)
+}
+
+type GetPaymentHandler struct {
+	useCase GetPaymentUseCase
+}
+
+func NewGetPaymentHandler(useCase GetPaymentUseCase) *GetPaymentHandler {
+	return &GetPaymentHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *GetPaymentHandler) Execute(ctx *gin.Context) {
+	paymentId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid payment id"))
+		return
+	}
+
+	pay, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), paymentId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentResponse(*pay))
+}
+
+func paymentResponse(pay payment.Entity) gin.H {
+	return gin.H{
+		"id":         pay.Id(),
+		"order_id":   pay.OrderID(),
+		"status":     pay.Status(),
+		"type":       pay.Type(),
+		"amount":     pay.Amount(),
+		"details":    pay.Details(),
+		"created_at": pay.CreatedAt(),
+		"updated_at": pay.UpdatedAt(),
+	}
+}
+
+func paymentsResponse(payments []payment.Entity) []gin.H {
+	response := make([]gin.H, 0, len(payments))
+	for _, pay := range payments {
+		response = append(response, paymentResponse(pay))
+	}
+
+	return response
+}
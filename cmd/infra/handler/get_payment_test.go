@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGetPaymentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockGetPaymentUseCase) Execute(_ context.Context, merchantId int64, paymentId int64) (*payment.Entity, error) {
+	args := m.Called(merchantId, paymentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payment.Entity), args.Error(1)
+}
+
+func setupGetPaymentTestRouter(h *handler.GetPaymentHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/payments/:id", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestGetPaymentHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentUseCase)
+	r := setupGetPaymentTestRouter(handler.NewGetPaymentHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(7)).Return(payment.NewPaymentBuilder().
+		WithId(7).
+		WithOrderId(3).
+		WithAmount(45.5).
+		WithType("CreditCard").
+		WithStatus("approved").
+		WithDetails("card ending 4242").
+		Build(), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/7", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, float64(7), resp["id"])
+	assert.Equal(t, float64(3), resp["order_id"])
+	assert.Equal(t, 45.5, resp["amount"])
+	assert.Equal(t, "CreditCard", resp["type"])
+	assert.Equal(t, "approved", resp["status"])
+	assert.Equal(t, "card ending 4242", resp["details"])
+	assert.Contains(t, resp, "created_at")
+	assert.Contains(t, resp, "updated_at")
+}
+
+func TestGetPaymentHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentUseCase)
+	r := setupGetPaymentTestRouter(handler.NewGetPaymentHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(7)).Return(nil, payment.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/7", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "payment_not_found", resp["code"])
+}
+
+func TestGetPaymentHandler_InvalidId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentUseCase)
+	r := setupGetPaymentTestRouter(handler.NewGetPaymentHandler(mockUC))
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/abc", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "Execute", mock.Anything)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetPaymentTimelineUseCase interface {
+	Execute(ctx context.Context, merchantId int64, paymentId int64) ([]payment.Transition, error)
+}
+
+type GetPaymentTimelineHandler struct {
+	useCase GetPaymentTimelineUseCase
+}
+
+func NewGetPaymentTimelineHandler(useCase GetPaymentTimelineUseCase) *GetPaymentTimelineHandler {
+	return &GetPaymentTimelineHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *GetPaymentTimelineHandler) Execute(ctx *gin.Context) {
+	paymentId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid payment id"))
+		return
+	}
+
+	transitions, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), paymentId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(transitions))
+	for _, t := range transitions {
+		response = append(response, transitionResponse(t.Id, t.From, t.To, t.Reason, t.Actor, t.RequestId, t.CreatedAt))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"timeline": response})
+}
+
+// transitionResponse renders a status change. Changes recorded before their
+// author was tracked have no actor or request id, and null stands for them.
+func transitionResponse(id int64, from, to, reason, actor, requestId string, createdAt time.Time) gin.H {
+	return gin.H{
+		"id":         id,
+		"from":       from,
+		"to":         to,
+		"reason":     reason,
+		"actor":      nullable(actor),
+		"request_id": nullable(requestId),
+		"created_at": createdAt,
+	}
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGetPaymentTimelineUseCase struct {
+	mock.Mock
+}
+
+func (m *MockGetPaymentTimelineUseCase) Execute(_ context.Context, merchantId int64, paymentId int64) ([]payment.Transition, error) {
+	args := m.Called(merchantId, paymentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Transition), args.Error(1)
+}
+
+func setupGetPaymentTimelineTestRouter(h *handler.GetPaymentTimelineHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/payments/:id/timeline", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestGetPaymentTimelineHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentTimelineUseCase)
+	r := setupGetPaymentTimelineTestRouter(handler.NewGetPaymentTimelineHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(5)).Return([]payment.Transition{
+		{Id: 1, PaymentId: 5, From: "pending", To: "approved", Reason: "ok", Actor: "api_key:abc123", RequestId: "req-1", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: 2, PaymentId: 5, From: "approved", To: "refunded", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/5/timeline", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["timeline"], 2) {
+		assert.Equal(t, "pending", resp["timeline"][0]["from"])
+		assert.Equal(t, "approved", resp["timeline"][0]["to"])
+		assert.Equal(t, "ok", resp["timeline"][0]["reason"])
+		assert.Equal(t, "api_key:abc123", resp["timeline"][0]["actor"])
+		assert.Equal(t, "req-1", resp["timeline"][0]["request_id"])
+		assert.Equal(t, "2024-01-01T00:00:00Z", resp["timeline"][0]["created_at"])
+		assert.Nil(t, resp["timeline"][1]["actor"])
+		assert.Nil(t, resp["timeline"][1]["request_id"])
+	}
+}
+
+func TestGetPaymentTimelineHandler_PaymentNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentTimelineUseCase)
+	r := setupGetPaymentTimelineTestRouter(handler.NewGetPaymentTimelineHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(5)).Return(nil, payment.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/5/timeline", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetPaymentTimelineHandler_InvalidId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockGetPaymentTimelineUseCase)
+	r := setupGetPaymentTimelineTestRouter(handler.NewGetPaymentTimelineHandler(mockUC))
+
+	req, _ := http.NewRequest(http.MethodGet, "/payments/abc/timeline", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/infra/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+type LivezHandler struct{}
+
+func NewLivezHandler() *LivezHandler {
+	return &LivezHandler{}
+}
+
+// Execute only reports that the process is serving requests; dependencies
+// are covered by readiness so a database outage never restarts the pod.
+func (h *LivezHandler) Execute(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, health.Report{Status: health.StatusOk})
+}
+
+type ReadyzHandler struct {
+	readiness ReadinessChecker
+}
+
+func NewReadyzHandler(readiness ReadinessChecker) *ReadyzHandler {
+	return &ReadyzHandler{
+		readiness: readiness,
+	}
+}
+
+func (h *ReadyzHandler) Execute(ctx *gin.Context) {
+	report := h.readiness.Check(ctx.Request.Context())
+	if !report.Ready() {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/health"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReadiness struct {
+	mock.Mock
+}
+
+func (m *MockReadiness) Check(_ context.Context) health.Report {
+	return m.Called().Get(0).(health.Report)
+}
+
+func setupHealthTestRouter(readiness handler.ReadinessChecker) *gin.Engine {
+	r := gin.New()
+	r.GET("/livez", handler.NewLivezHandler().Execute)
+	r.GET("/readyz", handler.NewReadyzHandler(readiness).Execute)
+	return r
+}
+
+func TestLivezHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readiness := new(MockReadiness)
+	r := setupHealthTestRouter(readiness)
+
+	req, _ := http.NewRequest(http.MethodGet, "/livez", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	readiness.AssertNotCalled(t, "Check")
+}
+
+func TestReadyzHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should answer 200 when ready", func(t *testing.T) {
+		readiness := new(MockReadiness)
+		readiness.On("Check").Return(health.Report{Status: "ok", Checks: map[string]health.CheckResult{"database": {Status: "ok"}}})
+		r := setupHealthTestRouter(readiness)
+
+		req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok","checks":{"database":{"status":"ok"}}}`, w.Body.String())
+	})
+
+	t.Run("should answer 503 with the failing checks", func(t *testing.T) {
+		readiness := new(MockReadiness)
+		readiness.On("Check").Return(health.Report{Status: "unavailable", Checks: map[string]health.CheckResult{
+			"database": {Status: "unavailable", Error: "connection refused"},
+		}})
+		r := setupHealthTestRouter(readiness)
+
+		req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"unavailable","checks":{"database":{"status":"unavailable","error":"connection refused"}}}`, w.Body.String())
+	})
+}
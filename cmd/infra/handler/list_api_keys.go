@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListApiKeysUseCase interface {
+	Execute(ctx context.Context, merchantId int64) ([]apikey.Entity, error)
+}
+
+type ListApiKeysHandler struct {
+	useCase ListApiKeysUseCase
+}
+
+func NewListApiKeysHandler(useCase ListApiKeysUseCase) *ListApiKeysHandler {
+	return &ListApiKeysHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *ListApiKeysHandler) Execute(ctx *gin.Context) {
+	keys, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"api_keys": response})
+}
@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockListApiKeysUseCase struct {
+	mock.Mock
+}
+
+func (m *MockListApiKeysUseCase) Execute(_ context.Context, merchantId int64) ([]apikey.Entity, error) {
+	args := m.Called(merchantId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apikey.Entity), args.Error(1)
+}
+
+func setupListApiKeysTestRouter(h *handler.ListApiKeysHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/api-keys", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestListApiKeysHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListApiKeysUseCase)
+	h := handler.NewListApiKeysHandler(mockUC)
+	r := setupListApiKeysTestRouter(h)
+
+	mockUC.On("Execute", int64(10)).Return([]apikey.Entity{
+		*apikey.NewApiKeyBuilder().WithId(1).WithScopes("read").WithLastUsedAt(time.Now()).Build(),
+		*apikey.NewApiKeyBuilder().WithId(2).WithScopes("admin").WithRevokedAt(time.Now()).Build(),
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api-keys", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["api_keys"], 2) {
+		assert.NotNil(t, resp["api_keys"][0]["last_used_at"])
+		assert.Equal(t, false, resp["api_keys"][0]["revoked"])
+		assert.Equal(t, true, resp["api_keys"][1]["revoked"])
+		assert.NotContains(t, resp["api_keys"][0], "key")
+		assert.NotContains(t, resp["api_keys"][0], "signing_secret")
+	}
+}
+
+func TestListApiKeysHandler_UseCaseError_500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListApiKeysUseCase)
+	h := handler.NewListApiKeysHandler(mockUC)
+	r := setupListApiKeysTestRouter(h)
+
+	mockUC.On("Execute", int64(10)).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api-keys", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/charge"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListOrderChargesUseCase interface {
+	Execute(ctx context.Context, merchantId int64, orderId int64) ([]charge.Entity, error)
+}
+
+type ListOrderChargesHandler struct {
+	useCase ListOrderChargesUseCase
+}
+
+func NewListOrderChargesHandler(useCase ListOrderChargesUseCase) *ListOrderChargesHandler {
+	return &ListOrderChargesHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *ListOrderChargesHandler) Execute(ctx *gin.Context) {
+	orderId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid order id"))
+		return
+	}
+
+	charges, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), orderId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(charges))
+	for _, c := range charges {
+		response = append(response, gin.H{
+			"id":         c.Id(),
+			"payment_id": c.PaymentId(),
+			"category":   c.Category(),
+			"amount":     c.Amount(),
+			"created_at": c.CreatedAt(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"charges": response})
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockListOrderChargesUseCase struct {
+	mock.Mock
+}
+
+func (m *MockListOrderChargesUseCase) Execute(_ context.Context, merchantId int64, orderId int64) ([]charge.Entity, error) {
+	args := m.Called(merchantId, orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]charge.Entity), args.Error(1)
+}
+
+func setupListOrderChargesTestRouter(h *handler.ListOrderChargesHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/orders/:id/charges", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestListOrderChargesHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListOrderChargesUseCase)
+	r := setupListOrderChargesTestRouter(handler.NewListOrderChargesHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return([]charge.Entity{
+		*charge.NewChargeBuilder().WithId(1).WithPaymentId(5).WithCategory("financial_fee").WithAmount(1.5).Build(),
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/charges", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["charges"], 1) {
+		assert.Equal(t, float64(5), resp["charges"][0]["payment_id"])
+		assert.Equal(t, "financial_fee", resp["charges"][0]["category"])
+		assert.Equal(t, 1.5, resp["charges"][0]["amount"])
+	}
+}
+
+func TestListOrderChargesHandler_OrderNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListOrderChargesUseCase)
+	r := setupListOrderChargesTestRouter(handler.NewListOrderChargesHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return(nil, order.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/charges", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListOrderPaymentsUseCase interface {
+	Execute(ctx context.Context, merchantId int64, orderId int64) ([]payment.Entity, error)
+}
+
+type ListOrderPaymentsHandler struct {
+	useCase ListOrderPaymentsUseCase
+}
+
+func NewListOrderPaymentsHandler(useCase ListOrderPaymentsUseCase) *ListOrderPaymentsHandler {
+	return &ListOrderPaymentsHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *ListOrderPaymentsHandler) Execute(ctx *gin.Context) {
+	orderId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid order id"))
+		return
+	}
+
+	payments, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), orderId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"payments": paymentsResponse(payments)})
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockListOrderPaymentsUseCase struct {
+	mock.Mock
+}
+
+func (m *MockListOrderPaymentsUseCase) Execute(_ context.Context, merchantId int64, orderId int64) ([]payment.Entity, error) {
+	args := m.Called(merchantId, orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Entity), args.Error(1)
+}
+
+func setupListOrderPaymentsTestRouter(h *handler.ListOrderPaymentsHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/orders/:id/payments", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestListOrderPaymentsHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListOrderPaymentsUseCase)
+	r := setupListOrderPaymentsTestRouter(handler.NewListOrderPaymentsHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return([]payment.Entity{
+		*payment.NewPaymentBuilder().WithId(1).WithOrderId(3).WithAmount(10).Build(),
+		*payment.NewPaymentBuilder().WithId(2).WithOrderId(3).WithAmount(20).Build(),
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/payments", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["payments"], 2) {
+		assert.Equal(t, float64(1), resp["payments"][0]["id"])
+		assert.Equal(t, float64(20), resp["payments"][1]["amount"])
+	}
+}
+
+func TestListOrderPaymentsHandler_OrderNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListOrderPaymentsUseCase)
+	r := setupListOrderPaymentsTestRouter(handler.NewListOrderPaymentsHandler(mockUC))
+
+	mockUC.On("Execute", int64(10), int64(3)).Return(nil, order.ErrNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/orders/3/payments", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"payment-gateway/cmd/infra/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListPaymentMethodsUseCase interface {
+	Execute(ctx context.Context, merchantId int64) ([]paymentmethod.Entity, error)
+}
+
+type ListPaymentMethodsHandler struct {
+	useCase ListPaymentMethodsUseCase
+}
+
+func NewListPaymentMethodsHandler(useCase ListPaymentMethodsUseCase) *ListPaymentMethodsHandler {
+	return &ListPaymentMethodsHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *ListPaymentMethodsHandler) Execute(ctx *gin.Context) {
+	methods, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(methods))
+	for _, method := range methods {
+		response = append(response, gin.H{
+			"code":               method.Code(),
+			"fee_category":       method.FeeCategory(),
+			"fee_rate":           method.FeeRate(),
+			"min_ticket":         method.MinTicket(),
+			"max_ticket":         method.MaxTicket(),
+			"installments":       method.SupportsInstallments(),
+			"refunds":            method.SupportsRefunds(),
+			"async_confirmation": method.SupportsAsyncConfirmation(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"payment_methods": response})
+}
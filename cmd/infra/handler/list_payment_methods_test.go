@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockListPaymentMethodsUseCase struct {
+	mock.Mock
+}
+
+func (m *MockListPaymentMethodsUseCase) Execute(_ context.Context, merchantId int64) ([]paymentmethod.Entity, error) {
+	args := m.Called(merchantId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]paymentmethod.Entity), args.Error(1)
+}
+
+func setupListPaymentMethodsTestRouter(h *handler.ListPaymentMethodsHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/payment-methods", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestListPaymentMethodsHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListPaymentMethodsUseCase)
+	h := handler.NewListPaymentMethodsHandler(mockUC)
+	r := setupListPaymentMethodsTestRouter(h)
+
+	mockUC.On("Execute", int64(10)).Return([]paymentmethod.Entity{
+		*paymentmethod.NewPaymentMethodBuilder().
+			WithCode("CreditCard").
+			WithFee(paymentmethod.FeeCategoryFinancial, 0.1).
+			WithTicket(1, 100000).
+			WithInstallments(true).
+			Build(),
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payment-methods", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string][]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if assert.Len(t, resp["payment_methods"], 1) {
+		method := resp["payment_methods"][0]
+		assert.Equal(t, "CreditCard", method["code"])
+		assert.Equal(t, "financial_fee", method["fee_category"])
+		assert.Equal(t, 0.1, method["fee_rate"])
+		assert.Equal(t, float64(100000), method["max_ticket"])
+		assert.Equal(t, true, method["installments"])
+		assert.Equal(t, false, method["async_confirmation"])
+	}
+}
+
+func TestListPaymentMethodsHandler_UseCaseError_500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockListPaymentMethodsUseCase)
+	h := handler.NewListPaymentMethodsHandler(mockUC)
+	r := setupListPaymentMethodsTestRouter(h)
+
+	mockUC.On("Execute", int64(10)).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest(http.MethodGet, "/payment-methods", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -1,15 +1,16 @@
 package handler
 
 import (
-	"errors"
+	"context"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/validation"
 	"strconv"
 )
 
 type ProcessPaymentUseCase interface {
-	Execute(paymentID int64, paymentType string, details string) error
+	Execute(ctx context.Context, merchantId int64, paymentID int64, paymentType string, details string) error
 }
 
 type ProcessPaymentHandler struct {
@@ -25,30 +26,23 @@ func NewProcessPaymentHandler(useCase ProcessPaymentUseCase) *ProcessPaymentHand
 func (h *ProcessPaymentHandler) Execute(ctx *gin.Context) {
 	paymentID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
-		ctx.JSON(400, gin.H{"error": "invalid payment id"})
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid payment id"))
 		return
 	}
 
 	var request struct {
-		Type    string `json:"type"`
-		Details string `json:"details"`
+		Type    string `json:"type" binding:"required,oneof=Success Failure"`
+		Details string `json:"details" binding:"max=200"`
 	}
 
-	if err := ctx.ShouldBindJSON(&request); err != nil {
-		ctx.JSON(400, gin.H{"error": "invalid request body"})
+	if err := validation.BindJSON(ctx, &request); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	err = h.useCase.Execute(paymentID, request.Type, request.Details)
+	err = h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), paymentID, request.Type, request.Details)
 	if err != nil {
-		var ex *exceptions.DomainError
-
-		if errors.As(err, &ex) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": ex.Error()})
-			return
-		}
-
-		ctx.JSON(500, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
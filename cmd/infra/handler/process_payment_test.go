@@ -2,30 +2,34 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/payment"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
 )
 
 type MockProcessPaymentUseCase struct {
 	mock.Mock
 }
 
-func (m *MockProcessPaymentUseCase) Execute(paymentID int64, paymentType string, details string) error {
-	args := m.Called(paymentID, paymentType, details)
+func (m *MockProcessPaymentUseCase) Execute(_ context.Context, merchantId int64, paymentID int64, paymentType string, details string) error {
+	args := m.Called(merchantId, paymentID, paymentType, details)
 	return args.Error(0)
 }
 
 func setupProcessPaymentTestRouter(h *handler.ProcessPaymentHandler) *gin.Engine {
 	r := gin.Default()
-	r.POST("/payments/:id/process", h.Execute)
+	r.Use(middleware.ErrorHandler())
+	r.POST("/payments/:id/process", withMerchant(10), h.Execute)
 	return r
 }
 
@@ -37,10 +41,10 @@ func TestProcessPaymentHandler_Success(t *testing.T) {
 	r := setupProcessPaymentTestRouter(h)
 
 	paymentID := int64(123)
-	paymentType := "credit_card"
+	paymentType := "Success"
 	details := "card ending in 4242"
 
-	mockUC.On("Execute", paymentID, paymentType, details).Return(nil)
+	mockUC.On("Execute", int64(10), paymentID, paymentType, details).Return(nil)
 
 	reqBody := map[string]interface{}{
 		"type":    paymentType,
@@ -103,10 +107,10 @@ func TestProcessPaymentHandler_UseCaseError_500(t *testing.T) {
 	r := setupProcessPaymentTestRouter(h)
 
 	paymentID := int64(123)
-	paymentType := "credit_card"
+	paymentType := "Success"
 	details := "card ending in 4242"
 
-	mockUC.On("Execute", paymentID, paymentType, details).Return(assert.AnError)
+	mockUC.On("Execute", int64(10), paymentID, paymentType, details).Return(assert.AnError)
 
 	reqBody := map[string]interface{}{
 		"type":    paymentType,
@@ -123,7 +127,7 @@ func TestProcessPaymentHandler_UseCaseError_500(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
-func TestProcessPaymentHandler_UseCaseError_400(t *testing.T) {
+func TestProcessPaymentHandler_UseCaseError_409(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockUC := new(MockProcessPaymentUseCase)
@@ -131,10 +135,10 @@ func TestProcessPaymentHandler_UseCaseError_400(t *testing.T) {
 	r := setupProcessPaymentTestRouter(h)
 
 	paymentID := int64(123)
-	paymentType := "credit_card"
+	paymentType := "Success"
 	details := "card ending in 4242"
 
-	mockUC.On("Execute", paymentID, paymentType, details).Return(exceptions.NewDomainError("error processing payment"))
+	mockUC.On("Execute", int64(10), paymentID, paymentType, details).Return(exceptions.NewDomainError(exceptions.CodeInvalidTransition, "error processing payment"))
 
 	reqBody := map[string]interface{}{
 		"type":    paymentType,
@@ -147,6 +151,46 @@ func TestProcessPaymentHandler_UseCaseError_400(t *testing.T) {
 
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
 	mockUC.AssertExpectations(t)
 }
+
+func TestProcessPaymentHandler_PaymentNotFound_404(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockProcessPaymentUseCase)
+	h := handler.NewProcessPaymentHandler(mockUC)
+	r := setupProcessPaymentTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(999999), "Success", "details").Return(payment.ErrNotFound)
+
+	body := bytes.NewBufferString(`{"type": "Success", "details": "details"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/payments/999999/process", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "payment_not_found")
+	mockUC.AssertExpectations(t)
+}
+
+func TestProcessPaymentHandler_UnknownType_422(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockProcessPaymentUseCase)
+	h := handler.NewProcessPaymentHandler(mockUC)
+	r := setupProcessPaymentTestRouter(h)
+
+	body := bytes.NewBufferString(`{"type": "Maybe", "details": "details"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/payments/1/process", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "must be one of Success, Failure")
+	mockUC.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything)
+}
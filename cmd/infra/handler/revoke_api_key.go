@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevokeApiKeyUseCase interface {
+	Execute(ctx context.Context, merchantId int64, keyId int64) (*apikey.Entity, error)
+}
+
+type RevokeApiKeyHandler struct {
+	useCase RevokeApiKeyUseCase
+}
+
+func NewRevokeApiKeyHandler(useCase RevokeApiKeyUseCase) *RevokeApiKeyHandler {
+	return &RevokeApiKeyHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *RevokeApiKeyHandler) Execute(ctx *gin.Context) {
+	keyId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid api key id"))
+		return
+	}
+
+	key, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), keyId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiKeyResponse(*key))
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRevokeApiKeyUseCase struct {
+	mock.Mock
+}
+
+func (m *MockRevokeApiKeyUseCase) Execute(_ context.Context, merchantId int64, keyId int64) (*apikey.Entity, error) {
+	args := m.Called(merchantId, keyId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func setupRevokeApiKeyTestRouter(h *handler.RevokeApiKeyHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.DELETE("/api-keys/:id", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestRevokeApiKeyHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRevokeApiKeyUseCase)
+	h := handler.NewRevokeApiKeyHandler(mockUC)
+	r := setupRevokeApiKeyTestRouter(h)
+
+	key := apikey.NewApiKeyBuilder().WithId(1).WithScopes("read").WithRevokedAt(time.Now()).Build()
+	mockUC.On("Execute", int64(10), int64(1)).Return(key, nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api-keys/1", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, true, resp["revoked"])
+	assert.NotNil(t, resp["revoked_at"])
+}
+
+func TestRevokeApiKeyHandler_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewRevokeApiKeyHandler(nil)
+	r := setupRevokeApiKeyTestRouter(h)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api-keys/invalid", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRevokeApiKeyHandler_UseCaseError_404(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRevokeApiKeyUseCase)
+	h := handler.NewRevokeApiKeyHandler(mockUC)
+	r := setupRevokeApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(1)).Return(nil, exceptions.NewDomainError(exceptions.CodeApiKeyNotFound, "Api key not found"))
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api-keys/1", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRevokeApiKeyHandler_UseCaseError_500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRevokeApiKeyUseCase)
+	h := handler.NewRevokeApiKeyHandler(mockUC)
+	r := setupRevokeApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(1)).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api-keys/1", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RotateApiKeyUseCase interface {
+	Execute(ctx context.Context, merchantId int64, keyId int64) (*apikey.Entity, string, error)
+}
+
+type RotateApiKeyHandler struct {
+	useCase RotateApiKeyUseCase
+}
+
+func NewRotateApiKeyHandler(useCase RotateApiKeyUseCase) *RotateApiKeyHandler {
+	return &RotateApiKeyHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *RotateApiKeyHandler) Execute(ctx *gin.Context) {
+	keyId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid api key id"))
+		return
+	}
+
+	key, token, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), keyId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := apiKeyResponse(*key)
+	response["key"] = token
+	response["signing_secret"] = key.SigningSecret()
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRotateApiKeyUseCase struct {
+	mock.Mock
+}
+
+func (m *MockRotateApiKeyUseCase) Execute(_ context.Context, merchantId int64, keyId int64) (*apikey.Entity, string, error) {
+	args := m.Called(merchantId, keyId)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*apikey.Entity), args.String(1), args.Error(2)
+}
+
+func setupRotateApiKeyTestRouter(h *handler.RotateApiKeyHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/api-keys/:id/rotate", withMerchant(10), h.Execute)
+	return r
+}
+
+func TestRotateApiKeyHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRotateApiKeyUseCase)
+	h := handler.NewRotateApiKeyHandler(mockUC)
+	r := setupRotateApiKeyTestRouter(h)
+
+	key := apikey.NewApiKeyBuilder().WithId(1).WithScopes("read").WithSigningSecret("pgw_sig_new").Build()
+	mockUC.On("Execute", int64(10), int64(1)).Return(key, "pgw_test_new", nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys/1/rotate", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "pgw_test_new", resp["key"])
+	assert.Equal(t, "pgw_sig_new", resp["signing_secret"])
+	assert.Equal(t, float64(1), resp["id"])
+}
+
+func TestRotateApiKeyHandler_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewRotateApiKeyHandler(nil)
+	r := setupRotateApiKeyTestRouter(h)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys/invalid/rotate", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRotateApiKeyHandler_UseCaseError_409(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRotateApiKeyUseCase)
+	h := handler.NewRotateApiKeyHandler(mockUC)
+	r := setupRotateApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(1)).Return(nil, "", exceptions.NewDomainError(exceptions.CodeApiKeyRevoked, "Api key is already revoked"))
+
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys/1/rotate", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRotateApiKeyHandler_UseCaseError_500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockRotateApiKeyUseCase)
+	h := handler.NewRotateApiKeyHandler(mockUC)
+	r := setupRotateApiKeyTestRouter(h)
+
+	mockUC.On("Execute", int64(10), int64(1)).Return(nil, "", assert.AnError)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api-keys/1/rotate", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockUC.AssertExpectations(t)
+}
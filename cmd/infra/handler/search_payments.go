@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/validation"
+	"payment-gateway/cmd/usecases"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchPaymentsUseCase interface {
+	Execute(ctx context.Context, merchantId int64, search payment.Search) (usecases.PaymentPage, error)
+}
+
+type SearchPaymentsHandler struct {
+	useCase SearchPaymentsUseCase
+}
+
+func NewSearchPaymentsHandler(useCase SearchPaymentsUseCase) *SearchPaymentsHandler {
+	return &SearchPaymentsHandler{
+		useCase: useCase,
+	}
+}
+
+// Execute lists payments in the order given by sort, a column optionally
+// prefixed with "-" for descending order. The next page is requested by
+// passing back next_cursor with the same filters and sort.
+func (h *SearchPaymentsHandler) Execute(ctx *gin.Context) {
+	var request struct {
+		OrderId     int64     `form:"order_id" binding:"omitempty,gt=0"`
+		Status      string    `form:"status" binding:"omitempty,oneof=pending approved reproved refunded"`
+		Type        string    `form:"type" binding:"omitempty,payment_type"`
+		MinAmount   float64   `form:"min_amount" binding:"omitempty,gt=0"`
+		MaxAmount   float64   `form:"max_amount" binding:"omitempty,gt=0"`
+		CreatedFrom time.Time `form:"created_from"`
+		CreatedTo   time.Time `form:"created_to"`
+		Sort        string    `form:"sort" binding:"omitempty,oneof=id -id amount -amount created_at -created_at"`
+		Cursor      string    `form:"cursor"`
+		Limit       int       `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	}
+
+	if err := validation.BindQuery(ctx, &request); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if request.MinAmount != 0 && request.MaxAmount != 0 && request.MinAmount > request.MaxAmount {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "min_amount must not be greater than max_amount"))
+		return
+	}
+	if !request.CreatedFrom.IsZero() && !request.CreatedTo.IsZero() && !request.CreatedFrom.Before(request.CreatedTo) {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeInvalidRequest, "created_from must be before created_to"))
+		return
+	}
+
+	search := payment.Search{
+		OrderId:     request.OrderId,
+		Status:      request.Status,
+		Type:        request.Type,
+		MinAmount:   request.MinAmount,
+		MaxAmount:   request.MaxAmount,
+		CreatedFrom: request.CreatedFrom,
+		CreatedTo:   request.CreatedTo,
+		Sort:        strings.TrimPrefix(request.Sort, "-"),
+		Descending:  strings.HasPrefix(request.Sort, "-"),
+		Limit:       request.Limit,
+	}
+	if request.Cursor != "" {
+		after, err := decodeCursor(request.Cursor)
+		if err != nil {
+			ctx.Error(exceptions.NewValidationError(exceptions.FieldError{Field: "cursor", Code: "cursor", Message: "is invalid"}))
+			return
+		}
+		search.After = &after
+	}
+
+	page, err := h.useCase.Execute(ctx.Request.Context(), middleware.MerchantId(ctx), search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var next any
+	if page.Next != nil {
+		next = encodeCursor(*page.Next)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"payments":    paymentsResponse(page.Payments),
+		"next_cursor": next,
+	})
+}
+
+// Cursors are opaque to clients: the position of the last payment of a page,
+// as URL-safe base64 JSON.
+func encodeCursor(cursor payment.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (payment.Cursor, error) {
+	var cursor payment.Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err == nil {
+		err = json.Unmarshal(raw, &cursor)
+	}
+
+	return cursor, err
+}
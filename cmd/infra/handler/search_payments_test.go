@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/handler"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchPaymentsUseCase struct {
+	mock.Mock
+}
+
+func (m *MockSearchPaymentsUseCase) Execute(_ context.Context, merchantId int64, search payment.Search) (usecases.PaymentPage, error) {
+	args := m.Called(merchantId, search)
+	return args.Get(0).(usecases.PaymentPage), args.Error(1)
+}
+
+func setupSearchPaymentsTestRouter(h *handler.SearchPaymentsHandler) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/payments", withMerchant(10), h.Execute)
+	return r
+}
+
+func searchPayments(r *gin.Engine, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req, _ := http.NewRequest(http.MethodGet, "/payments?"+query, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestSearchPaymentsHandler_Filters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockSearchPaymentsUseCase)
+	r := setupSearchPaymentsTestRouter(handler.NewSearchPaymentsHandler(mockUC))
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mockUC.On("Execute", int64(10), payment.Search{
+		OrderId:     3,
+		Status:      "approved",
+		Type:        "CreditCard",
+		MinAmount:   10,
+		MaxAmount:   99.9,
+		CreatedFrom: from,
+		CreatedTo:   to,
+		Sort:        "created_at",
+		Descending:  true,
+		Limit:       50,
+	}).Return(usecases.PaymentPage{Payments: []payment.Entity{*payment.NewPaymentBuilder().WithId(1).Build()}}, nil)
+
+	w, resp := searchPayments(r, "order_id=3&status=approved&type=CreditCard&min_amount=10&max_amount=99.9"+
+		"&created_from=2026-01-01T00:00:00Z&created_to=2026-02-01T00:00:00Z&sort=-created_at&limit=50")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+	assert.Len(t, resp["payments"], 1)
+	assert.Nil(t, resp["next_cursor"])
+}
+
+func TestSearchPaymentsHandler_Cursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockSearchPaymentsUseCase)
+	r := setupSearchPaymentsTestRouter(handler.NewSearchPaymentsHandler(mockUC))
+
+	next := payment.Cursor{Id: 2, Amount: 20, CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	mockUC.On("Execute", int64(10), payment.Search{}).Return(usecases.PaymentPage{Next: &next}, nil).Once()
+	mockUC.On("Execute", int64(10), payment.Search{After: &next}).Return(usecases.PaymentPage{}, nil).Once()
+
+	w, first := searchPayments(r, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{}, first["payments"])
+	cursor, ok := first["next_cursor"].(string)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	w, second := searchPayments(r, "cursor="+cursor)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, second["next_cursor"])
+	mockUC.AssertExpectations(t)
+}
+
+func TestSearchPaymentsHandler_InvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(MockSearchPaymentsUseCase)
+	r := setupSearchPaymentsTestRouter(handler.NewSearchPaymentsHandler(mockUC))
+
+	w, resp := searchPayments(r, "status=paid&sort=name&limit=1000")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "validation_failed", resp["code"])
+
+	w, resp = searchPayments(r, "cursor=not-a-cursor")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "validation_failed", resp["code"])
+
+	w, resp = searchPayments(r, "created_from=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", resp["code"])
+
+	w, resp = searchPayments(r, "min_amount=50&max_amount=10")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", resp["code"])
+
+	w, resp = searchPayments(r, "created_from=2026-02-01T00:00:00Z&created_to=2026-02-01T00:00:00Z")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", resp["code"])
+
+	mockUC.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
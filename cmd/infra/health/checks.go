@@ -0,0 +1,8 @@
+package health
+
+import "database/sql"
+
+// Database checks that the connection pool can reach the server.
+func Database(db *sql.DB) Checker {
+	return Check("database", db.PingContext)
+}
@@ -0,0 +1,23 @@
+package health_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/health"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase(t *testing.T) {
+	t.Run("should fail when the database does not answer", func(t *testing.T) {
+		db, mock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+		mock.ExpectPing().WillReturnError(assert.AnError)
+
+		err := health.Database(db).Check(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, "database", health.Database(db).Name())
+	})
+}
@@ -0,0 +1,106 @@
+// Package health implements the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOk          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusShutdown    = "shutting_down"
+)
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// Check adapts a function to a Checker.
+func Check(name string, check func(ctx context.Context) error) Checker {
+	return checkFunc{name: name, check: check}
+}
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status == StatusOk
+}
+
+type Readiness struct {
+	checkers     []Checker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewReadiness(timeout time.Duration, checkers ...Checker) *Readiness {
+	return &Readiness{
+		checkers: checkers,
+		timeout:  timeout,
+	}
+}
+
+// Shutdown makes every later probe fail so load balancers stop routing new
+// traffic while in-flight requests drain.
+func (r *Readiness) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs every checker concurrently, each bounded by the probe timeout.
+func (r *Readiness) Check(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusShutdown}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOk, Checks: make(map[string]CheckResult, len(r.checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range r.checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+
+			result := CheckResult{Status: StatusOk}
+			if err := checker.Check(ctx); err != nil {
+				result = CheckResult{Status: StatusUnavailable, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+			if result.Status != StatusOk {
+				report.Status = StatusUnavailable
+			}
+		}(checker)
+	}
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,54 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"payment-gateway/cmd/infra/health"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := health.Check("database", func(ctx context.Context) error { return nil })
+	failing := health.Check("worker", func(ctx context.Context) error { return errors.New("stopped") })
+
+	t.Run("should be ready when every check passes", func(t *testing.T) {
+		report := health.NewReadiness(time.Second, ok).Check(context.Background())
+
+		assert.True(t, report.Ready())
+		assert.Equal(t, map[string]health.CheckResult{"database": {Status: "ok"}}, report.Checks)
+	})
+
+	t.Run("should report every failing check", func(t *testing.T) {
+		report := health.NewReadiness(time.Second, ok, failing).Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, "unavailable", report.Status)
+		assert.Equal(t, health.CheckResult{Status: "unavailable", Error: "stopped"}, report.Checks["worker"])
+		assert.Equal(t, health.CheckResult{Status: "ok"}, report.Checks["database"])
+	})
+
+	t.Run("should bound slow checks by the timeout", func(t *testing.T) {
+		slow := health.Check("database", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		report := health.NewReadiness(10*time.Millisecond, slow).Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+	})
+
+	t.Run("should stop being ready once shutting down", func(t *testing.T) {
+		readiness := health.NewReadiness(time.Second, ok)
+		readiness.Shutdown()
+
+		report := readiness.Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, "shutting_down", report.Status)
+	})
+}
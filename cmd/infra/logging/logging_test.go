@@ -0,0 +1,86 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"payment-gateway/cmd/infra/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should tag records with the request id from the context", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := logging.New(&buf, "info", "json")
+		assert.NoError(t, err)
+
+		ctx := logging.WithRequestId(context.Background(), "req-1")
+		logger.With("component", "test").InfoContext(ctx, "payment created")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "payment created", line["msg"])
+		assert.Equal(t, "req-1", line["request_id"])
+		assert.NotContains(t, line, "actor")
+		assert.Equal(t, "test", line["component"])
+	})
+
+	t.Run("should tag records with the actor from the context", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := logging.New(&buf, "info", "json")
+
+		ctx := logging.WithActor(context.Background(), "api_key:abc123")
+		logger.InfoContext(ctx, "payment processed")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "api_key:abc123", line["actor"])
+		assert.Equal(t, "api_key:abc123", logging.Actor(ctx))
+	})
+
+	t.Run("should tag records with the active trace", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := logging.New(&buf, "info", "json")
+
+		traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}))
+		logger.InfoContext(ctx, "payment created")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", line["span_id"])
+	})
+
+	t.Run("should omit the request id when the context has none", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := logging.New(&buf, "info", "json")
+
+		logger.InfoContext(context.Background(), "started")
+
+		assert.NotContains(t, buf.String(), "request_id")
+	})
+
+	t.Run("should respect the configured level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := logging.New(&buf, "warn", "text")
+
+		logger.Info("ignored")
+		logger.Warn("kept")
+
+		assert.NotContains(t, buf.String(), "ignored")
+		assert.Contains(t, buf.String(), "msg=kept")
+	})
+
+	t.Run("should reject unknown levels and formats", func(t *testing.T) {
+		_, err := logging.New(&bytes.Buffer{}, "verbose", "json")
+		assert.Error(t, err)
+
+		_, err = logging.New(&bytes.Buffer{}, "info", "xml")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+var sensitiveKeys = map[string]bool{
+	"card":           true,
+	"card_number":    true,
+	"pan":            true,
+	"cvv":            true,
+	"cvc":            true,
+	"expiry":         true,
+	"document":       true,
+	"cpf":            true,
+	"cnpj":           true,
+	"details":        true,
+	"password":       true,
+	"secret":         true,
+	"signing_secret": true,
+	"token":          true,
+	"api_key":        true,
+	"authorization":  true,
+}
+
+var sensitivePatterns = []*regexp.Regexp{
+	// Card numbers: 13 to 19 digits, optionally grouped by spaces or dashes.
+	regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	// CPF and CNPJ, formatted or not.
+	regexp.MustCompile(`\b\d{3}\.\d{3}\.\d{3}-\d{2}\b`),
+	regexp.MustCompile(`\b\d{2}\.\d{3}\.\d{3}/\d{4}-\d{2}\b`),
+	regexp.MustCompile(`\b\d{11}\b`),
+}
+
+// Redact is a slog ReplaceAttr function that hides attributes whose key names
+// sensitive data and masks card numbers and documents found in string values.
+func Redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(strings.ReplaceAll(attr.Key, "-", "_"))
+	if sensitiveKeys[key] {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	if attr.Value.Kind() != slog.KindString {
+		return attr
+	}
+
+	value := attr.Value.String()
+	for _, pattern := range sensitivePatterns {
+		value = pattern.ReplaceAllString(value, Redacted)
+	}
+
+	return slog.String(attr.Key, value)
+}
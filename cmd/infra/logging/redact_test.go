@@ -0,0 +1,29 @@
+package logging_test
+
+import (
+	"log/slog"
+	"payment-gateway/cmd/infra/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	t.Run("should redact sensitive keys", func(t *testing.T) {
+		for _, key := range []string{"details", "card_number", "CVV", "document", "Signing-Secret"} {
+			attr := logging.Redact(nil, slog.String(key, "anything"))
+			assert.Equal(t, logging.Redacted, attr.Value.String(), key)
+		}
+	})
+
+	t.Run("should mask card numbers and documents inside values", func(t *testing.T) {
+		attr := logging.Redact(nil, slog.String("error", "card 4111 1111 1111 1111 declined for 123.456.789-09"))
+
+		assert.Equal(t, "card [REDACTED] declined for [REDACTED]", attr.Value.String())
+	})
+
+	t.Run("should keep ordinary attributes", func(t *testing.T) {
+		assert.Equal(t, "POST /payments", logging.Redact(nil, slog.String("route", "POST /payments")).Value.String())
+		assert.Equal(t, int64(4111111111111111), logging.Redact(nil, slog.Int64("payment_id", 4111111111111111)).Value.Int64())
+	})
+}
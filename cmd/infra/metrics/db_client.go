@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"payment-gateway/cmd/infra/db"
+	"sync"
+	"time"
+)
+
+type QueryObserver interface {
+	ObserveQuery(dao string, method string, duration time.Duration, err error)
+}
+
+type operationKey struct{}
+
+type operation struct {
+	dao    string
+	method string
+}
+
+// WithOperation labels the statements issued with ctx with the DAO method
+// that issues them.
+func WithOperation(ctx context.Context, dao string, method string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation{dao: dao, method: method})
+}
+
+// Client times every statement and labels it with the operation set on its
+// context by WithOperation, or "unknown" without one. Queries are timed until
+// their rows are closed, so reading the result counts too.
+type Client struct {
+	client   db.Client
+	observer QueryObserver
+}
+
+func NewClient(client db.Client, observer QueryObserver) *Client {
+	return &Client{client: client, observer: observer}
+}
+
+func (c *Client) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := c.client.ExecContext(ctx, query, args...)
+	c.observe(ctx, start, err)
+
+	return result, err
+}
+
+func (c *Client) QueryContext(ctx context.Context, query string, args ...any) (db.Rows, error) {
+	start := time.Now()
+	rows, err := c.client.QueryContext(ctx, query, args...)
+	if err != nil {
+		c.observe(ctx, start, err)
+		return nil, err
+	}
+
+	return &observedRows{Rows: rows, observe: func(err error) { c.observe(ctx, start, err) }}, nil
+}
+
+func (c *Client) observe(ctx context.Context, start time.Time, err error) {
+	op, ok := ctx.Value(operationKey{}).(operation)
+	if !ok {
+		op = operation{dao: "unknown", method: "unknown"}
+	}
+	c.observer.ObserveQuery(op.dao, op.method, time.Since(start), err)
+}
+
+type observedRows struct {
+	db.Rows
+	observe func(err error)
+	once    sync.Once
+}
+
+func (r *observedRows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(func() { r.observe(errors.Join(r.Rows.Err(), err)) })
+
+	return err
+}
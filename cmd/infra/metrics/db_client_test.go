@@ -0,0 +1,89 @@
+package metrics_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/metrics"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQueryObserver struct {
+	mock.Mock
+}
+
+func (m *MockQueryObserver) ObserveQuery(dao string, method string, duration time.Duration, err error) {
+	m.Called(dao, method, err != nil)
+}
+
+func TestClient(t *testing.T) {
+	t.Run("should label queries with the calling DAO method", func(t *testing.T) {
+		db, sqlMock, _ := sqlmock.New()
+		defer db.Close()
+		observer := new(MockQueryObserver)
+		observer.On("ObserveQuery", "OrderDao", "FindById", false).Once()
+
+		sqlMock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "merchant_id", "status", "amount", "paid_amount", "charges_amount", "refunded_amount", "created_at", "updated_at"}).
+				AddRow(1, 4, "pending", 10.0, 0.0, 0.0, 0.0, time.Now(), time.Now()))
+
+		_, err := dao.NewOrderDao(metrics.NewClient(dbclient.NewTxClient(db), observer), dbclient.MySQL).FindById(context.Background(), 1)
+
+		assert.NoError(t, err)
+		observer.AssertExpectations(t)
+	})
+
+	t.Run("should keep the label through private helpers and flag failures", func(t *testing.T) {
+		db, sqlMock, _ := sqlmock.New()
+		defer db.Close()
+		observer := new(MockQueryObserver)
+		observer.On("ObserveQuery", "ApiKeyDao", "FindByHash", true).Once()
+
+		sqlMock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash").WillReturnError(assert.AnError)
+
+		_, err := dao.NewApiKeyDao(metrics.NewClient(dbclient.NewTxClient(db), observer), dbclient.MySQL).FindByHash(context.Background(), "hash")
+
+		assert.Error(t, err)
+		observer.AssertExpectations(t)
+	})
+
+	t.Run("should label queries with the operation on the context, or as unknown", func(t *testing.T) {
+		db, sqlMock, _ := sqlmock.New()
+		defer db.Close()
+		observer := new(MockQueryObserver)
+		observer.On("ObserveQuery", "unknown", "unknown", false).Once()
+		observer.On("ObserveQuery", "NonceDao", "Register", false).Once()
+		sqlMock.ExpectExec("DELETE FROM nonces").WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec("DELETE FROM nonces").WillReturnResult(sqlmock.NewResult(0, 0))
+		client := metrics.NewClient(dbclient.NewTxClient(db), observer)
+
+		_, err := client.ExecContext(context.Background(), "DELETE FROM nonces")
+		assert.NoError(t, err)
+		_, err = client.ExecContext(metrics.WithOperation(context.Background(), "NonceDao", "Register"), "DELETE FROM nonces")
+		assert.NoError(t, err)
+
+		observer.AssertExpectations(t)
+	})
+	t.Run("should observe queries once their rows are closed", func(t *testing.T) {
+		db, sqlMock, _ := sqlmock.New()
+		defer db.Close()
+		observer := new(MockQueryObserver)
+		sqlMock.ExpectQuery("SELECT id FROM orders").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		client := metrics.NewClient(dbclient.NewTxClient(db), observer)
+
+		rows, err := client.QueryContext(metrics.WithOperation(context.Background(), "OrderDao", "FindById"), "SELECT id FROM orders")
+		assert.NoError(t, err)
+		observer.AssertNotCalled(t, "ObserveQuery", "OrderDao", "FindById", false)
+
+		observer.On("ObserveQuery", "OrderDao", "FindById", false).Once()
+		assert.NoError(t, rows.Close())
+		assert.NoError(t, rows.Close())
+
+		observer.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,134 @@
+// Package metrics exposes the Prometheus collectors of the gateway.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "payment_gateway"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.HistogramVec
+	dbQueries    *prometheus.HistogramVec
+
+	paymentsCreated  *prometheus.CounterVec
+	paymentsApproved *prometheus.CounterVec
+	paymentsReproved *prometheus.CounterVec
+	chargeAmount     *prometheus.CounterVec
+	ordersPaid       prometheus.Counter
+	balanceDrift     prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of database queries by DAO method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"dao", "method", "outcome"}),
+		paymentsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_created_total",
+			Help:      "Payments created by payment type.",
+		}, []string{"type"}),
+		paymentsApproved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_approved_total",
+			Help:      "Payments approved by payment type.",
+		}, []string{"type"}),
+		paymentsReproved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_reproved_total",
+			Help:      "Payments reproved by payment type.",
+		}, []string{"type"}),
+		chargeAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "charge_amount_total",
+			Help:      "Sum of charge amounts by fee category.",
+		}, []string{"category"}),
+		ordersPaid: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_paid_total",
+			Help:      "Orders that transitioned to paid.",
+		}),
+		balanceDrift: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "order_balance_drift",
+			Help:      "Orders whose stored balance disagreed with their payments and charges at the last check.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.dbQueries,
+		m.paymentsCreated,
+		m.paymentsApproved,
+		m.paymentsReproved,
+		m.chargeAmount,
+		m.ordersPaid,
+		m.balanceDrift,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(dao string, method string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+
+	m.dbQueries.WithLabelValues(dao, method, outcome).Observe(duration.Seconds())
+}
+
+func (m *Metrics) PaymentCreated(paymentType string) {
+	m.paymentsCreated.WithLabelValues(paymentType).Inc()
+}
+
+func (m *Metrics) PaymentProcessed(paymentType string, status string) {
+	switch status {
+	case "approved":
+		m.paymentsApproved.WithLabelValues(paymentType).Inc()
+	case "reproved":
+		m.paymentsReproved.WithLabelValues(paymentType).Inc()
+	}
+}
+
+func (m *Metrics) ChargeCreated(category string, amount float64) {
+	m.chargeAmount.WithLabelValues(category).Add(amount)
+}
+
+func (m *Metrics) OrderPaid() {
+	m.ordersPaid.Inc()
+}
+
+func (m *Metrics) BalanceDrift(orders int) {
+	m.balanceDrift.Set(float64(orders))
+}
@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("should expose payment counters by type and status", func(t *testing.T) {
+		m := metrics.New()
+
+		m.PaymentCreated("CreditCard")
+		m.PaymentCreated("CreditCard")
+		m.PaymentProcessed("CreditCard", "approved")
+		m.PaymentProcessed("CashSlip", "reproved")
+		m.PaymentProcessed("CashSlip", "pending")
+
+		body := scrape(t, m)
+		assert.Contains(t, body, `payment_gateway_payments_created_total{type="CreditCard"} 2`)
+		assert.Contains(t, body, `payment_gateway_payments_approved_total{type="CreditCard"} 1`)
+		assert.Contains(t, body, `payment_gateway_payments_reproved_total{type="CashSlip"} 1`)
+		assert.NotContains(t, body, `payment_gateway_payments_approved_total{type="CashSlip"}`)
+	})
+
+	t.Run("should sum charge amounts by category and count paid orders", func(t *testing.T) {
+		m := metrics.New()
+
+		m.ChargeCreated("financial_fee", 10)
+		m.ChargeCreated("financial_fee", 2.5)
+		m.OrderPaid()
+
+		body := scrape(t, m)
+		assert.Contains(t, body, `payment_gateway_charge_amount_total{category="financial_fee"} 12.5`)
+		assert.Contains(t, body, `payment_gateway_orders_paid_total 1`)
+	})
+
+	t.Run("should expose the orders drifted at the last balance check", func(t *testing.T) {
+		m := metrics.New()
+
+		m.BalanceDrift(3)
+		m.BalanceDrift(1)
+
+		assert.Contains(t, scrape(t, m), `payment_gateway_order_balance_drift 1`)
+	})
+
+	t.Run("should expose request and query histograms", func(t *testing.T) {
+		m := metrics.New()
+
+		m.ObserveRequest("POST", "/payments", 201, 20*time.Millisecond)
+		m.ObserveQuery("OrderDao", "FindById", time.Millisecond, nil)
+
+		body := scrape(t, m)
+		assert.Contains(t, body, `payment_gateway_http_request_duration_seconds_count{method="POST",route="/payments",status="201"} 1`)
+		assert.Contains(t, body, `payment_gateway_db_query_duration_seconds_count{dao="OrderDao",method="FindById",outcome="success"} 1`)
+	})
+}
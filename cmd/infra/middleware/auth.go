@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/logging"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyContextKey = "api_key"
+	bearerPrefix     = "Bearer "
+	actorPrefix      = "api_key:"
+)
+
+type AuthenticateUseCase interface {
+	Execute(ctx context.Context, token string) (*apikey.Entity, error)
+}
+
+func Authenticate(useCase AuthenticateUseCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		if !strings.HasPrefix(header, bearerPrefix) {
+			unauthorized(ctx, exceptions.NewDomainError(exceptions.CodeMissingApiKey, "Authorization header must carry a Bearer api key"))
+			return
+		}
+
+		key, err := useCase.Execute(ctx.Request.Context(), strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix)))
+		if err != nil {
+			unauthorized(ctx, err)
+			return
+		}
+
+		SetApiKey(ctx, key)
+		ctx.Next()
+	}
+}
+
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ApiKey(ctx)
+		if key == nil {
+			unauthorized(ctx, exceptions.NewDomainError(exceptions.CodeMissingApiKey, "Request is not authenticated"))
+			return
+		}
+
+		if !key.HasScope(scope) {
+			abortWithError(ctx, exceptions.NewDomainError(exceptions.CodeInsufficientScope, "Api key is missing the "+scope+" scope"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// SetApiKey attaches the key to the request and names it as the actor of
+// what the request changes.
+func SetApiKey(ctx *gin.Context, key *apikey.Entity) {
+	ctx.Set(apiKeyContextKey, key)
+	ctx.Request = ctx.Request.WithContext(logging.WithActor(ctx.Request.Context(), actorPrefix+key.Prefix()))
+}
+
+func ApiKey(ctx *gin.Context) *apikey.Entity {
+	value, ok := ctx.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+
+	return value.(*apikey.Entity)
+}
+
+func MerchantId(ctx *gin.Context) int64 {
+	key := ApiKey(ctx)
+	if key == nil {
+		return 0
+	}
+
+	return key.MerchantId()
+}
+
+func unauthorized(ctx *gin.Context, err error) {
+	var ex *exceptions.DomainError
+	if errors.As(err, &ex) {
+		ctx.Header("WWW-Authenticate", `Bearer realm="payment-gateway"`)
+	}
+
+	abortWithError(ctx, err)
+}
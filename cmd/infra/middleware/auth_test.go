@@ -0,0 +1,151 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthenticateUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticateUseCase) Execute(_ context.Context, token string) (*apikey.Entity, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func setupAuthTestRouter(useCase middleware.AuthenticateUseCase, scope string) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/protected", middleware.Authenticate(useCase), middleware.RequireScope(scope), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"merchant_id": middleware.MerchantId(ctx), "actor": logging.Actor(ctx.Request.Context())})
+	})
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should attach merchant of a valid key", func(t *testing.T) {
+		mockUC := new(MockAuthenticateUseCase)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithPrefix("abc123").WithScopes("read").Build()
+		mockUC.On("Execute", "pgw_test_token").Return(key, nil)
+		r := setupAuthTestRouter(mockUC, apikey.ScopeRead)
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer pgw_test_token")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, float64(10), resp["merchant_id"])
+		assert.Equal(t, "api_key:abc123", resp["actor"])
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 when header is missing", func(t *testing.T) {
+		mockUC := new(MockAuthenticateUseCase)
+		r := setupAuthTestRouter(mockUC, apikey.ScopeRead)
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "missing_api_key", resp["code"])
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 when key is invalid", func(t *testing.T) {
+		mockUC := new(MockAuthenticateUseCase)
+		mockUC.On("Execute", "wrong").Return(nil, exceptions.NewDomainError(exceptions.CodeInvalidApiKey, "Invalid api key"))
+		r := setupAuthTestRouter(mockUC, apikey.ScopeRead)
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "invalid_api_key", resp["code"])
+		assert.Equal(t, "Invalid api key", resp["detail"])
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("should return 500 when authentication fails unexpectedly", func(t *testing.T) {
+		mockUC := new(MockAuthenticateUseCase)
+		mockUC.On("Execute", "token").Return(nil, assert.AnError)
+		r := setupAuthTestRouter(mockUC, apikey.ScopeRead)
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockUC.AssertExpectations(t)
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should return 403 when key lacks scope", func(t *testing.T) {
+		mockUC := new(MockAuthenticateUseCase)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithScopes("read").Build()
+		mockUC.On("Execute", "pgw_test_token").Return(key, nil)
+		r := setupAuthTestRouter(mockUC, apikey.ScopeWrite)
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer pgw_test_token")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "insufficient_scope", resp["code"])
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 when request was not authenticated", func(t *testing.T) {
+		r := gin.New()
+		r.Use(middleware.ErrorHandler())
+		r.GET("/protected", middleware.RequireScope(apikey.ScopeRead), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler renders the last error recorded with ctx.Error as
+// application/problem+json. It must be registered before every middleware
+// that can fail the request, so it sees their errors, and after those that
+// only observe the response, such as the access log and the request metrics,
+// so they record the status it writes.
+func ErrorHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		details := problem.FromError(ctx.Errors.Last().Err, ctx.Request.URL.Path)
+		ctx.Header("Content-Type", problem.ContentType)
+		ctx.JSON(details.Status, details)
+	}
+}
+
+func NotFound() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Error(exceptions.NewDomainError(exceptions.CodeRouteNotFound, "No route matches "+ctx.Request.Method+" "+ctx.Request.URL.Path))
+	}
+}
+
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
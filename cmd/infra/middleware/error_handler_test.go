@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupErrorHandlerTestRouter(err error) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.NoRoute(middleware.NotFound())
+	r.GET("/orders/:id", func(ctx *gin.Context) {
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"id": 1})
+	})
+	return r
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should render domain errors as problem details", func(t *testing.T) {
+		r := setupErrorHandlerTestRouter(exceptions.NewDomainError(exceptions.CodeOrderNotFound, "Order not found"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "/problems/order_not_found", resp["type"])
+		assert.Equal(t, "Order not found", resp["title"])
+		assert.Equal(t, "Order not found", resp["detail"])
+		assert.Equal(t, "/orders/1", resp["instance"])
+		assert.Equal(t, float64(404), resp["status"])
+	})
+
+	t.Run("should render validation field errors", func(t *testing.T) {
+		r := setupErrorHandlerTestRouter(exceptions.NewValidationError(
+			exceptions.FieldError{Field: "amount", Code: "gt", Message: "must be greater than 0"},
+		))
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"errors":[{"field":"amount","code":"gt","detail":"must be greater than 0"}]`)
+	})
+
+	t.Run("should render unexpected errors as 500 without leaking details", func(t *testing.T) {
+		r := setupErrorHandlerTestRouter(errors.New("dial tcp: connection refused"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("should leave successful responses untouched", func(t *testing.T) {
+		r := setupErrorHandlerTestRouter(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("should render unknown routes as problem details", func(t *testing.T) {
+		r := setupErrorHandlerTestRouter(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/unknown", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "route_not_found")
+	})
+}
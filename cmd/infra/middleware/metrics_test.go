@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRequestObserver struct {
+	mock.Mock
+}
+
+func (m *MockRequestObserver) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	m.Called(method, route, status)
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should observe requests by registered route", func(t *testing.T) {
+		observer := new(MockRequestObserver)
+		observer.On("ObserveRequest", http.MethodGet, "/orders/:id", http.StatusOK).Once()
+
+		r := gin.New()
+		r.Use(middleware.Metrics(observer))
+		r.GET("/orders/:id", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/42", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		observer.AssertExpectations(t)
+	})
+
+	t.Run("should group unknown paths under a single label", func(t *testing.T) {
+		observer := new(MockRequestObserver)
+		observer.On("ObserveRequest", http.MethodGet, "unmatched", http.StatusNotFound).Once()
+
+		r := gin.New()
+		r.Use(middleware.Metrics(observer))
+
+		req, _ := http.NewRequest(http.MethodGet, "/does-not-exist/1", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		observer.AssertExpectations(t)
+	})
+	t.Run("should group non-standard methods under a single label", func(t *testing.T) {
+		observer := new(MockRequestObserver)
+		observer.On("ObserveRequest", "OTHER", "unmatched", http.StatusNotFound).Once()
+
+		r := gin.New()
+		r.Use(middleware.Metrics(observer))
+
+		req, _ := http.NewRequest("PROPFIND", "/orders/42", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		observer.AssertExpectations(t)
+	})
+}
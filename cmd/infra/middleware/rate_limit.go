@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimiter interface {
+	Allow(ctx context.Context, keyId int64, merchantId int64, route string, now time.Time) (ratelimit.Result, bool, error)
+}
+
+type IPRateLimiter interface {
+	AllowIP(ctx context.Context, ip string, now time.Time) (ratelimit.Result, bool, error)
+}
+
+func RateLimit(limiter RateLimiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ApiKey(ctx)
+		if key == nil {
+			ctx.Next()
+			return
+		}
+
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		result, limited, err := limiter.Allow(ctx.Request.Context(), key.Id(), key.MerchantId(), route, time.Now())
+		enforce(ctx, result, limited, err)
+	}
+}
+
+// RateLimitIP limits requests by client address before they are
+// authenticated, so requests with invalid keys are throttled too.
+func RateLimitIP(limiter IPRateLimiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, limited, err := limiter.AllowIP(ctx.Request.Context(), ctx.ClientIP(), time.Now())
+		enforce(ctx, result, limited, err)
+	}
+}
+
+func enforce(ctx *gin.Context, result ratelimit.Result, limited bool, err error) {
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !limited {
+		ctx.Next()
+		return
+	}
+
+	ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		abortWithError(ctx, exceptions.NewDomainError(exceptions.CodeRateLimited, "Rate limit exceeded, retry later"))
+		return
+	}
+
+	ctx.Next()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+func (m *MockRateLimiter) Allow(_ context.Context, keyId int64, merchantId int64, route string, now time.Time) (ratelimit.Result, bool, error) {
+	args := m.Called(keyId, merchantId, route)
+	return args.Get(0).(ratelimit.Result), args.Bool(1), args.Error(2)
+}
+
+func (m *MockRateLimiter) AllowIP(_ context.Context, ip string, now time.Time) (ratelimit.Result, bool, error) {
+	args := m.Called(ip)
+	return args.Get(0).(ratelimit.Result), args.Bool(1), args.Error(2)
+}
+
+func setupRateLimitTestRouter(limiter middleware.RateLimiter, authenticated bool) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.Use(func(ctx *gin.Context) {
+		if authenticated {
+			middleware.SetApiKey(ctx, apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build())
+		}
+		ctx.Next()
+	})
+	r.POST("/payments", middleware.RateLimit(limiter), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+	return r
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should pass through and set quota headers", func(t *testing.T) {
+		limiter := new(MockRateLimiter)
+		limiter.On("Allow", int64(1), int64(10), "POST /payments").
+			Return(ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 200 * time.Millisecond}, true, nil)
+		r := setupRateLimitTestRouter(limiter, true)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "9", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+		limiter.AssertExpectations(t)
+	})
+
+	t.Run("should return 429 with retry after when limited", func(t *testing.T) {
+		limiter := new(MockRateLimiter)
+		limiter.On("Allow", int64(1), int64(10), "POST /payments").
+			Return(ratelimit.Result{Allowed: false, Limit: 10, RetryAfter: 1500 * time.Millisecond, Reset: 10 * time.Second}, true, nil)
+		r := setupRateLimitTestRouter(limiter, true)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "10", w.Header().Get("RateLimit-Reset"))
+		assert.Contains(t, w.Body.String(), "rate_limited")
+	})
+
+	t.Run("should skip unauthenticated requests", func(t *testing.T) {
+		limiter := new(MockRateLimiter)
+		r := setupRateLimitTestRouter(limiter, false)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		limiter.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 500 when the store fails", func(t *testing.T) {
+		limiter := new(MockRateLimiter)
+		limiter.On("Allow", int64(1), int64(10), "POST /payments").
+			Return(ratelimit.Result{}, false, errors.New("store down"))
+		r := setupRateLimitTestRouter(limiter, true)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestRateLimitIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should reject the client address before authentication", func(t *testing.T) {
+		limiter := new(MockRateLimiter)
+		limiter.On("AllowIP", "192.0.2.1").
+			Return(ratelimit.Result{Allowed: false, Limit: 100, RetryAfter: time.Second, Reset: time.Second}, true, nil)
+		authenticated := false
+		r := gin.New()
+		r.Use(middleware.ErrorHandler())
+		r.POST("/payments", middleware.RateLimitIP(limiter), func(ctx *gin.Context) {
+			authenticated = true
+		})
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		req.RemoteAddr = "192.0.2.1:4321"
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.False(t, authenticated)
+	})
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"payment-gateway/cmd/infra/logging"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestId accepts the caller's X-Request-ID when it is well formed and
+// generates one otherwise. The ID is echoed in the response and stored in the
+// request context so every log line of the request carries it.
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIdHeader)
+		if !validRequestId.MatchString(id) {
+			id = newRequestId()
+		}
+
+		ctx.Header(RequestIdHeader, id)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestId(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/infra/middleware"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRequestIdTestRouter(buf *bytes.Buffer) *gin.Engine {
+	logger, _ := logging.New(buf, "info", "json")
+
+	r := gin.New()
+	r.Use(middleware.RequestId(), middleware.AccessLog(logger), middleware.ErrorHandler())
+	r.GET("/orders/:id", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"request_id": logging.RequestId(ctx.Request.Context())})
+	})
+	return r
+}
+
+func TestRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should keep a well formed request id from the caller", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := setupRequestIdTestRouter(&buf)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		req.Header.Set(middleware.RequestIdHeader, "abc-123")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc-123", w.Header().Get(middleware.RequestIdHeader))
+		assert.JSONEq(t, `{"request_id":"abc-123"}`, w.Body.String())
+	})
+
+	t.Run("should generate a request id when missing or malformed", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := setupRequestIdTestRouter(&buf)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		req.Header.Set(middleware.RequestIdHeader, "bad id\n")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		id := w.Header().Get(middleware.RequestIdHeader)
+		assert.Len(t, id, 32)
+		assert.NotEqual(t, "bad id\n", id)
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should log one line per request with the request id", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := setupRequestIdTestRouter(&buf)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		req.Header.Set(middleware.RequestIdHeader, "abc-123")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 1)
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+		assert.Equal(t, "request completed", line["msg"])
+		assert.Equal(t, "abc-123", line["request_id"])
+		assert.Equal(t, "/orders/:id", line["route"])
+		assert.Equal(t, float64(http.StatusOK), line["status"])
+	})
+
+	t.Run("should log unmatched routes with their status", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := setupRequestIdTestRouter(&buf)
+		r.NoRoute(middleware.NotFound())
+
+		req, _ := http.NewRequest(http.MethodGet, "/unknown", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, buf.String(), `"status":404`)
+	})
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/usecases"
+	"payment-gateway/pkg/signature"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerifySignatureUseCase interface {
+	Execute(ctx context.Context, req usecases.SignedRequest) (*apikey.Entity, error)
+}
+
+// AuthenticateSigned accepts either a Bearer api key or an HMAC signed
+// request. Signed requests are detected by the presence of the signature header.
+// Their body is read into memory to be verified, so it is refused past
+// maxBodyBytes.
+func AuthenticateSigned(bearer AuthenticateUseCase, signed VerifySignatureUseCase, maxBodyBytes int64) gin.HandlerFunc {
+	authenticateBearer := Authenticate(bearer)
+
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(signature.HeaderSignature) == "" {
+			authenticateBearer(ctx)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(ctx.GetHeader(signature.HeaderTimestamp), 10, 64)
+		if err != nil {
+			unauthorized(ctx, exceptions.NewDomainError(exceptions.CodeInvalidSignature, "Signature timestamp must be a unix timestamp"))
+			return
+		}
+
+		var body []byte
+		if ctx.Request.Body != nil {
+			body, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodyBytes))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortWithError(ctx, exceptions.NewDomainError(exceptions.CodeRequestTooLarge, fmt.Sprintf("Signed request body exceeds %d bytes", maxBodyBytes)))
+				return
+			}
+			if err != nil {
+				abortWithError(ctx, exceptions.NewDomainError(exceptions.CodeInvalidRequest, "invalid request body"))
+				return
+			}
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		key, err := signed.Execute(ctx.Request.Context(), usecases.SignedRequest{
+			KeyId:     ctx.GetHeader(signature.HeaderKeyId),
+			Timestamp: timestamp,
+			Nonce:     ctx.GetHeader(signature.HeaderNonce),
+			Signature: ctx.GetHeader(signature.HeaderSignature),
+			Method:    ctx.Request.Method,
+			URI:       ctx.Request.URL.RequestURI(),
+			Body:      body,
+		})
+		if err != nil {
+			unauthorized(ctx, err)
+			return
+		}
+
+		SetApiKey(ctx, key)
+		ctx.Next()
+	}
+}
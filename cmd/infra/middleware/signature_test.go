@@ -0,0 +1,152 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/middleware"
+	"payment-gateway/cmd/usecases"
+	"payment-gateway/pkg/signature"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockVerifySignatureUseCase struct {
+	mock.Mock
+}
+
+func (m *MockVerifySignatureUseCase) Execute(_ context.Context, req usecases.SignedRequest) (*apikey.Entity, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func setupSignedTestRouter(bearer middleware.AuthenticateUseCase, signed middleware.VerifySignatureUseCase) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/payments", middleware.AuthenticateSigned(bearer, signed, 32), func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.JSON(http.StatusOK, gin.H{"merchant_id": middleware.MerchantId(ctx), "body": string(body)})
+	})
+	return r
+}
+
+func TestAuthenticateSigned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithScopes("write").Build()
+
+	t.Run("should authenticate signed request and keep body for the handler", func(t *testing.T) {
+		mockSigned := new(MockVerifySignatureUseCase)
+		mockSigned.On("Execute", mock.MatchedBy(func(req usecases.SignedRequest) bool {
+			return req.KeyId == "abc123" && req.Nonce == "nonce" && req.Timestamp == 1700000000 &&
+				req.Method == http.MethodPost && req.URI == "/payments" && string(req.Body) == `{"amount":1}`
+		})).Return(key, nil)
+		r := setupSignedTestRouter(nil, mockSigned)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(`{"amount":1}`))
+		req.Header.Set(signature.HeaderKeyId, "abc123")
+		req.Header.Set(signature.HeaderTimestamp, "1700000000")
+		req.Header.Set(signature.HeaderNonce, "nonce")
+		req.Header.Set(signature.HeaderSignature, "sig")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, float64(10), resp["merchant_id"])
+		assert.Equal(t, `{"amount":1}`, resp["body"])
+		mockSigned.AssertExpectations(t)
+	})
+
+	t.Run("should fall back to bearer authentication without signature", func(t *testing.T) {
+		mockBearer := new(MockAuthenticateUseCase)
+		mockBearer.On("Execute", "token").Return(key, nil)
+		r := setupSignedTestRouter(mockBearer, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString("{}"))
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockBearer.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 when timestamp is malformed", func(t *testing.T) {
+		r := setupSignedTestRouter(nil, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(signature.HeaderTimestamp, "yesterday")
+		req.Header.Set(signature.HeaderSignature, "sig")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should return 413 when the body exceeds the limit", func(t *testing.T) {
+		mockSigned := new(MockVerifySignatureUseCase)
+		r := setupSignedTestRouter(nil, mockSigned)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(`{"amount":1,"details":"way past the limit"}`))
+		req.Header.Set(signature.HeaderTimestamp, "1700000000")
+		req.Header.Set(signature.HeaderSignature, "sig")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "request_too_large", resp["code"])
+		mockSigned.AssertNotCalled(t, "Execute", mock.Anything)
+	})
+
+	t.Run("should return 401 when signature is rejected", func(t *testing.T) {
+		mockSigned := new(MockVerifySignatureUseCase)
+		mockSigned.On("Execute", mock.Anything).Return(nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, "Request nonce was already used"))
+		r := setupSignedTestRouter(nil, mockSigned)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(signature.HeaderTimestamp, "1700000000")
+		req.Header.Set(signature.HeaderSignature, "sig")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "invalid_signature", resp["code"])
+		assert.Equal(t, "Request nonce was already used", resp["detail"])
+	})
+
+	t.Run("should return 500 when verification fails unexpectedly", func(t *testing.T) {
+		mockSigned := new(MockVerifySignatureUseCase)
+		mockSigned.On("Execute", mock.Anything).Return(nil, assert.AnError)
+		r := setupSignedTestRouter(nil, mockSigned)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(signature.HeaderTimestamp, "1700000000")
+		req.Header.Set(signature.HeaderSignature, "sig")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
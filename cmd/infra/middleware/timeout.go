@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout bounds the request context with a deadline so use cases and DAOs
+// stop working once it expires. Routes are matched as "METHOD /path" using
+// the registered path; unmatched routes use the fallback.
+func Timeout(fallback time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout := fallback
+		if value, ok := routes[ctx.Request.Method+" "+ctx.FullPath()]; ok {
+			timeout = value
+		}
+
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Next()
+	}
+}
+
+// ParseRouteTimeouts reads route timeouts written as
+// "METHOD /path=duration;METHOD /path=duration".
+func ParseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route timeout %q, expected route=duration", entry)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid route timeout %q: %w", entry, err)
+		}
+
+		timeouts[strings.TrimSpace(route)] = timeout
+	}
+
+	return timeouts, nil
+}
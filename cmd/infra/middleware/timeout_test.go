@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTimeoutTestRouter(fallback time.Duration, routes map[string]time.Duration) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(), middleware.Timeout(fallback, routes))
+	handler := func(ctx *gin.Context) {
+		select {
+		case <-ctx.Request.Context().Done():
+			ctx.Error(ctx.Request.Context().Err())
+		case <-time.After(100 * time.Millisecond):
+			ctx.Status(http.StatusOK)
+		}
+	}
+	r.GET("/orders/:id", handler)
+	r.POST("/payments", handler)
+	return r
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should let requests finish before the deadline", func(t *testing.T) {
+		r := setupTimeoutTestRouter(time.Second, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should answer with a timeout problem when the deadline expires", func(t *testing.T) {
+		r := setupTimeoutTestRouter(10*time.Millisecond, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"request_timeout"`)
+	})
+
+	t.Run("should prefer the route timeout over the fallback", func(t *testing.T) {
+		r := setupTimeoutTestRouter(time.Second, map[string]time.Duration{"POST /payments": 10 * time.Millisecond})
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+		req, _ = http.NewRequest(http.MethodGet, "/orders/1", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestParseRouteTimeouts(t *testing.T) {
+	t.Run("should parse route timeouts", func(t *testing.T) {
+		timeouts, err := middleware.ParseRouteTimeouts("POST /payments=5s; GET /orders/:id=2s")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]time.Duration{
+			"POST /payments":  5 * time.Second,
+			"GET /orders/:id": 2 * time.Second,
+		}, timeouts)
+	})
+
+	t.Run("should reject malformed entries", func(t *testing.T) {
+		_, err := middleware.ParseRouteTimeouts("POST /payments")
+		assert.Error(t, err)
+
+		_, err = middleware.ParseRouteTimeouts("POST /payments=soon")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"payment-gateway/cmd/infra/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTracingTestRouter(recorder *tracetest.SpanRecorder, status int) *gin.Engine {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	r := gin.New()
+	r.Use(middleware.Tracing(provider, propagation.TraceContext{}))
+	r.POST("/payments/:id/process", func(ctx *gin.Context) {
+		ctx.Status(status)
+	})
+	return r
+}
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should continue the caller trace", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		r := setupTracingTestRouter(recorder, http.StatusOK)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments/1/process", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 1) {
+			assert.Equal(t, "POST /payments/:id/process", spans[0].Name())
+			assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+		}
+	})
+
+	t.Run("should mark server errors", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		r := setupTracingTestRouter(recorder, http.StatusInternalServerError)
+
+		req, _ := http.NewRequest(http.MethodPost, "/payments/1/process", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 1) {
+			assert.False(t, spans[0].Parent().IsValid())
+			assert.Equal(t, "Error", spans[0].Status().Code.String())
+		}
+	})
+}
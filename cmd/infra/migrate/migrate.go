@@ -0,0 +1,275 @@
+// Package migrate applies the versioned schema migrations embedded in the
+// binary and records them in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"payment-gateway/cmd/infra/db"
+	"sort"
+	"strings"
+	"time"
+)
+
+const lockName = "payment_gateway.schema_migrations"
+
+var ErrLocked = errors.New("another instance is running migrations")
+
+type Options struct {
+	// Dev also applies the migrations that seed development data.
+	Dev         bool
+	LockTimeout time.Duration
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	Dev       bool
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    db.Dialect
+	migrations []Migration
+	options    Options
+	logger     *slog.Logger
+}
+
+func New(pool *sql.DB, dialect db.Dialect, migrations []Migration, options Options, logger *slog.Logger) *Migrator {
+	return &Migrator{
+		db:         pool,
+		dialect:    dialect,
+		migrations: migrations,
+		options:    options,
+		logger:     logger,
+	}
+}
+
+// Up applies every pending migration in version order. Development seeds
+// are only applied when enabled in the options. Each migration is recorded
+// in the same transaction that applies it where the engine allows.
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	err = m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok || (migration.Dev && !m.options.Dev) {
+				continue
+			}
+			err := m.dialect.Atomic(ctx, conn, func() error {
+				if err := m.run(ctx, conn, migration, migration.up); err != nil {
+					return err
+				}
+				_, err := conn.ExecContext(ctx, m.dialect.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+					migration.Version, migration.Name, time.Now().UTC())
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, most recent first.
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = m.locked(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, m.dialect.Rebind("SELECT version FROM schema_migrations ORDER BY applied_at DESC, version DESC LIMIT ?"), steps)
+		if err != nil {
+			return err
+		}
+		var versions []int64
+		for rows.Next() {
+			var version int64
+			if err := rows.Scan(&version); err != nil {
+				rows.Close()
+				return err
+			}
+			versions = append(versions, version)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			migration, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("migration %d is applied but not known to this binary", version)
+			}
+			err := m.dialect.Atomic(ctx, conn, func() error {
+				if err := m.run(ctx, conn, migration, migration.down); err != nil {
+					return err
+				}
+				_, err := conn.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), version)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration, plus applied versions missing from
+// this binary, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if _, err := m.db.ExecContext(ctx, m.createTable()); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := done[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Dev:       migration.Dev,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+		delete(done, migration.Version)
+	}
+	for version, appliedAt := range done {
+		statuses = append(statuses, Status{Version: version, Name: "(unknown)", Applied: true, AppliedAt: appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+func (m *Migrator) Name() string {
+	return "migrations"
+}
+
+// Check fails while schema migrations are pending, so an instance is not
+// marked ready against an outdated schema. Development seeds are optional.
+func (m *Migrator) Check(ctx context.Context) error {
+	done, err := appliedVersions(ctx, m.db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := done[migration.Version]; !ok && !migration.Dev {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, script string) error {
+	for _, statement := range statements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	m.logger.Info("migration applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+
+	return nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// createTable keeps microseconds in applied_at so Down can order migrations
+// applied within the same second. SQLite already stores the full time, and
+// only reads columns declared exactly as TIMESTAMP back as times.
+func (m *Migrator) createTable() string {
+	timestamp := "TIMESTAMP(6)"
+	if m.dialect.Name() == db.DriverSQLite {
+		timestamp = "TIMESTAMP"
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at %-12s NOT NULL
+)`, timestamp)
+}
+
+// locked runs fn on a dedicated connection holding an advisory lock, so
+// instances starting together apply each migration exactly once.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	acquired, err := m.dialect.Lock(ctx, conn, lockName, m.options.LockTimeout)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return ErrLocked
+	}
+	defer func() {
+		err = errors.Join(err, m.dialect.Unlock(context.Background(), conn, lockName))
+	}()
+
+	if _, err := conn.ExecContext(ctx, m.createTable()); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, q querier) (map[int64]time.Time, error) {
+	rows, err := q.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,217 @@
+package migrate_test
+
+import (
+	"context"
+	"log/slog"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/migrate"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var source = fstest.MapFS{
+	"0001_create_orders.up.sql":     {Data: []byte("-- orders\nCREATE TABLE orders\n(\n    id BIGINT\n);\n\nCREATE INDEX idx ON orders (id);\n")},
+	"0001_create_orders.down.sql":   {Data: []byte("DROP TABLE orders;\n")},
+	"0002_create_payments.up.sql":   {Data: []byte("CREATE TABLE payments (id BIGINT);\n")},
+	"0002_create_payments.down.sql": {Data: []byte("DROP TABLE payments;\n")},
+	"dev/1000_seed_orders.up.sql":   {Data: []byte("INSERT INTO orders (id) VALUES (1);\n")},
+	"dev/1000_seed_orders.down.sql": {Data: []byte("DELETE FROM orders;\n")},
+}
+
+func newMigrator(t *testing.T, dev bool) (*migrate.Migrator, sqlmock.Sqlmock) {
+	return newDialectMigrator(t, dbclient.MySQL, dev)
+}
+
+func newDialectMigrator(t *testing.T, dialect dbclient.Dialect, dev bool) (*migrate.Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := migrate.Load(source)
+	assert.NoError(t, err)
+
+	options := migrate.Options{Dev: dev, LockTimeout: 1500 * time.Millisecond}
+	return migrate.New(db, dialect, migrations, options, slog.New(slog.DiscardHandler)), mock
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs("payment_gateway.schema_migrations", int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectRelease(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("DO RELEASE_LOCK(?)")).
+		WithArgs("payment_gateway.schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func appliedRows(versions ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range versions {
+		rows.AddRow(version, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	return rows
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Run("should apply pending migrations statement by statement", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(1))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE payments (id BIGINT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)")).
+			WithArgs(int64(2), "create_payments", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRelease(mock)
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, int64(2), applied[0].Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should split scripts and apply development seeds when enabled", func(t *testing.T) {
+		migrator, mock := newMigrator(t, true)
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(2))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE orders\n(\n    id BIGINT\n)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx ON orders (id)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(1), "create_orders", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO orders (id) VALUES (1)")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(1000), "seed_orders", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRelease(mock)
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, applied, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should stop at the first failing migration and release the lock", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(1))
+		mock.ExpectExec("CREATE TABLE payments").WillReturnError(assert.AnError)
+		expectRelease(mock)
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "migration 0002_create_payments")
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should apply each migration and record it in one transaction on postgres", func(t *testing.T) {
+		migrator, mock := newDialectMigrator(t, dbclient.Postgres, false)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock(hashtext($1))")).
+			WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(true))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows())
+		mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE TABLE orders").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE INDEX idx").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)")).
+			WithArgs(int64(1), "create_orders", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("COMMIT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE TABLE payments").WillReturnError(assert.AnError)
+		mock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Len(t, applied, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should fail when another instance holds the lock", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+			WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(0))
+
+		_, err := migrator.Up(context.Background())
+
+		assert.ErrorIs(t, err, migrate.ErrLocked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	t.Run("should revert the most recent migrations", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		expectLock(mock)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations ORDER BY applied_at DESC, version DESC LIMIT ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectExec("DROP TABLE payments").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRelease(mock)
+
+		reverted, err := migrator.Down(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "create_payments", reverted[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to revert migrations unknown to the binary", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(7))
+		expectRelease(mock)
+
+		_, err := migrator.Down(context.Background(), 1)
+
+		assert.EqualError(t, err, "migration 7 is applied but not known to this binary")
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	t.Run("should list applied, pending and unknown migrations", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(1, 5))
+
+		statuses, err := migrator.Status(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 4)
+		assert.True(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+		assert.Equal(t, int64(5), statuses[2].Version)
+		assert.Equal(t, "(unknown)", statuses[2].Name)
+		assert.True(t, statuses[3].Dev)
+	})
+}
+
+func TestMigrator_Check(t *testing.T) {
+	t.Run("should fail while schema migrations are pending", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(1))
+
+		err := migrator.Check(context.Background())
+
+		assert.EqualError(t, err, "pending migrations: 0002_create_payments")
+		assert.Equal(t, "migrations", migrator.Name())
+	})
+
+	t.Run("should not require development seeds", func(t *testing.T) {
+		migrator, mock := newMigrator(t, false)
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(1, 2))
+
+		assert.NoError(t, migrator.Check(context.Background()))
+	})
+}
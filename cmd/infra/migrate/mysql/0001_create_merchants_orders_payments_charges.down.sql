@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS charges;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS merchants;
@@ -0,0 +1,54 @@
+-- IF NOT EXISTS lets databases created by the former setup.sql adopt this baseline
+CREATE TABLE IF NOT EXISTS merchants
+(
+    id         BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name       VARCHAR(100) NOT NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS orders
+(
+    id          BIGINT PRIMARY KEY AUTO_INCREMENT,
+    merchant_id BIGINT,
+    status      VARCHAR(50)    NOT NULL,
+    amount      DECIMAL(10, 2) NOT NULL,
+    created_at  DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_orders_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+
+CREATE TABLE IF NOT EXISTS payments
+(
+    id           BIGINT PRIMARY KEY AUTO_INCREMENT,
+    merchant_id  BIGINT,
+    status       VARCHAR(50)    NOT NULL,
+    order_id     BIGINT         NOT NULL,
+    payment_type VARCHAR(50)    NOT NULL,
+    amount       DECIMAL(10, 2) NOT NULL,
+    details      VARCHAR(200),
+    created_at   DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_payments_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_payments_order
+        FOREIGN KEY (order_id) REFERENCES orders (id)
+            ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS charges
+(
+    id         BIGINT PRIMARY KEY AUTO_INCREMENT,
+    amount     DECIMAL(10, 2) NOT NULL,
+    category   VARCHAR(50)    NOT NULL,
+    payment_id BIGINT         NOT NULL,
+    created_at DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_charges_payment
+        FOREIGN KEY (payment_id) REFERENCES payments (id)
+            ON DELETE CASCADE
+);
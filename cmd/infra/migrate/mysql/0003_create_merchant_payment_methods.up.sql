@@ -0,0 +1,12 @@
+-- Per-merchant overrides; payment methods without a row are enabled
+CREATE TABLE IF NOT EXISTS merchant_payment_methods
+(
+    merchant_id BIGINT      NOT NULL,
+    code        VARCHAR(50) NOT NULL,
+    enabled     BOOLEAN     NOT NULL,
+
+    PRIMARY KEY (merchant_id, code),
+    CONSTRAINT fk_merchant_payment_methods_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+            ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- Buckets shared by instances when RATE_LIMIT_STORE=database
+CREATE TABLE IF NOT EXISTS rate_limit_buckets
+(
+    bucket_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    tokens     DOUBLE       NOT NULL,
+    updated_at DATETIME(6)  NOT NULL
+);
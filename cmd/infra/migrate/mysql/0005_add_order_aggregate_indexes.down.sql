@@ -0,0 +1,2 @@
+DROP INDEX idx_charges_payment_category ON charges;
+DROP INDEX idx_payments_order_status ON payments;
@@ -0,0 +1,4 @@
+-- Covering indexes for the per-order sums of payments, by status and type, and
+-- of charges
+CREATE INDEX idx_payments_order_status ON payments (order_id, status, payment_type, amount);
+CREATE INDEX idx_charges_payment_category ON charges (payment_id, category, amount);
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN refunded_amount,
+    DROP COLUMN charges_amount,
+    DROP COLUMN paid_amount;
@@ -0,0 +1,2 @@
+DROP INDEX idx_payments_merchant_created_at ON payments;
+DROP INDEX idx_payments_merchant_amount ON payments;
@@ -0,0 +1,5 @@
+-- Keyset pagination of the payment search, one index per sort order. The
+-- search always filters on the merchant, so they lead with it; InnoDB
+-- appends the primary key to them, which breaks ties on id
+CREATE INDEX idx_payments_merchant_amount ON payments (merchant_id, amount);
+CREATE INDEX idx_payments_merchant_created_at ON payments (merchant_id, created_at);
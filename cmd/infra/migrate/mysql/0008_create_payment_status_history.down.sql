@@ -0,0 +1,3 @@
+DROP INDEX idx_charges_created_at ON charges;
+
+DROP TABLE IF EXISTS payment_status_history;
@@ -0,0 +1,22 @@
+-- Append-only record of every status a payment moved to, so past balances can
+-- be rebuilt. Payments processed before this migration get a single row at
+-- their last update.
+CREATE TABLE IF NOT EXISTS payment_status_history
+(
+    id         BIGINT PRIMARY KEY AUTO_INCREMENT,
+    payment_id BIGINT      NOT NULL,
+    status     VARCHAR(50) NOT NULL,
+    created_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_payment_status_history_payment (payment_id, created_at),
+    CONSTRAINT fk_payment_status_history_payment
+        FOREIGN KEY (payment_id) REFERENCES payments (id)
+            ON DELETE CASCADE
+);
+
+INSERT INTO payment_status_history (payment_id, status, created_at)
+SELECT id, status, updated_at
+FROM payments
+WHERE status <> 'pending';
+
+CREATE INDEX idx_charges_created_at ON charges (created_at);
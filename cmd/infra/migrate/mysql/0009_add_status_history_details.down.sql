@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE payment_status_history
+    DROP COLUMN request_id,
+    DROP COLUMN actor,
+    DROP COLUMN reason,
+    DROP COLUMN from_status;
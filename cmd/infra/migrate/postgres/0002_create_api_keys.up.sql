@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id             BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    merchant_id    BIGINT       NOT NULL,
+    name           VARCHAR(100) NOT NULL,
+    prefix         VARCHAR(12)  NOT NULL,
+    key_hash       CHAR(64)     NOT NULL,
+    signing_secret VARCHAR(64)  NOT NULL,
+    scopes         VARCHAR(50)  NOT NULL,
+    mode           VARCHAR(10)  NOT NULL,
+    last_used_at   TIMESTAMP,
+    revoked_at     TIMESTAMP,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_api_keys_hash UNIQUE (key_hash),
+    CONSTRAINT uq_api_keys_prefix UNIQUE (prefix),
+    CONSTRAINT fk_api_keys_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_merchant_id ON api_keys (merchant_id);
+
+-- Nonces are kept for the signature tolerance window to reject replayed signed requests
+CREATE TABLE IF NOT EXISTS api_key_nonces
+(
+    api_key_id BIGINT      NOT NULL,
+    nonce      VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP   NOT NULL,
+
+    PRIMARY KEY (api_key_id, nonce),
+    CONSTRAINT fk_api_key_nonces_api_key
+        FOREIGN KEY (api_key_id) REFERENCES api_keys (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_nonces_expires_at ON api_key_nonces (expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS refunded_amount,
+    DROP COLUMN IF EXISTS charges_amount,
+    DROP COLUMN IF EXISTS paid_amount;
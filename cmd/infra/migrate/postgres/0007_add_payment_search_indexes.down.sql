@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payments_merchant_created_at;
+DROP INDEX IF EXISTS idx_payments_merchant_amount;
+DROP INDEX IF EXISTS idx_payments_merchant_id;
+
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_id ON payments (merchant_id);
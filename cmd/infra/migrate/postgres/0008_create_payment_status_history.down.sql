@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_charges_created_at;
+
+DROP TABLE IF EXISTS payment_status_history;
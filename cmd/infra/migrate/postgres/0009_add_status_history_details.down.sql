@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE payment_status_history
+    DROP COLUMN IF EXISTS request_id,
+    DROP COLUMN IF EXISTS actor,
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS from_status;
@@ -0,0 +1,5 @@
+DELETE FROM orders
+WHERE id <= 25;
+
+DELETE FROM merchants
+WHERE name = 'Development Merchant';
@@ -0,0 +1,41 @@
+-- Development merchant with an admin key
+-- (pgw_test_devbootstrap000000000000000000000000000000000000, signing secret pgw_sig_devbootstrap)
+INSERT INTO merchants (name)
+VALUES ('Development Merchant');
+
+INSERT INTO api_keys (merchant_id, name, prefix, key_hash, signing_secret, scopes, mode)
+SELECT id, 'bootstrap', 'devbootstrap', 'ac2f5e1fc857b348d1e30f7129ffde74fd047941d03f838ac993f6cc9aaff430',
+       'pgw_sig_devbootstrap', 'admin', 'test'
+FROM merchants
+WHERE name = 'Development Merchant';
+
+INSERT INTO orders (status, amount)
+VALUES ('pending', 120.50),
+       ('pending', 250.00),
+       ('pending', 89.99),
+       ('pending', 310.25),
+       ('pending', 45.00),
+       ('pending', 199.99),
+       ('pending', 540.75),
+       ('pending', 123.45),
+       ('pending', 79.90),
+       ('pending', 65.25),
+       ('pending', 300.00),
+       ('pending', 410.10),
+       ('pending', 145.50),
+       ('pending', 275.80),
+       ('pending', 88.88),
+       ('pending', 59.99),
+       ('pending', 160.60),
+       ('pending', 330.33),
+       ('pending', 200.00),
+       ('pending', 110.10),
+       ('pending', 500.00),
+       ('pending', 215.25),
+       ('pending', 39.99),
+       ('pending', 90.00),
+       ('pending', 149.49);
+
+UPDATE orders
+SET merchant_id = (SELECT id FROM merchants WHERE name = 'Development Merchant')
+WHERE id <= 25;
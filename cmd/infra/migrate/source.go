@@ -0,0 +1,136 @@
+package migrate
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed mysql postgres sqlite
+var embedded embed.FS
+
+// devDir holds the opt-in migrations that seed development data.
+const devDir = "dev"
+
+type Migration struct {
+	Version int64
+	Name    string
+	Dev     bool
+
+	up   string
+	down string
+}
+
+// Embedded returns the migrations compiled into the binary for the driver.
+func Embedded(driver string) ([]Migration, error) {
+	source, err := fs.Sub(embedded, driver)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(source, "."); err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	return Load(source)
+}
+
+// Load reads "<version>_<name>.up.sql" and "<version>_<name>.down.sql" pairs
+// from the root of source and from its dev directory, sorted by version.
+func Load(source fs.FS) ([]Migration, error) {
+	byVersion := map[int64]*Migration{}
+	err := fs.WalkDir(source, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		dir, file := path.Split(name)
+		dev := strings.TrimSuffix(dir, "/") == devDir
+		if dir != "" && !dev {
+			return fmt.Errorf("%s: migrations must be in the root or %s directory", name, devDir)
+		}
+
+		base, direction, ok := cut(file)
+		if !ok {
+			return fmt.Errorf("%s: expected <version>_<name>.up.sql or .down.sql", name)
+		}
+		versionText, description, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if err != nil || description == "" {
+			return fmt.Errorf("%s: expected <version>_<name>.up.sql or .down.sql", name)
+		}
+
+		content, err := fs.ReadFile(source, name)
+		if err != nil {
+			return err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: description, Dev: dev}
+			byVersion[version] = migration
+		}
+		if migration.Name != description || migration.Dev != dev {
+			return fmt.Errorf("%s: version %d is already used by %s", name, version, migration.Name)
+		}
+		if direction == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func cut(file string) (base string, direction string, ok bool) {
+	for _, direction := range []string{"up", "down"} {
+		if base, found := strings.CutSuffix(file, "."+direction+".sql"); found {
+			return base, direction, true
+		}
+	}
+
+	return "", "", false
+}
+
+// statements splits a script on semicolons that end a line, skipping
+// comment-only lines, since the driver runs one statement per call.
+func statements(script string) []string {
+	var result []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			result = append(result, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		result = append(result, rest)
+	}
+
+	return result
+}
@@ -0,0 +1,70 @@
+package migrate_test
+
+import (
+	"payment-gateway/cmd/infra/migrate"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("should load the embedded migrations in version order", func(t *testing.T) {
+		for _, driver := range []string{"mysql", "postgres", "sqlite"} {
+			migrations, err := migrate.Embedded(driver)
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, migrations)
+			assert.Equal(t, int64(1), migrations[0].Version)
+			assert.Equal(t, "create_merchants_orders_payments_charges", migrations[0].Name)
+			last := migrations[len(migrations)-1]
+			assert.True(t, last.Dev)
+			assert.Equal(t, "seed_development_data", last.Name)
+		}
+	})
+
+	t.Run("should ship the same versions for every driver", func(t *testing.T) {
+		mysql, _ := migrate.Embedded("mysql")
+		for _, driver := range []string{"postgres", "sqlite"} {
+			other, _ := migrate.Embedded(driver)
+
+			assert.Equal(t, len(mysql), len(other))
+			for i := range mysql {
+				assert.Equal(t, mysql[i].Version, other[i].Version)
+				assert.Equal(t, mysql[i].Name, other[i].Name)
+			}
+		}
+
+		_, err := migrate.Embedded("oracle")
+		assert.EqualError(t, err, `no migrations for driver "oracle"`)
+	})
+
+	t.Run("should require both directions", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{
+			"0001_create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id BIGINT);")},
+		})
+
+		assert.EqualError(t, err, "migration 1_create_orders needs both up and down scripts")
+	})
+
+	t.Run("should reject badly named files", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{"create_orders.up.sql": {}})
+		assert.EqualError(t, err, "create_orders.up.sql: expected <version>_<name>.up.sql or .down.sql")
+
+		_, err = migrate.Load(fstest.MapFS{"0001_create_orders.sql": {}})
+		assert.EqualError(t, err, "0001_create_orders.sql: expected <version>_<name>.up.sql or .down.sql")
+
+		_, err = migrate.Load(fstest.MapFS{"seed/0001_data.up.sql": {}})
+		assert.EqualError(t, err, "seed/0001_data.up.sql: migrations must be in the root or dev directory")
+	})
+
+	t.Run("should reject two migrations with the same version", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{
+			"0001_create_orders.up.sql":   {},
+			"0001_create_charges.up.sql":  {},
+			"0001_create_orders.down.sql": {},
+		})
+
+		assert.ErrorContains(t, err, "version 1 is already used by")
+	})
+}
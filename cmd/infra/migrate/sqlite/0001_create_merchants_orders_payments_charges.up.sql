@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS merchants
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(100) NOT NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS orders
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    merchant_id BIGINT,
+    status      VARCHAR(50)    NOT NULL,
+    amount      DECIMAL(10, 2) NOT NULL,
+    created_at  DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_orders_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+
+CREATE TABLE IF NOT EXISTS payments
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    merchant_id  BIGINT,
+    status       VARCHAR(50)    NOT NULL,
+    order_id     BIGINT         NOT NULL,
+    payment_type VARCHAR(50)    NOT NULL,
+    amount       DECIMAL(10, 2) NOT NULL,
+    details      VARCHAR(200),
+    created_at   DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_payments_merchant
+        FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_payments_order
+        FOREIGN KEY (order_id) REFERENCES orders (id)
+            ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS charges
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    amount     DECIMAL(10, 2) NOT NULL,
+    category   VARCHAR(50)    NOT NULL,
+    payment_id BIGINT         NOT NULL,
+    created_at DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_charges_payment
+        FOREIGN KEY (payment_id) REFERENCES payments (id)
+            ON DELETE CASCADE
+);
+
+-- Unlike MySQL, SQLite does not index foreign keys implicitly
+CREATE INDEX IF NOT EXISTS idx_orders_merchant_id ON orders (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_id ON payments (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id);
+CREATE INDEX IF NOT EXISTS idx_charges_payment_id ON charges (payment_id);
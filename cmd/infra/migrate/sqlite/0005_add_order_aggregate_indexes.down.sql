@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments (order_id);
+CREATE INDEX IF NOT EXISTS idx_charges_payment_id ON charges (payment_id);
+DROP INDEX IF EXISTS idx_charges_payment_category;
+DROP INDEX IF EXISTS idx_payments_order_status;
@@ -0,0 +1,7 @@
+-- Covering indexes for the per-order sums of payments, by status and type, and
+-- of charges; they lead with the foreign keys, so the single-column indexes
+-- become redundant
+CREATE INDEX IF NOT EXISTS idx_payments_order_status ON payments (order_id, status, payment_type, amount);
+CREATE INDEX IF NOT EXISTS idx_charges_payment_category ON charges (payment_id, category, amount);
+DROP INDEX IF EXISTS idx_payments_order_id;
+DROP INDEX IF EXISTS idx_charges_payment_id;
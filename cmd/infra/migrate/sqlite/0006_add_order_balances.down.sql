@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN refunded_amount;
+ALTER TABLE orders DROP COLUMN charges_amount;
+ALTER TABLE orders DROP COLUMN paid_amount;
@@ -0,0 +1,10 @@
+-- Running balances kept up to date as payments are processed, backfilled from
+-- the payments and charges already stored
+ALTER TABLE orders ADD COLUMN paid_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN charges_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+UPDATE orders
+SET paid_amount     = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = orders.id AND p.status = 'approved'), 0),
+    charges_amount  = COALESCE((SELECT SUM(c.amount) FROM charges c INNER JOIN payments p ON c.payment_id = p.id WHERE p.order_id = orders.id), 0),
+    refunded_amount = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = orders.id AND p.status = 'refunded'), 0);
@@ -0,0 +1,8 @@
+-- Keyset pagination of the payment search, one index per sort order with
+-- the id breaking ties. The search always filters on the merchant, so they
+-- lead with it
+DROP INDEX IF EXISTS idx_payments_merchant_id;
+
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_id ON payments (merchant_id, id);
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_amount ON payments (merchant_id, amount, id);
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_created_at ON payments (merchant_id, created_at, id);
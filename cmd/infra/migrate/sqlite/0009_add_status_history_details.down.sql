@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE payment_status_history DROP COLUMN request_id;
+ALTER TABLE payment_status_history DROP COLUMN actor;
+ALTER TABLE payment_status_history DROP COLUMN reason;
+ALTER TABLE payment_status_history DROP COLUMN from_status;
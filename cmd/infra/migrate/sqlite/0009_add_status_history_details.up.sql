@@ -0,0 +1,33 @@
+-- Who changed a status, from what and why. Rows written before this migration
+-- only recorded the new status; every payment transition then left pending.
+ALTER TABLE payment_status_history ADD COLUMN from_status VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE payment_status_history ADD COLUMN reason VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE payment_status_history ADD COLUMN actor VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE payment_status_history ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT '';
+
+UPDATE payment_status_history
+SET from_status = 'pending';
+
+-- Paid orders are backfilled with a single transition at their last update
+CREATE TABLE IF NOT EXISTS order_status_history
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id    BIGINT       NOT NULL,
+    from_status VARCHAR(50)  NOT NULL DEFAULT '',
+    status      VARCHAR(50)  NOT NULL,
+    reason      VARCHAR(200) NOT NULL DEFAULT '',
+    actor       VARCHAR(100) NOT NULL DEFAULT '',
+    request_id  VARCHAR(128) NOT NULL DEFAULT '',
+    created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_order_status_history_order
+        FOREIGN KEY (order_id) REFERENCES orders (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order ON order_status_history (order_id, created_at);
+
+INSERT INTO order_status_history (order_id, from_status, status, created_at)
+SELECT id, 'pending', status, updated_at
+FROM orders
+WHERE status = 'paid';
@@ -0,0 +1,29 @@
+-- Append-only log of every change the gateway makes to payments, orders,
+-- charges and api keys. Each entry hashes the previous one, and audit_chain
+-- holds the head of the chain, locked by every append so entries line up.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    seq          BIGINT PRIMARY KEY,
+    entity       VARCHAR(50)  NOT NULL,
+    entity_id    BIGINT       NOT NULL,
+    action       VARCHAR(20)  NOT NULL,
+    before_state TEXT         NOT NULL,
+    after_state  TEXT         NOT NULL,
+    actor        VARCHAR(100) NOT NULL DEFAULT '',
+    request_id   VARCHAR(128) NOT NULL DEFAULT '',
+    created_at   DATETIME     NOT NULL,
+    prev_hash    CHAR(64)     NOT NULL,
+    hash         CHAR(64)     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id);
+
+CREATE TABLE IF NOT EXISTS audit_chain
+(
+    id   INT PRIMARY KEY,
+    seq  BIGINT   NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+
+INSERT INTO audit_chain (id, seq, hash)
+VALUES (1, 0, '0000000000000000000000000000000000000000000000000000000000000000');
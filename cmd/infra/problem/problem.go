@@ -0,0 +1,121 @@
+// Package problem renders errors as RFC 7807 problem details.
+package problem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+)
+
+const (
+	ContentType = "application/problem+json"
+	typePrefix  = "/problems/"
+
+	internalErrorDetail = "An unexpected error occurred"
+
+	// StatusClientClosedRequest is the non-standard status used when the
+	// client goes away before the response is written.
+	StatusClientClosedRequest = 499
+)
+
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+type Details struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type entry struct {
+	status int
+	title  string
+}
+
+var catalog = map[string]entry{
+	exceptions.CodeInvalidRequest:   {http.StatusBadRequest, "Invalid request"},
+	exceptions.CodeValidationFailed: {http.StatusUnprocessableEntity, "Validation failed"},
+	exceptions.CodeRouteNotFound:    {http.StatusNotFound, "Route not found"},
+	exceptions.CodeRequestTooLarge:  {http.StatusRequestEntityTooLarge, "Request too large"},
+	exceptions.CodeInternalError:    {http.StatusInternalServerError, "Internal server error"},
+	exceptions.CodeRequestTimeout:   {http.StatusGatewayTimeout, "Request timed out"},
+	exceptions.CodeRequestCanceled:  {StatusClientClosedRequest, "Request canceled"},
+
+	exceptions.CodeOrderNotFound:      {http.StatusNotFound, "Order not found"},
+	exceptions.CodePaymentNotFound:    {http.StatusNotFound, "Payment not found"},
+	exceptions.CodeChargeNotFound:     {http.StatusNotFound, "Charge not found"},
+	exceptions.CodePaymentExceedsDebt: {http.StatusUnprocessableEntity, "Payment exceeds debt"},
+	exceptions.CodeInvalidTransition:  {http.StatusConflict, "Invalid status transition"},
+
+	exceptions.CodePaymentMethodDisabled:    {http.StatusUnprocessableEntity, "Payment method disabled"},
+	exceptions.CodePaymentMethodNotEligible: {http.StatusUnprocessableEntity, "Payment method not eligible"},
+
+	exceptions.CodeApiKeyNotFound:    {http.StatusNotFound, "Api key not found"},
+	exceptions.CodeApiKeyRevoked:     {http.StatusConflict, "Api key revoked"},
+	exceptions.CodeInvalidScope:      {http.StatusBadRequest, "Invalid api key scope"},
+	exceptions.CodeInvalidMode:       {http.StatusBadRequest, "Invalid api key mode"},
+	exceptions.CodeMissingApiKey:     {http.StatusUnauthorized, "Missing api key"},
+	exceptions.CodeInvalidApiKey:     {http.StatusUnauthorized, "Invalid api key"},
+	exceptions.CodeInsufficientScope: {http.StatusForbidden, "Insufficient scope"},
+	exceptions.CodeInvalidSignature:  {http.StatusUnauthorized, "Invalid request signature"},
+	exceptions.CodeRateLimited:       {http.StatusTooManyRequests, "Rate limit exceeded"},
+}
+
+// Status returns the HTTP status registered for a code. Unknown domain codes
+// are client errors.
+func Status(code string) int {
+	if e, ok := catalog[code]; ok {
+		return e.status
+	}
+
+	return http.StatusBadRequest
+}
+
+func FromError(err error, instance string) Details {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return build(exceptions.CodeRequestTimeout, "The request did not complete before its deadline", instance)
+	}
+	if errors.Is(err, context.Canceled) {
+		return build(exceptions.CodeRequestCanceled, "The request was canceled by the client", instance)
+	}
+
+	var ex *exceptions.DomainError
+	if !errors.As(err, &ex) {
+		return build(exceptions.CodeInternalError, internalErrorDetail, instance)
+	}
+
+	details := build(ex.Code(), ex.Error(), instance)
+	for _, field := range ex.Fields() {
+		details.Errors = append(details.Errors, FieldError{
+			Field:  field.Field,
+			Code:   field.Code,
+			Detail: field.Message,
+		})
+	}
+
+	return details
+}
+
+func build(code string, detail string, instance string) Details {
+	title := http.StatusText(Status(code))
+	if e, ok := catalog[code]; ok {
+		title = e.title
+	}
+
+	return Details{
+		Type:     typePrefix + code,
+		Title:    title,
+		Status:   Status(code),
+		Detail:   detail,
+		Instance: instance,
+		Code:     code,
+	}
+}
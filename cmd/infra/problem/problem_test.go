@@ -0,0 +1,74 @@
+package problem_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/problem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	t.Run("should map catalogued codes to their status", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, problem.Status(exceptions.CodePaymentExceedsDebt))
+		assert.Equal(t, http.StatusNotFound, problem.Status(exceptions.CodeOrderNotFound))
+		assert.Equal(t, http.StatusConflict, problem.Status(exceptions.CodeInvalidTransition))
+		assert.Equal(t, http.StatusTooManyRequests, problem.Status(exceptions.CodeRateLimited))
+	})
+
+	t.Run("should treat unknown codes as bad requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, problem.Status("unknown"))
+	})
+}
+
+func TestFromError(t *testing.T) {
+	t.Run("should describe a domain error", func(t *testing.T) {
+		err := exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, "Payment exceeds debt")
+
+		details := problem.FromError(err, "/payments")
+
+		assert.Equal(t, problem.Details{
+			Type:     "/problems/payment_exceeds_debt",
+			Title:    "Payment exceeds debt",
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   "Payment exceeds debt",
+			Instance: "/payments",
+			Code:     "payment_exceeds_debt",
+		}, details)
+	})
+
+	t.Run("should include field errors", func(t *testing.T) {
+		err := exceptions.NewValidationError(exceptions.FieldError{Field: "amount", Code: "gt", Message: "must be greater than 0"})
+
+		details := problem.FromError(err, "/payments")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, details.Status)
+		assert.Equal(t, []problem.FieldError{{Field: "amount", Code: "gt", Detail: "must be greater than 0"}}, details.Errors)
+	})
+
+	t.Run("should hide unexpected errors", func(t *testing.T) {
+		details := problem.FromError(errors.New("connection refused"), "/orders/1")
+
+		assert.Equal(t, http.StatusInternalServerError, details.Status)
+		assert.Equal(t, "internal_error", details.Code)
+		assert.NotContains(t, details.Detail, "connection refused")
+	})
+
+	t.Run("should report expired deadlines as timeouts", func(t *testing.T) {
+		details := problem.FromError(fmt.Errorf("query orders: %w", context.DeadlineExceeded), "/orders/1")
+
+		assert.Equal(t, http.StatusGatewayTimeout, details.Status)
+		assert.Equal(t, "request_timeout", details.Code)
+	})
+
+	t.Run("should report canceled requests", func(t *testing.T) {
+		details := problem.FromError(context.Canceled, "/orders/1")
+
+		assert.Equal(t, problem.StatusClientClosedRequest, details.Status)
+		assert.Equal(t, "request_canceled", details.Code)
+	})
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Bucket is a token bucket a request draws from.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+type Store interface {
+	// Take takes a token from every bucket when each of them has one, and
+	// none otherwise, returning the result of each bucket in order.
+	Take(ctx context.Context, buckets []Bucket, now time.Time) ([]Result, error)
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// RefillTime is how long an empty bucket takes to fill up again.
+func (l Limit) RefillTime() time.Duration {
+	if !l.Enabled() {
+		return 0
+	}
+
+	return secondsToDuration(float64(l.Burst) / l.Rate)
+}
+
+// ParseLimit reads a limit written as "rate:burst", e.g. "5:10" for five
+// requests per second with bursts of ten.
+func ParseLimit(value string) (Limit, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected rate:burst", value)
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid rate in %q: %w", value, err)
+	}
+
+	burst, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid burst in %q: %w", value, err)
+	}
+
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// refill returns the tokens of a bucket holding tokens since last.
+func refill(tokens float64, last time.Time, limit Limit, now time.Time) float64 {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+	}
+
+	return tokens
+}
+
+// takeAll consumes a token from each of the refilled buckets only when every
+// one of them has a token, so a request rejected by one bucket costs the
+// others nothing. A bucket's result is allowed when it had a token.
+func takeAll(tokens []float64, buckets []Bucket) []Result {
+	allowed := true
+	for _, held := range tokens {
+		allowed = allowed && held >= 1
+	}
+
+	results := make([]Result, len(buckets))
+	for i, bucket := range buckets {
+		limit := bucket.Limit
+		result := Result{Limit: limit.Burst, Allowed: tokens[i] >= 1}
+		if allowed {
+			tokens[i]--
+		} else if !result.Allowed {
+			result.RetryAfter = secondsToDuration((1 - tokens[i]) / limit.Rate)
+		}
+
+		result.Remaining = int(math.Floor(tokens[i]))
+		result.Reset = secondsToDuration((float64(limit.Burst) - tokens[i]) / limit.Rate)
+		results[i] = result
+	}
+
+	return results
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ParseRouteLimits reads route limits written as
+// "METHOD /path=rate:burst;METHOD /path=rate:burst".
+func ParseRouteLimits(value string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route rate limit %q, expected route=rate:burst", entry)
+		}
+
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		limits[strings.TrimSpace(route)] = limit
+	}
+
+	return limits, nil
+}
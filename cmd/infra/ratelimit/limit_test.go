@@ -0,0 +1,71 @@
+package ratelimit_test
+
+import (
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Run("should parse rate and burst", func(t *testing.T) {
+		limit, err := ratelimit.ParseLimit("2.5:10")
+
+		assert.NoError(t, err)
+		assert.Equal(t, ratelimit.Limit{Rate: 2.5, Burst: 10}, limit)
+		assert.True(t, limit.Enabled())
+	})
+
+	t.Run("should reject malformed values", func(t *testing.T) {
+		for _, value := range []string{"", "10", "a:10", "10:b", "1:2:3"} {
+			_, err := ratelimit.ParseLimit(value)
+			assert.Error(t, err, value)
+		}
+	})
+
+	t.Run("should treat zero rate as disabled", func(t *testing.T) {
+		limit, err := ratelimit.ParseLimit("0:10")
+
+		assert.NoError(t, err)
+		assert.False(t, limit.Enabled())
+	})
+}
+
+func TestPolicy_RefillTime(t *testing.T) {
+	t.Run("should take the longest refill time of the enabled limits", func(t *testing.T) {
+		policy := ratelimit.Policy{
+			PerIP:       ratelimit.Limit{Rate: 50, Burst: 100},
+			PerKey:      ratelimit.Limit{Rate: 0, Burst: 1000},
+			PerMerchant: ratelimit.Limit{Rate: 1, Burst: 3},
+			PerRoute:    map[string]ratelimit.Limit{"POST /payments": {Rate: 2, Burst: 10}},
+		}
+
+		assert.Equal(t, 5*time.Second, policy.RefillTime())
+	})
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	t.Run("should parse several routes", func(t *testing.T) {
+		limits, err := ratelimit.ParseRouteLimits("POST /payments=5:10; GET /orders/:id=20:40")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]ratelimit.Limit{
+			"POST /payments":  {Rate: 5, Burst: 10},
+			"GET /orders/:id": {Rate: 20, Burst: 40},
+		}, limits)
+	})
+
+	t.Run("should return empty map for empty value", func(t *testing.T) {
+		limits, err := ratelimit.ParseRouteLimits("")
+
+		assert.NoError(t, err)
+		assert.Empty(t, limits)
+	})
+
+	t.Run("should reject entries without limit", func(t *testing.T) {
+		_, err := ratelimit.ParseRouteLimits("POST /payments")
+
+		assert.Error(t, err)
+	})
+}
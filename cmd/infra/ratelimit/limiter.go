@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type Policy struct {
+	PerIP       Limit
+	PerKey      Limit
+	PerMerchant Limit
+	PerRoute    map[string]Limit
+}
+
+// RefillTime is the longest refill time among the limits of the policy, after
+// which any idle bucket is full.
+func (p Policy) RefillTime() time.Duration {
+	longest := max(p.PerIP.RefillTime(), p.PerKey.RefillTime(), p.PerMerchant.RefillTime())
+	for _, limit := range p.PerRoute {
+		longest = max(longest, limit.RefillTime())
+	}
+
+	return longest
+}
+
+type Limiter struct {
+	store  Store
+	policy Policy
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{
+		store:  store,
+		policy: policy,
+	}
+}
+
+// AllowIP takes a token from the bucket of the client address, checked
+// before the request is authenticated.
+func (l *Limiter) AllowIP(ctx context.Context, ip string, now time.Time) (Result, bool, error) {
+	return l.allow(ctx, []Bucket{{Key: "ip:" + ip, Limit: l.policy.PerIP}}, now)
+}
+
+// Allow takes a token from every bucket that applies to the request, or from
+// none when any of them is empty, and returns the most restrictive result.
+func (l *Limiter) Allow(ctx context.Context, keyId int64, merchantId int64, route string, now time.Time) (Result, bool, error) {
+	buckets := []Bucket{
+		{Key: "key:" + strconv.FormatInt(keyId, 10), Limit: l.policy.PerKey},
+		{Key: "merchant:" + strconv.FormatInt(merchantId, 10), Limit: l.policy.PerMerchant},
+	}
+	if limit, ok := l.policy.PerRoute[route]; ok {
+		buckets = append(buckets, Bucket{Key: "route:" + route + ":key:" + strconv.FormatInt(keyId, 10), Limit: limit})
+	}
+
+	return l.allow(ctx, buckets, now)
+}
+
+func (l *Limiter) allow(ctx context.Context, buckets []Bucket, now time.Time) (Result, bool, error) {
+	var enabled []Bucket
+	for _, bucket := range buckets {
+		if bucket.Limit.Enabled() {
+			enabled = append(enabled, bucket)
+		}
+	}
+	if len(enabled) == 0 {
+		return Result{Allowed: true}, false, nil
+	}
+
+	results, err := l.store.Take(ctx, enabled, now)
+	if err != nil {
+		return Result{}, false, err
+	}
+
+	strictest := results[0]
+	for _, result := range results[1:] {
+		if moreRestrictive(result, strictest) {
+			strictest = result
+		}
+	}
+
+	return strictest, true, nil
+}
+
+func moreRestrictive(candidate Result, current Result) bool {
+	if candidate.Allowed != current.Allowed {
+		return !candidate.Allowed
+	}
+
+	if !candidate.Allowed {
+		return candidate.RetryAfter > current.RetryAfter
+	}
+
+	return candidate.Remaining < current.Remaining
+}
@@ -0,0 +1,90 @@
+package ratelimit_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should report the most restrictive bucket", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{
+			PerKey:      ratelimit.Limit{Rate: 10, Burst: 10},
+			PerMerchant: ratelimit.Limit{Rate: 10, Burst: 100},
+			PerRoute:    map[string]ratelimit.Limit{"POST /payments": {Rate: 1, Burst: 2}},
+		})
+
+		result, limited, err := limiter.Allow(context.Background(), 1, 10, "POST /payments", now)
+
+		assert.NoError(t, err)
+		assert.True(t, limited)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("should reject when any bucket is empty", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{
+			PerKey:      ratelimit.Limit{Rate: 10, Burst: 10},
+			PerMerchant: ratelimit.Limit{Rate: 1, Burst: 1},
+		})
+		limiter.Allow(context.Background(), 1, 10, "GET /orders/:id", now)
+
+		result, _, err := limiter.Allow(context.Background(), 2, 10, "GET /orders/:id", now)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+	})
+
+	t.Run("should not take from the other buckets when one rejects", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{
+			PerKey:      ratelimit.Limit{Rate: 1, Burst: 3},
+			PerMerchant: ratelimit.Limit{Rate: 10, Burst: 10},
+			PerRoute:    map[string]ratelimit.Limit{"POST /payments": {Rate: 1, Burst: 1}},
+		})
+		limiter.Allow(context.Background(), 1, 10, "POST /payments", now)
+		for range 3 {
+			result, _, err := limiter.Allow(context.Background(), 1, 10, "POST /payments", now)
+			assert.NoError(t, err)
+			assert.False(t, result.Allowed)
+		}
+
+		result, _, err := limiter.Allow(context.Background(), 1, 10, "GET /orders/:id", now)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("should limit each client address", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{
+			PerIP: ratelimit.Limit{Rate: 1, Burst: 1},
+		})
+		limiter.AllowIP(context.Background(), "10.0.0.1", now)
+
+		rejected, limited, err := limiter.AllowIP(context.Background(), "10.0.0.1", now)
+		assert.NoError(t, err)
+		assert.True(t, limited)
+		assert.False(t, rejected.Allowed)
+
+		other, _, err := limiter.AllowIP(context.Background(), "10.0.0.2", now)
+		assert.NoError(t, err)
+		assert.True(t, other.Allowed)
+	})
+
+	t.Run("should not limit when every bucket is disabled", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{})
+
+		result, limited, err := limiter.Allow(context.Background(), 1, 10, "GET /orders/:id", now)
+
+		assert.NoError(t, err)
+		assert.False(t, limited)
+		assert.True(t, result.Allowed)
+	})
+}
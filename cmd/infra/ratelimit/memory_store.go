@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often Take looks for buckets to evict.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+// MemoryStore keeps the buckets of this instance. A bucket left idle until it
+// refills is evicted, since a new one starts full as well.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, buckets []Bucket, now time.Time) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := make([]*bucket, len(buckets))
+	tokens := make([]float64, len(buckets))
+	for i, requested := range buckets {
+		b, ok := s.buckets[requested.Key]
+		if !ok {
+			b = &bucket{tokens: float64(requested.Limit.Burst), last: now}
+			s.buckets[requested.Key] = b
+		}
+		stored[i] = b
+		tokens[i] = refill(b.tokens, b.last, requested.Limit, now)
+	}
+
+	results := takeAll(tokens, buckets)
+	for i, b := range stored {
+		b.tokens = tokens[i]
+		b.last = now
+		b.full = now.Add(secondsToDuration((float64(buckets[i].Limit.Burst) - tokens[i]) / buckets[i].Limit.Rate))
+	}
+
+	if !now.Before(s.nextSweep) {
+		s.evict(now)
+		s.nextSweep = now.Add(sweepInterval)
+	}
+
+	return results, nil
+}
+
+// Len returns how many buckets the store holds.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
+
+func (s *MemoryStore) evict(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	take := func(store *ratelimit.MemoryStore, key string, now time.Time) ratelimit.Result {
+		results, err := store.Take(context.Background(), []ratelimit.Bucket{{Key: key, Limit: limit}}, now)
+		assert.NoError(t, err)
+		return results[0]
+	}
+
+	t.Run("should allow up to burst and then reject", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		first := take(store, "key", now)
+		second := take(store, "key", now)
+		third := take(store, "key", now)
+
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+		assert.True(t, second.Allowed)
+		assert.Equal(t, 0, second.Remaining)
+		assert.False(t, third.Allowed)
+		assert.Equal(t, 2, third.Limit)
+		assert.Equal(t, time.Second, third.RetryAfter)
+		assert.Equal(t, 2*time.Second, third.Reset)
+	})
+
+	t.Run("should refill tokens over time", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		take(store, "key", now)
+		take(store, "key", now)
+
+		result := take(store, "key", now.Add(time.Second))
+
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should keep buckets independent", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		take(store, "a", now)
+		take(store, "a", now)
+
+		result := take(store, "b", now)
+
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should take from no bucket when any of them is empty", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		take(store, "empty", now)
+		take(store, "empty", now)
+
+		results, err := store.Take(context.Background(), []ratelimit.Bucket{
+			{Key: "full", Limit: limit},
+			{Key: "empty", Limit: limit},
+		}, now)
+
+		assert.NoError(t, err)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, 2, results[0].Remaining)
+		assert.False(t, results[1].Allowed)
+		assert.Equal(t, time.Second, results[1].RetryAfter)
+		assert.Equal(t, 1, take(store, "full", now).Remaining)
+	})
+
+	t.Run("should evict buckets once they have refilled", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		take(store, "idle", now)
+		take(store, "idle", now)
+		take(store, "busy", now.Add(time.Minute))
+
+		result := take(store, "busy", now.Add(time.Minute))
+
+		assert.Equal(t, 1, store.Len())
+		assert.Equal(t, 0, result.Remaining)
+		assert.False(t, take(store, "busy", now.Add(time.Minute)).Allowed)
+	})
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"payment-gateway/cmd/infra/db"
+	"sort"
+	"time"
+)
+
+// SQLStore keeps the buckets in the database so limits hold across
+// instances.
+type SQLStore struct {
+	db      *sql.DB
+	dialect db.Dialect
+}
+
+func NewSQLStore(pool *sql.DB, dialect db.Dialect) *SQLStore {
+	return &SQLStore{db: pool, dialect: dialect}
+}
+
+// Take locks the buckets in key order, so requests sharing some of them
+// cannot deadlock, and updates them all in one transaction.
+func (s *SQLStore) Take(ctx context.Context, buckets []Bucket, now time.Time) ([]Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return buckets[order[a]].Key < buckets[order[b]].Key })
+
+	insert := s.dialect.Rebind(s.dialect.InsertIgnore(`INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at) VALUES (?, ?, ?)`))
+	lock := s.dialect.Rebind(s.dialect.ForUpdate(`SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ?`))
+	tokens := make([]float64, len(buckets))
+	for _, i := range order {
+		bucket := buckets[i]
+		_, err = tx.ExecContext(ctx, insert, bucket.Key, float64(bucket.Limit.Burst), now)
+		if err != nil {
+			return nil, err
+		}
+
+		var last time.Time
+		err = tx.QueryRowContext(ctx, lock, bucket.Key).Scan(&tokens[i], &last)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = refill(tokens[i], last, bucket.Limit, now)
+	}
+
+	results := takeAll(tokens, buckets)
+
+	update := s.dialect.Rebind(`UPDATE rate_limit_buckets SET tokens = ?, updated_at = ? WHERE bucket_key = ?`)
+	for _, i := range order {
+		_, err = tx.ExecContext(ctx, update, tokens[i], now, buckets[i].Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, tx.Commit()
+}
+
+// PurgeIdle deletes the buckets last taken from before idleSince. Once idle
+// for the refill time of their limit a bucket is full again, the same as one
+// Take creates, so nothing is lost.
+func (s *SQLStore) PurgeIdle(ctx context.Context, idleSince time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.dialect.Rebind(`DELETE FROM rate_limit_buckets WHERE updated_at < ?`), idleSince)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
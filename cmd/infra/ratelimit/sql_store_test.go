@@ -0,0 +1,143 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/ratelimit"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLStore_Take(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Burst: 10}
+	now := time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC)
+
+	t.Run("should refill and consume a token inside a transaction", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT IGNORE INTO rate_limit_buckets").
+			WithArgs("key:1", float64(10), now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = \\? FOR UPDATE").
+			WithArgs("key:1").
+			WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(0.5, now.Add(-time.Second)))
+		mock.ExpectExec("UPDATE rate_limit_buckets SET tokens = \\?, updated_at = \\? WHERE bucket_key = \\?").
+			WithArgs(0.5, now, "key:1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		results, err := ratelimit.NewSQLStore(db, dbclient.MySQL).Take(context.Background(), []ratelimit.Bucket{{Key: "key:1", Limit: limit}}, now)
+
+		assert.NoError(t, err)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, 0, results[0].Remaining)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when the bucket cannot be read", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT IGNORE INTO rate_limit_buckets").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT tokens, updated_at FROM rate_limit_buckets").WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		_, err := ratelimit.NewSQLStore(db, dbclient.MySQL).Take(context.Background(), []ratelimit.Bucket{{Key: "key:1", Limit: limit}}, now)
+
+		assert.EqualError(t, err, "db error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should use conflict-free inserts and numbered placeholders on postgres", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING")).
+			WithArgs("key:1", float64(10), now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = $1 FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(10.0, now))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE bucket_key = $3")).
+			WithArgs(9.0, now, "key:1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		results, err := ratelimit.NewSQLStore(db, dbclient.Postgres).Take(context.Background(), []ratelimit.Bucket{{Key: "key:1", Limit: limit}}, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9, results[0].Remaining)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should lock the buckets in key order and leave them full when one is empty", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		for _, bucket := range []struct {
+			key    string
+			tokens float64
+		}{{"key:1", 5}, {"merchant:10", 0}} {
+			mock.ExpectExec("INSERT IGNORE INTO rate_limit_buckets").
+				WithArgs(bucket.key, float64(10), now).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = \\? FOR UPDATE").
+				WithArgs(bucket.key).
+				WillReturnRows(sqlmock.NewRows([]string{"tokens", "updated_at"}).AddRow(bucket.tokens, now))
+		}
+		mock.ExpectExec("UPDATE rate_limit_buckets").WithArgs(5.0, now, "key:1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE rate_limit_buckets").WithArgs(0.0, now, "merchant:10").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		results, err := ratelimit.NewSQLStore(db, dbclient.MySQL).Take(context.Background(), []ratelimit.Bucket{
+			{Key: "merchant:10", Limit: limit},
+			{Key: "key:1", Limit: limit},
+		}, now)
+
+		assert.NoError(t, err)
+		assert.False(t, results[0].Allowed)
+		assert.True(t, results[1].Allowed)
+		assert.Equal(t, 5, results[1].Remaining)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSQLStore_PurgeIdle(t *testing.T) {
+	idleSince := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should delete the buckets idle since the given time", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM rate_limit_buckets WHERE updated_at < $1")).
+			WithArgs(idleSince).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		purged, err := ratelimit.NewSQLStore(db, dbclient.Postgres).PurgeIdle(context.Background(), idleSince)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when the delete fails", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectExec("DELETE FROM rate_limit_buckets").WillReturnError(errors.New("db error"))
+
+		purged, err := ratelimit.NewSQLStore(db, dbclient.MySQL).PurgeIdle(context.Background(), idleSince)
+
+		assert.EqualError(t, err, "db error")
+		assert.Zero(t, purged)
+	})
+}
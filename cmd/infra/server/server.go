@@ -0,0 +1,75 @@
+// Package server runs the HTTP server until the process is asked to stop.
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Options control how the server listens and how long it may take to stop.
+// TLS is served when both CertFile and KeyFile are set. PreStopDelay keeps
+// serving after readiness fails, so load balancers that poll it stop routing
+// here before the listener closes.
+type Options struct {
+	ShutdownTimeout time.Duration
+	PreStopDelay    time.Duration
+	CertFile        string
+	KeyFile         string
+}
+
+type Lifecycle interface {
+	Start(ctx context.Context)
+	Drain()
+	Stop(ctx context.Context) error
+}
+
+// Run serves until ctx is canceled, then fails readiness and keeps serving for
+// the pre-stop delay. It then stops accepting new traffic, waits for
+// in-flight requests and finally stops the background workers, all within
+// the shutdown timeout.
+func Run(ctx context.Context, srv *http.Server, lifecycle Lifecycle, options Options, logger *slog.Logger) error {
+	shutdownTimeout := options.ShutdownTimeout
+	lifecycle.Start(ctx)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if options.CertFile != "" && options.KeyFile != "" {
+			logger.Info("server listening", slog.String("addr", srv.Addr), slog.Bool("tls", true))
+			serveErr <- srv.ListenAndServeTLS(options.CertFile, options.KeyFile)
+			return
+		}
+		logger.Info("server listening", slog.String("addr", srv.Addr), slog.Bool("tls", false))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			return errors.Join(err, lifecycle.Stop(stopCtx))
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", slog.Duration("pre_stop_delay", options.PreStopDelay), slog.Duration("timeout", shutdownTimeout))
+	lifecycle.Drain()
+	time.Sleep(options.PreStopDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		logger.Error("server did not drain in time", slog.String("error", err.Error()))
+	}
+
+	err = errors.Join(err, lifecycle.Stop(shutdownCtx))
+	logger.Info("server stopped")
+
+	return err
+}
@@ -0,0 +1,148 @@
+package server_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"payment-gateway/cmd/infra/server"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLifecycle struct {
+	mock.Mock
+}
+
+func (m *MockLifecycle) Start(_ context.Context) {
+	m.Called()
+}
+
+func (m *MockLifecycle) Drain() {
+	m.Called()
+}
+
+func (m *MockLifecycle) Stop(_ context.Context) error {
+	return m.Called().Error(0)
+}
+
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestRun(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	t.Run("should finish in-flight requests before stopping", func(t *testing.T) {
+		addr := freeAddr(t)
+		inFlight := make(chan struct{})
+		srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(inFlight)
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusCreated)
+		})}
+		lifecycle := new(MockLifecycle)
+		lifecycle.On("Start").Once()
+		lifecycle.On("Drain").Once()
+		lifecycle.On("Stop").Return(nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- server.Run(ctx, srv, lifecycle, server.Options{ShutdownTimeout: time.Second}, logger) }()
+
+		status := make(chan int, 1)
+		go func() {
+			var resp *http.Response
+			var err error
+			for i := 0; i < 100; i++ {
+				resp, err = http.Post("http://"+addr, "application/json", nil)
+				if err == nil {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			if err != nil {
+				status <- 0
+				return
+			}
+			resp.Body.Close()
+			status <- resp.StatusCode
+		}()
+
+		<-inFlight
+		cancel()
+
+		assert.Equal(t, http.StatusCreated, <-status)
+		assert.NoError(t, <-done)
+		lifecycle.AssertExpectations(t)
+	})
+
+	t.Run("should keep serving during the pre-stop delay after draining", func(t *testing.T) {
+		addr := freeAddr(t)
+		srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})}
+		drained := make(chan struct{})
+		lifecycle := new(MockLifecycle)
+		lifecycle.On("Start").Once()
+		lifecycle.On("Drain").Run(func(mock.Arguments) { close(drained) }).Once()
+		lifecycle.On("Stop").Return(nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		options := server.Options{ShutdownTimeout: time.Second, PreStopDelay: 200 * time.Millisecond}
+		go func() { done <- server.Run(ctx, srv, lifecycle, options, logger) }()
+		for i := 0; i < 100; i++ {
+			if conn, err := net.Dial("tcp", addr); err == nil {
+				conn.Close()
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		cancel()
+		<-drained
+		resp, err := http.Get("http://" + addr)
+
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		}
+		assert.NoError(t, <-done)
+		lifecycle.AssertExpectations(t)
+	})
+
+	t.Run("should stop the lifecycle when the listener fails", func(t *testing.T) {
+		listener, _ := net.Listen("tcp", "127.0.0.1:0")
+		defer listener.Close()
+		srv := &http.Server{Addr: listener.Addr().String(), Handler: http.NotFoundHandler()}
+		lifecycle := new(MockLifecycle)
+		lifecycle.On("Start").Once()
+		lifecycle.On("Stop").Return(nil).Once()
+
+		err := server.Run(context.Background(), srv, lifecycle, server.Options{ShutdownTimeout: time.Second}, logger)
+
+		assert.Error(t, err)
+		lifecycle.AssertNotCalled(t, "Drain")
+		lifecycle.AssertExpectations(t)
+	})
+
+	t.Run("should stop the lifecycle when the TLS certificate cannot be loaded", func(t *testing.T) {
+		srv := &http.Server{Addr: freeAddr(t), Handler: http.NotFoundHandler()}
+		lifecycle := new(MockLifecycle)
+		lifecycle.On("Start").Once()
+		lifecycle.On("Stop").Return(nil).Once()
+
+		options := server.Options{ShutdownTimeout: time.Second, CertFile: "missing.pem", KeyFile: "missing.key"}
+		err := server.Run(context.Background(), srv, lifecycle, options, logger)
+
+		assert.Error(t, err)
+		lifecycle.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"payment-gateway/cmd/infra/db"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	stringLiteral  = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+	numericLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	tableName      = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// Client opens a span for every statement. Only the sanitized statement is
+// recorded; bound arguments never leave the process.
+type Client struct {
+	client db.Client
+	tracer trace.Tracer
+	system string
+}
+
+func NewClient(client db.Client, provider trace.TracerProvider, system string) *Client {
+	return &Client{
+		client: client,
+		tracer: provider.Tracer("payment-gateway/cmd/infra/db"),
+		system: system,
+	}
+}
+
+func (c *Client) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := c.start(ctx, query)
+	defer span.End()
+
+	result, err := c.client.ExecContext(ctx, query, args...)
+	recordError(span, err)
+
+	return result, err
+}
+
+func (c *Client) QueryContext(ctx context.Context, query string, args ...any) (db.Rows, error) {
+	ctx, span := c.start(ctx, query)
+	defer span.End()
+
+	rows, err := c.client.QueryContext(ctx, query, args...)
+	recordError(span, err)
+
+	return rows, err
+}
+
+func (c *Client) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := Sanitize(query)
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+
+	name := operation
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemKey.String(c.system),
+		semconv.DBQueryText(statement),
+		semconv.DBOperationName(operation),
+	}
+	if match := tableName.FindStringSubmatch(statement); match != nil {
+		name = operation + " " + match[1]
+		attrs = append(attrs, semconv.DBCollectionName(match[1]))
+	}
+
+	return c.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// Sanitize collapses whitespace and replaces literals with placeholders so a
+// statement never carries data into a span.
+func Sanitize(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numericLiteral.ReplaceAllString(query, "?")
+
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
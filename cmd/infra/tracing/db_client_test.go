@@ -0,0 +1,60 @@
+package tracing_test
+
+import (
+	"context"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/infra/tracing"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func TestClient(t *testing.T) {
+	t.Run("should open a span per statement with its sanitized text", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT id, COALESCE\(merchant_id, 0\), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders`).
+			WithArgs(int64(7)).
+			WillReturnError(assert.AnError)
+
+		_, err := dao.NewOrderDao(tracing.NewClient(dbclient.NewTxClient(db), provider, "mysql"), dbclient.MySQL).FindById(context.Background(), 7)
+
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 1) {
+			assert.Equal(t, "SELECT orders", spans[0].Name())
+			assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+			attrs := attributes(spans[0])
+			assert.Equal(t, "mysql", attrs["db.system"])
+			assert.Equal(t, "SELECT", attrs["db.operation.name"])
+			assert.Equal(t, "orders", attrs["db.collection.name"])
+			assert.Equal(t, "SELECT id, COALESCE(merchant_id, ?), status, amount, paid_amount, charges_amount, refunded_amount, created_at, updated_at FROM orders WHERE id = ?", attrs["db.query.text"])
+		}
+	})
+}
+
+func TestSanitize(t *testing.T) {
+	t.Run("should replace literals and collapse whitespace", func(t *testing.T) {
+		query := "SELECT *\n\t FROM payments WHERE details = 'card 4111111111111111' AND amount > 10.5 AND id = ?"
+
+		assert.Equal(t, "SELECT * FROM payments WHERE details = ? AND amount > ? AND id = ?", tracing.Sanitize(query))
+	})
+}
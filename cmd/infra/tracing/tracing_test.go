@@ -0,0 +1,50 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"payment-gateway/cmd/infra/tracing"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("should not record spans when tracing is disabled", func(t *testing.T) {
+		provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterNone})
+		assert.NoError(t, err)
+
+		_, span := provider.Tracer("test").Start(context.Background(), "noop")
+		span.End()
+
+		assert.False(t, span.SpanContext().IsValid())
+		assert.NoError(t, provider.Shutdown(context.Background()))
+	})
+
+	t.Run("should write spans to a file for local debugging", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "traces.json")
+		provider, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName: "payment-gateway",
+			Exporter:    tracing.ExporterFile,
+			File:        file,
+			SampleRatio: 1,
+		})
+		assert.NoError(t, err)
+
+		_, span := provider.Tracer("test").Start(context.Background(), "ProcessPayment")
+		span.End()
+		assert.NoError(t, provider.Shutdown(context.Background()))
+
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), `"Name":"ProcessPayment"`)
+		assert.Contains(t, string(content), `"payment-gateway"`)
+	})
+
+	t.Run("should reject unknown exporters", func(t *testing.T) {
+		_, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"})
+
+		assert.Error(t, err)
+	})
+}
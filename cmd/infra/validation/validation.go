@@ -0,0 +1,139 @@
+// Package validation binds request bodies and query strings and turns binding
+// failures into validation errors listing every offending field.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/domain/paymentmethod"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	errInvalidBody  = "invalid request body"
+	errInvalidQuery = "invalid query string"
+)
+
+var register sync.Once
+
+// BindJSON decodes the request body into obj and validates its binding tags.
+func BindJSON(ctx *gin.Context, obj any) error {
+	register.Do(registerValidators)
+
+	return translate(ctx.ShouldBindJSON(obj), errInvalidBody)
+}
+
+// BindQuery decodes the query string into the form fields of obj and
+// validates their binding tags.
+func BindQuery(ctx *gin.Context, obj any) error {
+	register.Do(registerValidators)
+
+	return translate(ctx.ShouldBindQuery(obj), errInvalidQuery)
+}
+
+// translate turns err into a validation error when it names the offending
+// fields, or into an invalid request with reason otherwise.
+func translate(err error, reason string) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]exceptions.FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, exceptions.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: message(fe),
+			})
+		}
+
+		return exceptions.NewValidationError(fields...)
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return exceptions.NewValidationError(exceptions.FieldError{
+			Field:   typeError.Field,
+			Code:    "type",
+			Message: "must be a " + typeError.Type.String(),
+		})
+	}
+
+	return exceptions.NewDomainError(exceptions.CodeInvalidRequest, reason)
+}
+
+func registerValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(jsonName)
+	_ = v.RegisterValidation("max_decimals", maxDecimals)
+	_ = v.RegisterValidation("payment_type", paymentType)
+}
+
+// jsonName names fields as clients send them: by their json key, or by their
+// form key for query strings.
+func jsonName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+func maxDecimals(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	value := strconv.FormatFloat(fl.Field().Float(), 'f', -1, 64)
+	_, decimals, found := strings.Cut(value, ".")
+
+	return !found || len(decimals) <= limit
+}
+
+func paymentType(fl validator.FieldLevel) bool {
+	return paymentmethod.IsRegistered(fl.Field().String())
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "min":
+		return "must have at least " + fe.Param() + " items"
+	case "max":
+		return "must have at most " + fe.Param() + " characters"
+	case "max_decimals":
+		return fmt.Sprintf("must have at most %s decimal places", fe.Param())
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "payment_type":
+		return "must be one of " + strings.Join(paymentmethod.Codes(), ", ")
+	default:
+		return "is invalid"
+	}
+}
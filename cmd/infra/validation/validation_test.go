@@ -0,0 +1,120 @@
+package validation_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/cmd/infra/validation"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type paymentRequest struct {
+	OrderID     int64   `json:"order_id" binding:"required,gt=0"`
+	Amount      float64 `json:"amount" binding:"required,gt=0,max_decimals=2"`
+	PaymentType string  `json:"payment_type" binding:"required,payment_type"`
+}
+
+func bind(body string) (paymentRequest, error) {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	var request paymentRequest
+	err := validation.BindJSON(ctx, &request)
+	return request, err
+}
+
+func fields(t *testing.T, err error) map[string]string {
+	ex, ok := err.(*exceptions.DomainError)
+	if !assert.True(t, ok) {
+		return nil
+	}
+
+	result := map[string]string{}
+	for _, field := range ex.Fields() {
+		result[field.Field] = field.Code
+	}
+	return result
+}
+
+func TestBindJSON(t *testing.T) {
+	t.Run("should bind a valid request", func(t *testing.T) {
+		request, err := bind(`{"order_id": 1, "amount": 10.25, "payment_type": "CreditCard"}`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10.25, request.Amount)
+	})
+
+	t.Run("should report every invalid field at once", func(t *testing.T) {
+		_, err := bind(`{"order_id": 0, "amount": -5, "payment_type": "credit_card"}`)
+
+		assert.Equal(t, map[string]string{
+			"order_id":     "required",
+			"amount":       "gt",
+			"payment_type": "payment_type",
+		}, fields(t, err))
+	})
+
+	t.Run("should reject amounts with more than two decimals", func(t *testing.T) {
+		_, err := bind(`{"order_id": 1, "amount": 10.123, "payment_type": "Cash"}`)
+
+		assert.Equal(t, map[string]string{"amount": "max_decimals"}, fields(t, err))
+	})
+
+	t.Run("should report fields with the wrong json type", func(t *testing.T) {
+		_, err := bind(`{"order_id": "1", "amount": 10, "payment_type": "Cash"}`)
+
+		assert.Equal(t, map[string]string{"order_id": "type"}, fields(t, err))
+	})
+
+	t.Run("should return invalid request for malformed json", func(t *testing.T) {
+		_, err := bind(`{invalid json}`)
+
+		ex, ok := err.(*exceptions.DomainError)
+		assert.True(t, ok)
+		assert.Equal(t, exceptions.CodeInvalidRequest, ex.Code())
+	})
+}
+
+func TestBindQuery(t *testing.T) {
+	type searchRequest struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending approved"`
+		Limit  int    `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	}
+	bindQuery := func(query string) (searchRequest, error) {
+		gin.SetMode(gin.TestMode)
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+query, nil)
+
+		var request searchRequest
+		err := validation.BindQuery(ctx, &request)
+		return request, err
+	}
+
+	t.Run("should bind a valid query string", func(t *testing.T) {
+		request, err := bindQuery("status=approved&limit=10")
+
+		assert.NoError(t, err)
+		assert.Equal(t, searchRequest{Status: "approved", Limit: 10}, request)
+	})
+
+	t.Run("should name invalid parameters by their form key", func(t *testing.T) {
+		_, err := bindQuery("status=done&limit=500")
+
+		assert.Equal(t, map[string]string{"status": "oneof", "limit": "lte"}, fields(t, err))
+	})
+
+	t.Run("should return invalid request for unparsable values", func(t *testing.T) {
+		_, err := bindQuery("limit=ten")
+
+		ex, ok := err.(*exceptions.DomainError)
+		assert.True(t, ok)
+		assert.Equal(t, exceptions.CodeInvalidRequest, ex.Code())
+		assert.Equal(t, "invalid query string", ex.Error())
+	})
+}
@@ -0,0 +1,131 @@
+// Package worker runs periodic background jobs.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// staleAfter is how many intervals may pass without a successful run before
+// the worker reports itself unhealthy.
+const staleAfter = 3
+
+type Job func(ctx context.Context) error
+
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	done        chan struct{}
+	lastSuccess time.Time
+	lastErr     error
+}
+
+func New(name string, interval time.Duration, job Job, logger *slog.Logger) *Worker {
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+		logger:   logger.With(slog.String("worker", name)),
+	}
+}
+
+func (w *Worker) Name() string {
+	return w.name
+}
+
+// Start runs the job every interval until Stop is called or ctx ends.
+func (w *Worker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done != nil {
+		return
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	w.lastSuccess = time.Now()
+
+	go w.loop(ctx, w.done)
+}
+
+// Stop asks the worker to finish and waits for the current run to return or
+// for ctx to expire.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker %s did not stop: %w", w.name, ctx.Err())
+	}
+}
+
+// Check reports whether the worker is running and has succeeded recently.
+func (w *Worker) Check(_ context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done == nil {
+		return errors.New("not started")
+	}
+	select {
+	case <-w.done:
+		return errors.New("stopped")
+	default:
+	}
+
+	if time.Since(w.lastSuccess) > staleAfter*w.interval {
+		if w.lastErr != nil {
+			return fmt.Errorf("no successful run since %s: %w", w.lastSuccess.Format(time.RFC3339), w.lastErr)
+		}
+		return fmt.Errorf("no successful run since %s", w.lastSuccess.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func (w *Worker) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	err := w.job(ctx)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastErr = err
+	if err != nil {
+		w.logger.WarnContext(ctx, "worker run failed", slog.String("error", err.Error()))
+		return
+	}
+	w.lastSuccess = time.Now()
+}
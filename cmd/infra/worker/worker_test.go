@@ -0,0 +1,75 @@
+package worker_test
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/infra/worker"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	t.Run("should run the job every interval until stopped", func(t *testing.T) {
+		var runs atomic.Int32
+		w := worker.New("test", 5*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}, logger)
+
+		w.Start(context.Background())
+		assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+		assert.NoError(t, w.Check(context.Background()))
+
+		assert.NoError(t, w.Stop(context.Background()))
+		assert.EqualError(t, w.Check(context.Background()), "stopped")
+	})
+
+	t.Run("should be unhealthy before starting", func(t *testing.T) {
+		w := worker.New("test", time.Minute, func(ctx context.Context) error { return nil }, logger)
+
+		assert.EqualError(t, w.Check(context.Background()), "not started")
+		assert.NoError(t, w.Stop(context.Background()))
+	})
+
+	t.Run("should become unhealthy when the job keeps failing", func(t *testing.T) {
+		w := worker.New("test", 2*time.Millisecond, func(ctx context.Context) error {
+			return assert.AnError
+		}, logger)
+
+		w.Start(context.Background())
+		defer w.Stop(context.Background())
+
+		assert.Eventually(t, func() bool {
+			err := w.Check(context.Background())
+			return err != nil && assert.ErrorIs(t, err, assert.AnError)
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("should give up waiting when the job does not return in time", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		w := worker.New("test", time.Millisecond, func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil
+		}, logger)
+
+		w.Start(context.Background())
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, w.Stop(ctx), context.DeadlineExceeded)
+
+		close(release)
+		assert.NoError(t, w.Stop(context.Background()))
+	})
+}
@@ -1,17 +1,21 @@
 package testhelpers
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/domain/audit"
 	"payment-gateway/cmd/domain/charge"
 	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/domain/payment"
+	"time"
 )
 
 type MockPaymentDao struct {
 	mock.Mock
 }
 
-func (m *MockPaymentDao) FindById(id int64) (*payment.Entity, error) {
+func (m *MockPaymentDao) FindById(ctx context.Context, id int64) (*payment.Entity, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -19,15 +23,39 @@ func (m *MockPaymentDao) FindById(id int64) (*payment.Entity, error) {
 	return args.Get(0).(*payment.Entity), args.Error(1)
 }
 
-func (m *MockPaymentDao) FindByOrderId(id int64) ([]payment.Entity, error) {
+func (m *MockPaymentDao) FindByIdForUpdate(ctx context.Context, id int64) (*payment.Entity, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
+	return args.Get(0).(*payment.Entity), args.Error(1)
+}
+
+func (m *MockPaymentDao) FindByOrderId(ctx context.Context, id int64) ([]payment.Entity, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Entity), args.Error(1)
+}
+
+func (m *MockPaymentDao) FindByOrderIdAsOf(ctx context.Context, orderId int64, asOf time.Time) ([]payment.Entity, error) {
+	args := m.Called(orderId, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Entity), args.Error(1)
+}
+
+func (m *MockPaymentDao) Search(ctx context.Context, search payment.Search) ([]payment.Entity, error) {
+	args := m.Called(search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]payment.Entity), args.Error(1)
 }
 
-func (m *MockPaymentDao) Insert(pay *payment.Entity) (*payment.Entity, error) {
+func (m *MockPaymentDao) Insert(ctx context.Context, pay *payment.Entity) (*payment.Entity, error) {
 	args := m.Called(pay)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,7 +63,7 @@ func (m *MockPaymentDao) Insert(pay *payment.Entity) (*payment.Entity, error) {
 	return args.Get(0).(*payment.Entity), args.Error(1)
 }
 
-func (m *MockPaymentDao) Update(pay *payment.Entity) (*payment.Entity, error) {
+func (m *MockPaymentDao) Update(ctx context.Context, pay *payment.Entity) (*payment.Entity, error) {
 	args := m.Called(pay)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -43,11 +71,40 @@ func (m *MockPaymentDao) Update(pay *payment.Entity) (*payment.Entity, error) {
 	return args.Get(0).(*payment.Entity), args.Error(1)
 }
 
+func (m *MockPaymentDao) FindTransitions(ctx context.Context, paymentId int64) ([]payment.Transition, error) {
+	args := m.Called(paymentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Transition), args.Error(1)
+}
+
+func (m *MockPaymentDao) SumApprovedByOrder(ctx context.Context, orderId int64) (float64, error) {
+	args := m.Called(orderId)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockPaymentDao) SummarizeByOrder(ctx context.Context, orderId int64) ([]payment.Summary, error) {
+	args := m.Called(orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]payment.Summary), args.Error(1)
+}
+
 type MockOrderDao struct {
 	mock.Mock
 }
 
-func (m *MockOrderDao) FindById(id int64) (*order.Entity, error) {
+func (m *MockOrderDao) Insert(ctx context.Context, or *order.Entity) (*order.Entity, error) {
+	args := m.Called(or)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*order.Entity), args.Error(1)
+}
+
+func (m *MockOrderDao) FindById(ctx context.Context, id int64) (*order.Entity, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -55,7 +112,15 @@ func (m *MockOrderDao) FindById(id int64) (*order.Entity, error) {
 	return args.Get(0).(*order.Entity), args.Error(1)
 }
 
-func (m *MockOrderDao) Update(pay *order.Entity) (*order.Entity, error) {
+func (m *MockOrderDao) FindByIdForUpdate(ctx context.Context, id int64) (*order.Entity, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*order.Entity), args.Error(1)
+}
+
+func (m *MockOrderDao) Update(ctx context.Context, pay *order.Entity) (*order.Entity, error) {
 	args := m.Called(pay)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -63,11 +128,27 @@ func (m *MockOrderDao) Update(pay *order.Entity) (*order.Entity, error) {
 	return args.Get(0).(*order.Entity), args.Error(1)
 }
 
+func (m *MockOrderDao) FindTransitions(ctx context.Context, orderId int64) ([]order.Transition, error) {
+	args := m.Called(orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]order.Transition), args.Error(1)
+}
+
+func (m *MockOrderDao) FindBalanceDrift(ctx context.Context, afterId int64, limit int) ([]order.Drift, int64, error) {
+	args := m.Called(afterId, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]order.Drift), args.Get(1).(int64), args.Error(2)
+}
+
 type MockChargeDao struct {
 	mock.Mock
 }
 
-func (m *MockChargeDao) Insert(pay *charge.Entity) (*charge.Entity, error) {
+func (m *MockChargeDao) Insert(ctx context.Context, pay *charge.Entity) (*charge.Entity, error) {
 	args := m.Called(pay)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -75,10 +156,151 @@ func (m *MockChargeDao) Insert(pay *charge.Entity) (*charge.Entity, error) {
 	return args.Get(0).(*charge.Entity), args.Error(1)
 }
 
-func (m *MockChargeDao) FindByOrderId(id int64) ([]charge.Entity, error) {
+func (m *MockChargeDao) FindById(ctx context.Context, id int64) (*charge.Entity, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*charge.Entity), args.Error(1)
+}
+
+func (m *MockChargeDao) FindByOrderId(ctx context.Context, id int64) ([]charge.Entity, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]charge.Entity), args.Error(1)
 }
+
+func (m *MockChargeDao) SumChargesByOrder(ctx context.Context, orderId int64) (map[string]float64, error) {
+	args := m.Called(orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockChargeDao) SumChargesByOrderAsOf(ctx context.Context, orderId int64, asOf time.Time) (map[string]float64, error) {
+	args := m.Called(orderId, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+type MockApiKeyDao struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyDao) Insert(ctx context.Context, key *apikey.Entity) (*apikey.Entity, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) FindById(ctx context.Context, id int64) (*apikey.Entity, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) FindByHash(ctx context.Context, hash string) (*apikey.Entity, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) FindByPrefix(ctx context.Context, prefix string) (*apikey.Entity, error) {
+	args := m.Called(prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) FindByMerchantId(ctx context.Context, merchantId int64) ([]apikey.Entity, error) {
+	args := m.Called(merchantId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) Update(ctx context.Context, key *apikey.Entity) (*apikey.Entity, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.Entity), args.Error(1)
+}
+
+func (m *MockApiKeyDao) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+type MockNonceDao struct {
+	mock.Mock
+}
+
+func (m *MockNonceDao) Register(ctx context.Context, keyId int64, nonce string, expiresAt time.Time) (bool, error) {
+	args := m.Called(keyId, nonce, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNonceDao) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockPaymentMethodDao struct {
+	mock.Mock
+}
+
+func (m *MockPaymentMethodDao) FindSettingsByMerchantId(ctx context.Context, merchantId int64) (map[string]bool, error) {
+	args := m.Called(merchantId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+type MockAuditDao struct {
+	mock.Mock
+}
+
+func (m *MockAuditDao) Append(ctx context.Context, entry *audit.Entry) (*audit.Entry, error) {
+	args := m.Called(entry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditDao) FindAfter(ctx context.Context, seq int64, limit int) ([]audit.Entry, error) {
+	args := m.Called(seq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditDao) Head(ctx context.Context) (int64, string, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.String(1), args.Error(2)
+}
+
+type MockStateReader struct {
+	mock.Mock
+}
+
+func (m *MockStateReader) State(ctx context.Context, entity string, entityId int64) (string, bool, error) {
+	args := m.Called(entity, entityId)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
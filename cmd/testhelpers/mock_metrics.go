@@ -0,0 +1,41 @@
+package testhelpers
+
+import "github.com/stretchr/testify/mock"
+
+type MockPaymentMetrics struct {
+	mock.Mock
+}
+
+// NewNopPaymentMetrics accepts any metric without asserting on it.
+func NewNopPaymentMetrics() *MockPaymentMetrics {
+	m := new(MockPaymentMetrics)
+	m.On("PaymentCreated", mock.Anything).Maybe()
+	m.On("PaymentProcessed", mock.Anything, mock.Anything).Maybe()
+	m.On("ChargeCreated", mock.Anything, mock.Anything).Maybe()
+	m.On("OrderPaid").Maybe()
+	return m
+}
+
+func (m *MockPaymentMetrics) PaymentCreated(paymentType string) {
+	m.Called(paymentType)
+}
+
+func (m *MockPaymentMetrics) PaymentProcessed(paymentType string, status string) {
+	m.Called(paymentType, status)
+}
+
+func (m *MockPaymentMetrics) ChargeCreated(category string, amount float64) {
+	m.Called(category, amount)
+}
+
+func (m *MockPaymentMetrics) OrderPaid() {
+	m.Called()
+}
+
+type MockBalanceMetrics struct {
+	mock.Mock
+}
+
+func (m *MockBalanceMetrics) BalanceDrift(orders int) {
+	m.Called(orders)
+}
@@ -0,0 +1,29 @@
+package testhelpers
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactor runs the function it receives directly. The configured error
+// stands for a failed commit.
+type MockTransactor struct {
+	mock.Mock
+}
+
+// NewNopTransactor accepts any transaction without asserting on it.
+func NewNopTransactor() *MockTransactor {
+	m := new(MockTransactor)
+	m.On("Transaction").Return(nil).Maybe()
+	return m
+}
+
+func (m *MockTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	args := m.Called()
+	if err != nil {
+		return err
+	}
+	return args.Error(0)
+}
@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"time"
+)
+
+const errInvalidApiKey = "Invalid api key"
+
+type AuthenticateApiKey struct {
+	apiKeyDao apikey.Dao
+}
+
+func NewAuthenticateApiKey(apiKeyDao apikey.Dao) *AuthenticateApiKey {
+	return &AuthenticateApiKey{
+		apiKeyDao: apiKeyDao,
+	}
+}
+
+func (a *AuthenticateApiKey) Execute(ctx context.Context, token string) (_ *apikey.Entity, err error) {
+	ctx, span := startSpan(ctx, "AuthenticateApiKey")
+	defer func() { endSpan(span, err) }()
+
+	key, err := a.apiKeyDao.FindByHash(ctx, apikey.Hash(token))
+	if errors.Is(err, apikey.ErrNotFound) {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidApiKey, errInvalidApiKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.IsRevoked() {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidApiKey, errInvalidApiKey)
+	}
+
+	now := time.Now()
+	if key.UseIsStale(now) {
+		err = a.apiKeyDao.TouchLastUsed(ctx, key.Id(), now)
+		if err != nil {
+			return nil, err
+		}
+		key.SetLastUsedAt(now)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,97 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticateApiKey_Execute(t *testing.T) {
+	token := "pgw_test_secret"
+
+	t.Run("should authenticate active key and record usage", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build()
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(key, nil)
+		mockApiKeyDao.On("TouchLastUsed", int64(1), mock.Anything).Return(nil)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), result.MerchantId())
+		assert.NotZero(t, result.LastUsedAt())
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject unknown key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(nil, apikey.ErrNotFound)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.Equal(t, "Invalid api key", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject revoked key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithRevokedAt(time.Now()).Build()
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(key, nil)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.Equal(t, "Invalid api key", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when lookup fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(nil, assert.AnError)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when usage cannot be recorded", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).Build()
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(key, nil)
+		mockApiKeyDao.On("TouchLastUsed", int64(1), mock.Anything).Return(assert.AnError)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should not record the use of a key used within the last minute", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		lastUsedAt := time.Now().Add(-30 * time.Second)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithLastUsedAt(lastUsedAt).Build()
+		mockApiKeyDao.On("FindByHash", apikey.Hash(token)).Return(key, nil)
+
+		useCase := usecases.NewAuthenticateApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, lastUsedAt, result.LastUsedAt())
+		mockApiKeyDao.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/domain/order"
+	"sync"
+)
+
+// CheckOrderBalances recomputes the balance every order keeps from its
+// payments and charges and reports the orders that disagree. It never
+// rewrites a balance: drift points at a bug to be investigated.
+type CheckOrderBalances struct {
+	orderDao  order.Dao
+	batchSize int
+	logger    *slog.Logger
+	metrics   BalanceMetrics
+
+	mu      sync.Mutex
+	afterId int64
+	drifted int
+}
+
+func NewCheckOrderBalances(orderDao order.Dao, batchSize int, logger *slog.Logger, metrics BalanceMetrics) *CheckOrderBalances {
+	return &CheckOrderBalances{
+		orderDao:  orderDao,
+		batchSize: batchSize,
+		logger:    logger,
+		metrics:   metrics,
+	}
+}
+
+// Execute checks the next batch of orders after the last one the previous
+// call checked, and starts over once it reaches the last order. The drift
+// count is reported at the end of every full pass.
+func (c *CheckOrderBalances) Execute(ctx context.Context) (_ []order.Drift, err error) {
+	ctx, span := startSpan(ctx, "CheckOrderBalances")
+	defer func() { endSpan(span, err) }()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	drifts, next, err := c.orderDao.FindBalanceDrift(ctx, c.afterId, c.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		c.logger.WarnContext(ctx, "order balance drifted",
+			slog.Int64("order_id", drift.OrderId),
+			slog.Float64("stored_paid", drift.Stored.Paid),
+			slog.Float64("computed_paid", drift.Computed.Paid),
+			slog.Float64("stored_charges", drift.Stored.Charges),
+			slog.Float64("computed_charges", drift.Computed.Charges),
+			slog.Float64("stored_refunded", drift.Stored.Refunded),
+			slog.Float64("computed_refunded", drift.Computed.Refunded),
+		)
+	}
+	c.drifted += len(drifts)
+	c.afterId = next
+	if next == 0 {
+		c.metrics.BalanceDrift(c.drifted)
+		c.drifted = 0
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,72 @@
+package usecases_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckOrderBalances_Execute(t *testing.T) {
+	t.Run("should log every drifted order and count them at the end of the pass", func(t *testing.T) {
+		first := []order.Drift{{OrderId: 1, Stored: order.Balance{Paid: 100}, Computed: order.Balance{Paid: 150}}}
+		second := []order.Drift{{OrderId: 3, Stored: order.Balance{Charges: 3}, Computed: order.Balance{Charges: 4.5}}}
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockMetrics := new(testhelpers.MockBalanceMetrics)
+		mockOrderDao.On("FindBalanceDrift", int64(0), 2).Return(first, int64(2), nil).Once()
+		mockOrderDao.On("FindBalanceDrift", int64(2), 2).Return(second, int64(0), nil).Once()
+		mockMetrics.On("BalanceDrift", 2).Once()
+		var logs bytes.Buffer
+		useCase := usecases.NewCheckOrderBalances(mockOrderDao, 2, slog.New(slog.NewTextHandler(&logs, nil)), mockMetrics)
+
+		found, err := useCase.Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, first, found)
+		mockMetrics.AssertNotCalled(t, "BalanceDrift", mock.Anything)
+
+		found, err = useCase.Execute(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, second, found)
+
+		assert.Contains(t, logs.String(), "order_id=1 stored_paid=100 computed_paid=150")
+		assert.Contains(t, logs.String(), "order_id=3")
+		mockOrderDao.AssertExpectations(t)
+		mockMetrics.AssertExpectations(t)
+	})
+
+	t.Run("should start the next pass from the first order with a fresh count", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockMetrics := new(testhelpers.MockBalanceMetrics)
+		mockOrderDao.On("FindBalanceDrift", int64(0), 10).Return([]order.Drift{{OrderId: 1}}, int64(0), nil).Once()
+		mockOrderDao.On("FindBalanceDrift", int64(0), 10).Return([]order.Drift{}, int64(0), nil).Once()
+		mockMetrics.On("BalanceDrift", 1).Once()
+		mockMetrics.On("BalanceDrift", 0).Once()
+		useCase := usecases.NewCheckOrderBalances(mockOrderDao, 10, slog.New(slog.DiscardHandler), mockMetrics)
+
+		_, err := useCase.Execute(context.Background())
+		assert.NoError(t, err)
+		found, err := useCase.Execute(context.Background())
+		assert.NoError(t, err)
+
+		assert.Empty(t, found)
+		mockOrderDao.AssertExpectations(t)
+		mockMetrics.AssertExpectations(t)
+	})
+
+	t.Run("should return dao errors without touching the drift count", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockMetrics := new(testhelpers.MockBalanceMetrics)
+		mockOrderDao.On("FindBalanceDrift", int64(0), 10).Return(nil, int64(0), assert.AnError)
+
+		_, err := usecases.NewCheckOrderBalances(mockOrderDao, 10, slog.New(slog.DiscardHandler), mockMetrics).Execute(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		mockMetrics.AssertNotCalled(t, "BalanceDrift", mock.Anything)
+	})
+}
@@ -0,0 +1,33 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+)
+
+type CreateApiKey struct {
+	apiKeyDao apikey.Dao
+}
+
+func NewCreateApiKey(apiKeyDao apikey.Dao) *CreateApiKey {
+	return &CreateApiKey{
+		apiKeyDao: apiKeyDao,
+	}
+}
+
+func (c *CreateApiKey) Execute(ctx context.Context, merchantId int64, name string, scopes []string, mode string) (_ *apikey.Entity, _ string, err error) {
+	ctx, span := startSpan(ctx, "CreateApiKey")
+	defer func() { endSpan(span, err) }()
+
+	key, token, err := apikey.NewApiKey(merchantId, name, scopes, mode)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err = c.apiKeyDao.Insert(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
@@ -0,0 +1,52 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateApiKey_Execute(t *testing.T) {
+	t.Run("should create api key and return plaintext token once", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("Insert", mock.Anything).Return(apikey.NewApiKeyBuilder().WithId(1).Build(), nil)
+
+		useCase := usecases.NewCreateApiKey(mockApiKeyDao)
+		key, token, err := useCase.Execute(context.Background(), 1, "backend", []string{"read"}, "live")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), key.Id())
+		assert.NotEmpty(t, token)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should not persist invalid api key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+
+		useCase := usecases.NewCreateApiKey(mockApiKeyDao)
+		key, token, err := useCase.Execute(context.Background(), 1, "backend", []string{"root"}, "live")
+
+		assert.Equal(t, "Invalid api key scope", err.Error())
+		assert.Nil(t, key)
+		assert.Empty(t, token)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when insert fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("Insert", mock.Anything).Return(nil, assert.AnError)
+
+		useCase := usecases.NewCreateApiKey(mockApiKeyDao)
+		key, token, err := useCase.Execute(context.Background(), 1, "backend", []string{"read"}, "live")
+
+		assert.Error(t, err)
+		assert.Nil(t, key)
+		assert.Empty(t, token)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+}
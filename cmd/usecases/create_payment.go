@@ -1,43 +1,70 @@
 package usecases
 
 import (
+	"context"
+	"log/slog"
 	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/domain/paymentmethod"
 )
 
 type CreatePayment struct {
-	paymentDao payment.Dao
-	orderDao   order.Dao
+	paymentDao       payment.Dao
+	orderDao         order.Dao
+	paymentMethodDao paymentmethod.Dao
+	logger           *slog.Logger
+	metrics          PaymentMetrics
 }
 
-func NewCreatePayment(paymentDao payment.Dao, orderDao order.Dao) *CreatePayment {
+func NewCreatePayment(paymentDao payment.Dao, orderDao order.Dao, paymentMethodDao paymentmethod.Dao, logger *slog.Logger, metrics PaymentMetrics) *CreatePayment {
 	return &CreatePayment{
-		paymentDao: paymentDao,
-		orderDao:   orderDao,
+		paymentDao:       paymentDao,
+		orderDao:         orderDao,
+		paymentMethodDao: paymentMethodDao,
+		logger:           logger,
+		metrics:          metrics,
 	}
 }
 
-func (c *CreatePayment) Execute(orderId int64, amount float64, status string) (*payment.Entity, error) {
-	or, err := c.orderDao.FindById(orderId)
+func (c *CreatePayment) Execute(ctx context.Context, merchantId int64, orderId int64, amount float64, status string) (_ *payment.Entity, err error) {
+	ctx, span := startSpan(ctx, "CreatePayment")
+	defer func() { endSpan(span, err) }()
+
+	method, err := FindMerchantPaymentMethod(ctx, c.paymentMethodDao, merchantId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	err = method.CheckEligibility(amount)
 	if err != nil {
 		return nil, err
 	}
-	pay := payment.NewPayment(orderId, amount, status)
 
-	paidAmount, err := GetPaidAmount(c.paymentDao, or.Id())
+	or, err := FindMerchantOrder(ctx, c.orderDao, merchantId, orderId)
 	if err != nil {
 		return nil, err
 	}
+	pay := payment.NewPayment(orderId, amount, status)
+	pay.SetMerchantId(merchantId)
 
-	err = or.PreValidation(or.Amount()-paidAmount, pay.Amount())
+	err = or.PreValidation(pay.Amount())
 	if err != nil {
 		return nil, err
 	}
 
-	pay, err = c.paymentDao.Insert(pay)
+	pay, err = c.paymentDao.Insert(ctx, pay)
 	if err != nil {
 		return nil, err
 	}
 
+	c.metrics.PaymentCreated(pay.Type())
+	c.logger.InfoContext(ctx, "payment created",
+		slog.Int64("payment_id", pay.Id()),
+		slog.Int64("order_id", orderId),
+		slog.Int64("merchant_id", merchantId),
+		slog.String("payment_type", pay.Type()),
+		slog.Float64("amount", pay.Amount()),
+	)
+
 	return pay, nil
 }
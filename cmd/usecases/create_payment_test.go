@@ -1,6 +1,8 @@
 package usecases_test
 
 import (
+	"context"
+	"log/slog"
 	exceptions "payment-gateway/cmd/domain/err"
 	"payment-gateway/cmd/domain/order"
 	helpers_test "payment-gateway/cmd/testhelpers"
@@ -13,27 +15,33 @@ import (
 )
 
 func TestCreatePayment_Execute(t *testing.T) {
+	merchantID := int64(7)
 	orderID := int64(123)
 	amount := 100.5
-	paymentType := "credit_card"
+	paymentType := "CreditCard"
 	expectedPayment := payment.NewPayment(orderID, amount, paymentType)
-	expectedOrder := order.NewOrderBuilder().WithId(orderID).WithAmount(100.5).Build()
+	expectedOrder := order.NewOrderBuilder().WithId(orderID).WithMerchantId(merchantID).WithAmount(100.5).Build()
 	expectedPayment.SetId(1)
+	enabledMethods := func() *helpers_test.MockPaymentMethodDao {
+		mockPaymentMethodDao := new(helpers_test.MockPaymentMethodDao)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", merchantID).Return(map[string]bool{}, nil).Maybe()
+		return mockPaymentMethodDao
+	}
 
 	t.Run("should create payment successfully with no existing payments", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
-		var existingPayments []payment.Entity
-
 		mockPaymentDao.On("Insert", mock.Anything).Return(expectedPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
 		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPayment, result)
+		mockPaymentDao.AssertCalled(t, "Insert", mock.MatchedBy(func(pay *payment.Entity) bool {
+			return pay.MerchantId() == merchantID
+		}))
 		mockPaymentDao.AssertExpectations(t)
 		mockOrderDao.AssertExpectations(t)
 	})
@@ -41,16 +49,13 @@ func TestCreatePayment_Execute(t *testing.T) {
 	t.Run("should not create payment when order amount is exceeded", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		expectedOrder := order.NewOrderBuilder().WithId(orderID).WithAmount(10.5).Build()
-		expectedErr := exceptions.NewDomainError("Payment exceeds debt")
+		expectedOrder := order.NewOrderBuilder().WithId(orderID).WithMerchantId(merchantID).WithAmount(10.5).Build()
+		expectedErr := exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, "Payment exceeds debt")
 
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
 		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
 
 		assert.Equal(t, expectedErr, err)
 		assert.Nil(t, result)
@@ -61,16 +66,14 @@ func TestCreatePayment_Execute(t *testing.T) {
 	t.Run("should not create payment when order left debt is exceeded", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
-		existPay := payment.NewPaymentBuilder().WithOrderId(orderID).WithAmount(100.5).WithId(1).WithStatus("approved").Build()
-		existingPayments := []payment.Entity{*existPay}
+		paidOrder := order.NewOrderBuilder().WithId(orderID).WithMerchantId(merchantID).WithAmount(150).WithPaidAmount(100).Build()
 
-		expectedErr := exceptions.NewDomainError("Payment exceeds debt")
+		expectedErr := exceptions.NewDomainError(exceptions.CodePaymentExceedsDebt, "Payment exceeds debt")
 
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindById", mock.Anything).Return(paidOrder, nil)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
 
 		assert.Equal(t, expectedErr, err)
 		assert.Nil(t, result)
@@ -81,14 +84,11 @@ func TestCreatePayment_Execute(t *testing.T) {
 	t.Run("should return error when paymentDao fails", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
-		var existingPayments []payment.Entity
-
 		mockPaymentDao.On("Insert", mock.Anything).Return(nil, assert.AnError)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
 		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -96,33 +96,110 @@ func TestCreatePayment_Execute(t *testing.T) {
 		mockOrderDao.AssertExpectations(t)
 	})
 
-	t.Run("should return error when paymentDao fails retrieving existing payments", func(t *testing.T) {
+	t.Run("should return error when orderDao fails", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
 
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(nil, assert.AnError)
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindById", mock.Anything).Return(nil, assert.AnError)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		mockPaymentDao.AssertExpectations(t)
 		mockOrderDao.AssertExpectations(t)
 	})
-	t.Run("should return error when orderDao fails", func(t *testing.T) {
+
+	t.Run("should not create payment for an order of another merchant", func(t *testing.T) {
 		mockPaymentDao := new(helpers_test.MockPaymentDao)
 		mockOrderDao := new(helpers_test.MockOrderDao)
+		mockPaymentMethodDao := new(helpers_test.MockPaymentMethodDao)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(nil, assert.AnError)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", merchantID+1).Return(map[string]bool{}, nil)
+		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
 
-		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao)
-		result, err := useCase.Execute(orderID, amount, paymentType)
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, mockPaymentMethodDao, slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID+1, orderID, amount, paymentType)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, order.ErrNotFound)
 		assert.Nil(t, result)
-		mockPaymentDao.AssertExpectations(t)
+		mockPaymentDao.AssertNotCalled(t, "Insert", mock.Anything)
 		mockOrderDao.AssertExpectations(t)
 	})
+
+	t.Run("should propagate order not found", func(t *testing.T) {
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao := new(helpers_test.MockOrderDao)
+
+		mockOrderDao.On("FindById", int64(999999)).Return(nil, order.ErrNotFound)
+
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, 999999, amount, paymentType)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should reject unknown payment types before touching the database", func(t *testing.T) {
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao := new(helpers_test.MockOrderDao)
+
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, "credit_card")
+
+		var ex *exceptions.DomainError
+		assert.ErrorAs(t, err, &ex)
+		assert.Equal(t, exceptions.CodeValidationFailed, ex.Code())
+		assert.Nil(t, result)
+		mockOrderDao.AssertNotCalled(t, "FindById", mock.Anything)
+		mockPaymentDao.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should reject payment methods disabled for the merchant", func(t *testing.T) {
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao := new(helpers_test.MockOrderDao)
+		mockPaymentMethodDao := new(helpers_test.MockPaymentMethodDao)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", merchantID).Return(map[string]bool{paymentType: false}, nil)
+
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, mockPaymentMethodDao, slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
+
+		var ex *exceptions.DomainError
+		assert.ErrorAs(t, err, &ex)
+		assert.Equal(t, exceptions.CodePaymentMethodDisabled, ex.Code())
+		assert.Nil(t, result)
+		mockOrderDao.AssertNotCalled(t, "FindById", mock.Anything)
+	})
+
+	t.Run("should reject amounts outside the payment method ticket", func(t *testing.T) {
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao := new(helpers_test.MockOrderDao)
+
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), helpers_test.NewNopPaymentMetrics())
+		result, err := useCase.Execute(context.Background(), merchantID, orderID, 2, "CashSlip")
+
+		var ex *exceptions.DomainError
+		assert.ErrorAs(t, err, &ex)
+		assert.Equal(t, exceptions.CodePaymentMethodNotEligible, ex.Code())
+		assert.Equal(t, "Amount must be between 5.00 and 50000.00 for CashSlip", ex.Error())
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should count created payments by type", func(t *testing.T) {
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao := new(helpers_test.MockOrderDao)
+		mockMetrics := new(helpers_test.MockPaymentMetrics)
+		mockPaymentDao.On("Insert", mock.Anything).Return(expectedPayment, nil)
+		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
+		mockMetrics.On("PaymentCreated", paymentType).Once()
+
+		useCase := usecases.NewCreatePayment(mockPaymentDao, mockOrderDao, enabledMethods(), slog.New(slog.DiscardHandler), mockMetrics)
+		_, err := useCase.Execute(context.Background(), merchantID, orderID, amount, paymentType)
+
+		assert.NoError(t, err)
+		mockMetrics.AssertExpectations(t)
+	})
 }
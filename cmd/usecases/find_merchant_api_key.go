@@ -0,0 +1,22 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+)
+
+func FindMerchantApiKey(ctx context.Context, dao apikey.Dao, merchantId int64, keyId int64) (_ *apikey.Entity, err error) {
+	ctx, span := startSpan(ctx, "FindMerchantApiKey")
+	defer func() { endSpan(span, err) }()
+
+	key, err := dao.FindById(ctx, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.MerchantId() != merchantId {
+		return nil, apikey.ErrNotFound
+	}
+
+	return key, nil
+}
@@ -0,0 +1,38 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMerchantApiKey(t *testing.T) {
+	owned := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build()
+
+	for _, tt := range []struct {
+		name    string
+		found   *apikey.Entity
+		findErr error
+		want    *apikey.Entity
+		wantErr error
+	}{
+		{"should find key owned by merchant", owned, nil, owned, nil},
+		{"should not find key owned by another merchant", apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(20).Build(), nil, nil, apikey.ErrNotFound},
+		{"should not find missing key", nil, apikey.ErrNotFound, nil, apikey.ErrNotFound},
+		{"should return error when find fails", nil, assert.AnError, nil, assert.AnError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+			mockApiKeyDao.On("FindById", int64(1)).Return(tt.found, tt.findErr)
+
+			key, err := usecases.FindMerchantApiKey(context.Background(), mockApiKeyDao, 10, 1)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
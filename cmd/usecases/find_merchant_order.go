@@ -0,0 +1,22 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+)
+
+func FindMerchantOrder(ctx context.Context, dao order.Dao, merchantId int64, orderId int64) (_ *order.Entity, err error) {
+	ctx, span := startSpan(ctx, "FindMerchantOrder")
+	defer func() { endSpan(span, err) }()
+
+	or, err := dao.FindById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if or.MerchantId() != merchantId {
+		return nil, order.ErrNotFound
+	}
+
+	return or, nil
+}
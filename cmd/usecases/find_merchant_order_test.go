@@ -0,0 +1,38 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMerchantOrder(t *testing.T) {
+	owned := order.NewOrderBuilder().WithId(1).WithMerchantId(10).Build()
+
+	for _, tt := range []struct {
+		name    string
+		found   *order.Entity
+		findErr error
+		want    *order.Entity
+		wantErr error
+	}{
+		{"should find order owned by merchant", owned, nil, owned, nil},
+		{"should not find order owned by another merchant", order.NewOrderBuilder().WithId(1).WithMerchantId(20).Build(), nil, nil, order.ErrNotFound},
+		{"should not find order without merchant", order.NewOrderBuilder().WithId(1).Build(), nil, nil, order.ErrNotFound},
+		{"should return error when find fails", nil, assert.AnError, nil, assert.AnError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrderDao := new(testhelpers.MockOrderDao)
+			mockOrderDao.On("FindById", int64(1)).Return(tt.found, tt.findErr)
+
+			or, err := usecases.FindMerchantOrder(context.Background(), mockOrderDao, 10, 1)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, or)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+)
+
+func FindMerchantPayment(ctx context.Context, dao payment.Dao, merchantId int64, paymentId int64) (_ *payment.Entity, err error) {
+	ctx, span := startSpan(ctx, "FindMerchantPayment")
+	defer func() { endSpan(span, err) }()
+
+	pay, err := dao.FindById(ctx, paymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if pay.MerchantId() != merchantId {
+		return nil, payment.ErrNotFound
+	}
+
+	return pay, nil
+}
@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/paymentmethod"
+)
+
+func FindMerchantPaymentMethod(ctx context.Context, dao paymentmethod.Dao, merchantId int64, code string) (_ *paymentmethod.Entity, err error) {
+	ctx, span := startSpan(ctx, "FindMerchantPaymentMethod")
+	defer func() { endSpan(span, err) }()
+
+	method, err := paymentmethod.Find(code)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := dao.FindSettingsByMerchantId(ctx, merchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled, ok := settings[code]; ok {
+		method.SetEnabled(enabled)
+	}
+
+	return method, nil
+}
@@ -0,0 +1,38 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMerchantPayment(t *testing.T) {
+	owned := payment.NewPaymentBuilder().WithId(1).WithMerchantId(10).Build()
+
+	for _, tt := range []struct {
+		name    string
+		found   *payment.Entity
+		findErr error
+		want    *payment.Entity
+		wantErr error
+	}{
+		{"should find payment owned by merchant", owned, nil, owned, nil},
+		{"should not find payment owned by another merchant", payment.NewPaymentBuilder().WithId(1).WithMerchantId(20).Build(), nil, nil, payment.ErrNotFound},
+		{"should not find payment without merchant", payment.NewPaymentBuilder().WithId(1).Build(), nil, nil, payment.ErrNotFound},
+		{"should return error when find fails", nil, assert.AnError, nil, assert.AnError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPaymentDao := new(testhelpers.MockPaymentDao)
+			mockPaymentDao.On("FindById", int64(1)).Return(tt.found, tt.findErr)
+
+			pay, err := usecases.FindMerchantPayment(context.Background(), mockPaymentDao, 10, 1)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, pay)
+		})
+	}
+}
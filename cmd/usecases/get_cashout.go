@@ -1,9 +1,12 @@
 package usecases
 
 import (
+	"context"
+	"math"
 	"payment-gateway/cmd/domain/charge"
 	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/domain/payment"
+	"time"
 )
 
 type GetCashout struct {
@@ -13,51 +16,122 @@ type GetCashout struct {
 }
 
 type CashoutView struct {
-	OrderId       int64   `json:"-"`
-	CashedDebt    float64 `json:"cashed_debt"`
-	RemainingDebt float64 `json:"remaining_debt"`
-	Charges       float64 `json:"charges"`
-	IsPaid        bool    `json:"is_paid"`
+	OrderId           int64              `json:"-"`
+	CashedDebt        float64            `json:"cashed_debt"`
+	RemainingDebt     float64            `json:"remaining_debt"`
+	Charges           float64            `json:"charges"`
+	Refunded          float64            `json:"refunded"`
+	IsPaid            bool               `json:"is_paid"`
+	NetAmount         float64            `json:"net_amount"`
+	ChargesByCategory map[string]float64 `json:"charges_by_category"`
+	PaidByType        map[string]float64 `json:"paid_by_type"`
+	PaymentsByStatus  map[string]int     `json:"payments_by_status"`
 }
 
 type Accountable interface {
 	Amount() float64
 }
 
-func NewGetCashout(paymentDao payment.Dao, orderDao order.Dao, chargeDao charge.Dao) *GetCashout {
+func NewGetCashout(orderDao order.Dao, paymentDao payment.Dao, chargeDao charge.Dao) *GetCashout {
 	return &GetCashout{
-		paymentDao: paymentDao,
 		orderDao:   orderDao,
+		paymentDao: paymentDao,
 		chargeDao:  chargeDao,
 	}
 }
 
-func (c *GetCashout) Execute(orderId int64) (order.Entity, CashoutView, error) {
-	or, err := c.orderDao.FindById(orderId)
+// Execute reads the totals from the balance the order keeps up to date as
+// its payments are processed, and itemizes them from the payments and
+// charges. That takes three queries rather than one: the order has to be read
+// first to check its merchant, and each itemization is grouped by the DAO of
+// its table over the order's range of an index, so neither reads more than
+// the rows of the order.
+func (c *GetCashout) Execute(ctx context.Context, merchantId int64, orderId int64) (_ order.Entity, _ CashoutView, err error) {
+	ctx, span := startSpan(ctx, "GetCashout")
+	defer func() { endSpan(span, err) }()
+
+	or, err := FindMerchantOrder(ctx, c.orderDao, merchantId, orderId)
+	if err != nil {
+		return order.Entity{}, CashoutView{}, err
+	}
+
+	summaries, err := c.paymentDao.SummarizeByOrder(ctx, orderId)
 	if err != nil {
 		return order.Entity{}, CashoutView{}, err
 	}
 
-	paidAmount, err := GetPaidAmount(c.paymentDao, orderId)
+	charges, err := c.chargeDao.SumChargesByOrder(ctx, orderId)
 	if err != nil {
 		return order.Entity{}, CashoutView{}, err
 	}
 
-	charges, err := c.chargeDao.FindByOrderId(orderId)
+	return *or, newCashoutView(or, summaries, charges), nil
+}
+
+// ExecuteAsOf rebuilds the cashout as it was at asOf from the status history
+// of the payments and the charges, which are never updated. The order is not
+// found at an asOf before it was created.
+func (c *GetCashout) ExecuteAsOf(ctx context.Context, merchantId int64, orderId int64, asOf time.Time) (_ order.Entity, _ CashoutView, err error) {
+	ctx, span := startSpan(ctx, "GetCashoutAsOf")
+	defer func() { endSpan(span, err) }()
+
+	or, err := FindMerchantOrder(ctx, c.orderDao, merchantId, orderId)
 	if err != nil {
 		return order.Entity{}, CashoutView{}, err
 	}
+	if asOf.Before(or.CreatedAt()) {
+		return order.Entity{}, CashoutView{}, order.ErrNotFound
+	}
 
-	var totalCharges float64
-	for _, charge := range charges {
-		totalCharges += charge.Amount()
+	payments, err := c.paymentDao.FindByOrderIdAsOf(ctx, orderId, asOf)
+	if err != nil {
+		return order.Entity{}, CashoutView{}, err
 	}
 
-	return *or, CashoutView{
-		OrderId:       orderId,
-		CashedDebt:    paidAmount,
-		RemainingDebt: or.Amount() - paidAmount,
-		Charges:       totalCharges,
-		IsPaid:        paidAmount >= or.Amount(),
-	}, nil
+	charges, err := c.chargeDao.SumChargesByOrderAsOf(ctx, orderId, asOf)
+	if err != nil {
+		return order.Entity{}, CashoutView{}, err
+	}
+
+	var total float64
+	for _, amount := range charges {
+		total += amount
+	}
+	past := or.AsOf(payments, total)
+
+	return *past, newCashoutView(past, payment.Summarize(payments), charges), nil
+}
+
+func newCashoutView(or *order.Entity, summaries []payment.Summary, charges map[string]float64) CashoutView {
+	paidByType := map[string]float64{}
+	paymentsByStatus := map[string]int{}
+	for _, summary := range summaries {
+		paymentsByStatus[summary.Status] += summary.Count
+		if summary.Status == payment.StatusApproved {
+			paidByType[summary.Type] = cents(paidByType[summary.Type] + summary.Amount)
+		}
+	}
+	chargesByCategory := make(map[string]float64, len(charges))
+	for category, amount := range charges {
+		chargesByCategory[category] = cents(amount)
+	}
+
+	return CashoutView{
+		OrderId:           or.Id(),
+		CashedDebt:        or.PaidAmount(),
+		RemainingDebt:     or.RemainingDebt(),
+		Charges:           or.ChargesAmount(),
+		Refunded:          or.RefundedAmount(),
+		IsPaid:            or.PaidAmount() >= or.Amount(),
+		NetAmount:         or.NetAmount(),
+		ChargesByCategory: chargesByCategory,
+		PaidByType:        paidByType,
+		PaymentsByStatus:  paymentsByStatus,
+	}
+}
+
+// cents rounds away the binary noise the databases add summing amounts, so
+// the itemization adds up to the totals the order keeps rounded.
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
 }
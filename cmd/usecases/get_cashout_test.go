@@ -1,84 +1,202 @@
 package usecases_test
 
 import (
-	"payment-gateway/cmd/domain/charge"
+	"context"
 	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
 	helpers_test "payment-gateway/cmd/testhelpers"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
-	"payment-gateway/cmd/domain/payment"
 	"payment-gateway/cmd/usecases"
 )
 
 func TestGetCashout_Execute(t *testing.T) {
-	mockPaymentDao := new(helpers_test.MockPaymentDao)
 	mockOrderDao := new(helpers_test.MockOrderDao)
+	mockPaymentDao := new(helpers_test.MockPaymentDao)
 	mockChargeDao := new(helpers_test.MockChargeDao)
 
-	getCashoutUseCase := usecases.NewGetCashout(mockPaymentDao, mockOrderDao, mockChargeDao)
+	getCashoutUseCase := usecases.NewGetCashout(mockOrderDao, mockPaymentDao, mockChargeDao)
 
-	t.Run("should get cashout", func(t *testing.T) {
-		expectedOrder := order.NewOrderBuilder().WithId(1).WithAmount(100).Build()
+	t.Run("should get cashout from the order balance", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithAmount(100).
+			WithPaidAmount(20).
+			WithChargesAmount(10).
+			WithRefundedAmount(5).
+			Build()
 		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
-		mockPaymentDao.On("FindByOrderId", int64(1)).Return([]payment.Entity{
-			*payment.NewPaymentBuilder().WithStatus("approved").WithAmount(10).Build(),
-			*payment.NewPaymentBuilder().WithStatus("approved").WithAmount(10).Build(),
-		}, nil).Once()
-		mockChargeDao.On("FindByOrderId", int64(1)).Return([]charge.Entity{
-			*charge.NewChargeBuilder().WithAmount(5).Build(),
-			*charge.NewChargeBuilder().WithAmount(5).Build(),
-		}, nil).Once()
+		mockPaymentDao.On("SummarizeByOrder", int64(1)).Return([]payment.Summary{}, nil).Once()
+		mockChargeDao.On("SumChargesByOrder", int64(1)).Return(map[string]float64{}, nil).Once()
 
-		or, view, err := getCashoutUseCase.Execute(1)
+		or, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
 
 		assert.Equal(t, *expectedOrder, or)
 		assert.Equal(t, usecases.CashoutView{
-			OrderId:       1,
-			CashedDebt:    20,
-			RemainingDebt: 80,
-			Charges:       10,
-			IsPaid:        false,
+			OrderId:           1,
+			CashedDebt:        20,
+			RemainingDebt:     80,
+			Charges:           10,
+			Refunded:          5,
+			IsPaid:            false,
+			NetAmount:         10,
+			ChargesByCategory: map[string]float64{},
+			PaidByType:        map[string]float64{},
+			PaymentsByStatus:  map[string]int{},
 		}, view)
 		assert.Nil(t, err)
+		mockOrderDao.AssertExpectations(t)
+	})
+
+	t.Run("should itemize charges by category, paid amount by type and payments by status", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithAmount(300).WithPaidAmount(300).WithChargesAmount(50).Build()
+		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
+		mockPaymentDao.On("SummarizeByOrder", int64(1)).Return([]payment.Summary{
+			{Status: "approved", Type: "CashSlip", Count: 1, Amount: 200},
+			{Status: "approved", Type: "CreditCard", Count: 1, Amount: 100},
+			{Status: "pending", Type: "CreditCard", Count: 2, Amount: 40},
+			{Status: "reproved", Type: "CashSlip", Count: 1, Amount: 200},
+		}, nil).Once()
+		mockChargeDao.On("SumChargesByOrder", int64(1)).Return(map[string]float64{"financial_fee": 10, "process_fee": 40}, nil).Once()
+
+		_, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 250.0, view.NetAmount)
+		assert.Equal(t, map[string]float64{"financial_fee": 10, "process_fee": 40}, view.ChargesByCategory)
+		assert.Equal(t, map[string]float64{"CashSlip": 200, "CreditCard": 100}, view.PaidByType)
+		assert.Equal(t, map[string]int{"approved": 2, "pending": 2, "reproved": 1}, view.PaymentsByStatus)
+	})
+
+	t.Run("should round the itemization to cents", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(10).WithAmount(300).WithPaidAmount(0.3).WithChargesAmount(0.3).Build()
+		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
+		mockPaymentDao.On("SummarizeByOrder", int64(1)).Return([]payment.Summary{
+			{Status: "approved", Type: "CreditCard", Count: 2, Amount: 0.1 + 0.2},
+		}, nil).Once()
+		mockChargeDao.On("SumChargesByOrder", int64(1)).Return(map[string]float64{"financial_fee": 0.1 + 0.2}, nil).Once()
+
+		_, view, err := getCashoutUseCase.Execute(context.Background(), 10, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"financial_fee": view.Charges}, view.ChargesByCategory)
+		assert.Equal(t, map[string]float64{"CreditCard": view.CashedDebt}, view.PaidByType)
+	})
+
+	t.Run("should report a fully paid order", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithAmount(100).WithPaidAmount(100).Build()
+		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
+		mockPaymentDao.On("SummarizeByOrder", int64(1)).Return([]payment.Summary{}, nil).Once()
+		mockChargeDao.On("SumChargesByOrder", int64(1)).Return(map[string]float64{}, nil).Once()
+
+		_, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, view.RemainingDebt)
+		assert.True(t, view.IsPaid)
 	})
 
 	t.Run("should throw error when order not found", func(t *testing.T) {
-		expectedOrder := order.NewOrderBuilder().WithId(1).WithAmount(100).Build()
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithAmount(100).Build()
 		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, assert.AnError).Once()
 
-		or, view, err := getCashoutUseCase.Execute(1)
+		or, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
 
 		assert.Equal(t, order.Entity{}, or)
 		assert.Equal(t, usecases.CashoutView{}, view)
 		assert.Error(t, err)
 	})
 
-	t.Run("should thrown an error when payments find thrown error", func(t *testing.T) {
-		expectedOrder := order.NewOrderBuilder().WithId(1).WithAmount(100).Build()
+	t.Run("should throw error when the breakdown cannot be read", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithAmount(100).Build()
 		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
-		mockPaymentDao.On("FindByOrderId", int64(1)).Return([]payment.Entity{}, assert.AnError).Once()
+		mockPaymentDao.On("SummarizeByOrder", int64(1)).Return(nil, assert.AnError).Once()
 
-		or, view, err := getCashoutUseCase.Execute(1)
+		or, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
 
 		assert.Equal(t, order.Entity{}, or)
 		assert.Equal(t, usecases.CashoutView{}, view)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, assert.AnError)
 	})
 
-	t.Run("should thrown an error when charges find thrown error", func(t *testing.T) {
-		expectedOrder := order.NewOrderBuilder().WithId(1).WithAmount(100).Build()
+	t.Run("should not get cashout of an order of another merchant", func(t *testing.T) {
+		expectedOrder := order.NewOrderBuilder().WithId(1).WithMerchantId(8).WithAmount(100).Build()
 		mockOrderDao.On("FindById", int64(1)).Return(expectedOrder, nil).Once()
-		mockPaymentDao.On("FindByOrderId", int64(1)).Return([]payment.Entity{
-			*payment.NewPaymentBuilder().WithStatus("approved").WithAmount(10).Build(),
-			*payment.NewPaymentBuilder().WithStatus("approved").WithAmount(10).Build(),
+
+		or, view, err := getCashoutUseCase.Execute(context.Background(), 7, 1)
+
+		assert.Equal(t, order.Entity{}, or)
+		assert.Equal(t, usecases.CashoutView{}, view)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+	})
+}
+
+func TestGetCashout_ExecuteAsOf(t *testing.T) {
+	mockOrderDao := new(helpers_test.MockOrderDao)
+	mockPaymentDao := new(helpers_test.MockPaymentDao)
+	mockChargeDao := new(helpers_test.MockChargeDao)
+
+	getCashoutUseCase := usecases.NewGetCashout(mockOrderDao, mockPaymentDao, mockChargeDao)
+	asOf := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := asOf.Add(-24 * time.Hour)
+
+	t.Run("should rebuild the cashout from the payments and charges at the time", func(t *testing.T) {
+		current := order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithCreatedAt(createdAt).WithStatus("paid").WithAmount(300).WithPaidAmount(300).WithChargesAmount(50).Build()
+		mockOrderDao.On("FindById", int64(1)).Return(current, nil).Once()
+		mockPaymentDao.On("FindByOrderIdAsOf", int64(1), asOf).Return([]payment.Entity{
+			*payment.NewPaymentBuilder().WithId(1).WithAmount(100).WithType("CreditCard").WithStatus("approved").Build(),
+			*payment.NewPaymentBuilder().WithId(2).WithAmount(200).WithType("CashSlip").WithStatus("pending").Build(),
 		}, nil).Once()
-		mockChargeDao.On("FindByOrderId", int64(1)).Return([]charge.Entity{}, assert.AnError).Once()
+		mockChargeDao.On("SumChargesByOrderAsOf", int64(1), asOf).Return(map[string]float64{"financial_fee": 10}, nil).Once()
+
+		or, view, err := getCashoutUseCase.ExecuteAsOf(context.Background(), 7, 1, asOf)
 
-		or, view, err := getCashoutUseCase.Execute(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", or.Status())
+		assert.Equal(t, usecases.CashoutView{
+			OrderId:           1,
+			CashedDebt:        100,
+			RemainingDebt:     200,
+			Charges:           10,
+			IsPaid:            false,
+			NetAmount:         90,
+			ChargesByCategory: map[string]float64{"financial_fee": 10},
+			PaidByType:        map[string]float64{"CreditCard": 100},
+			PaymentsByStatus:  map[string]int{"approved": 1, "pending": 1},
+		}, view)
+		assert.Equal(t, 300.0, current.PaidAmount())
+	})
+
+	t.Run("should throw error when order not found", func(t *testing.T) {
+		mockOrderDao.On("FindById", int64(1)).Return(nil, order.ErrNotFound).Once()
+
+		or, view, err := getCashoutUseCase.ExecuteAsOf(context.Background(), 7, 1, asOf)
 
 		assert.Equal(t, order.Entity{}, or)
 		assert.Equal(t, usecases.CashoutView{}, view)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+	})
+
+	t.Run("should not find the order before it was created", func(t *testing.T) {
+		mockOrderDao := new(helpers_test.MockOrderDao)
+		mockPaymentDao := new(helpers_test.MockPaymentDao)
+		mockOrderDao.On("FindById", int64(1)).Return(order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithCreatedAt(asOf.Add(time.Second)).WithAmount(100).Build(), nil)
+
+		or, view, err := usecases.NewGetCashout(mockOrderDao, mockPaymentDao, mockChargeDao).ExecuteAsOf(context.Background(), 7, 1, asOf)
+
+		assert.Equal(t, order.Entity{}, or)
+		assert.Equal(t, usecases.CashoutView{}, view)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		mockPaymentDao.AssertNotCalled(t, "FindByOrderIdAsOf", int64(1), asOf)
+	})
+
+	t.Run("should throw error when the history cannot be read", func(t *testing.T) {
+		mockOrderDao.On("FindById", int64(1)).Return(order.NewOrderBuilder().WithId(1).WithMerchantId(7).WithCreatedAt(createdAt).WithAmount(100).Build(), nil).Once()
+		mockPaymentDao.On("FindByOrderIdAsOf", int64(1), asOf).Return(nil, assert.AnError).Once()
+
+		_, view, err := getCashoutUseCase.ExecuteAsOf(context.Background(), 7, 1, asOf)
+
+		assert.Equal(t, usecases.CashoutView{}, view)
+		assert.ErrorIs(t, err, assert.AnError)
 	})
 }
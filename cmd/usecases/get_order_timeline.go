@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+)
+
+type GetOrderTimeline struct {
+	orderDao order.Dao
+}
+
+func NewGetOrderTimeline(orderDao order.Dao) *GetOrderTimeline {
+	return &GetOrderTimeline{
+		orderDao: orderDao,
+	}
+}
+
+func (g *GetOrderTimeline) Execute(ctx context.Context, merchantId int64, orderId int64) (_ []order.Transition, err error) {
+	ctx, span := startSpan(ctx, "GetOrderTimeline")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := FindMerchantOrder(ctx, g.orderDao, merchantId, orderId); err != nil {
+		return nil, err
+	}
+
+	return g.orderDao.FindTransitions(ctx, orderId)
+}
@@ -0,0 +1,48 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrderTimeline_Execute(t *testing.T) {
+	t.Run("should list the status transitions of the order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		transitions := []order.Transition{{Id: 1, OrderId: 2, From: "pending", To: "paid", Actor: "api_key:abc123"}}
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(10).Build(), nil)
+		mockOrderDao.On("FindTransitions", int64(2)).Return(transitions, nil)
+
+		result, err := usecases.NewGetOrderTimeline(mockOrderDao).Execute(context.Background(), 10, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transitions, result)
+		mockOrderDao.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for a missing order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockOrderDao.On("FindById", int64(2)).Return(nil, order.ErrNotFound)
+
+		result, err := usecases.NewGetOrderTimeline(mockOrderDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockOrderDao.AssertNotCalled(t, "FindTransitions", int64(2))
+	})
+
+	t.Run("should return not found for another merchant's order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(20).Build(), nil)
+
+		result, err := usecases.NewGetOrderTimeline(mockOrderDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockOrderDao.AssertNotCalled(t, "FindTransitions", int64(2))
+	})
+}
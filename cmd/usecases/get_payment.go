@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+)
+
+type GetPayment struct {
+	paymentDao payment.Dao
+}
+
+func NewGetPayment(paymentDao payment.Dao) *GetPayment {
+	return &GetPayment{
+		paymentDao: paymentDao,
+	}
+}
+
+func (g *GetPayment) Execute(ctx context.Context, merchantId int64, paymentId int64) (_ *payment.Entity, err error) {
+	ctx, span := startSpan(ctx, "GetPayment")
+	defer func() { endSpan(span, err) }()
+
+	return FindMerchantPayment(ctx, g.paymentDao, merchantId, paymentId)
+}
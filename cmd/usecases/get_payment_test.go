@@ -0,0 +1,45 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPayment_Execute(t *testing.T) {
+	t.Run("should get the payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		expected := payment.NewPaymentBuilder().WithId(1).WithMerchantId(10).WithOrderId(2).WithAmount(10).Build()
+		mockPaymentDao.On("FindById", int64(1)).Return(expected, nil)
+
+		result, err := usecases.NewGetPayment(mockPaymentDao).Execute(context.Background(), 10, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for a missing payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockPaymentDao.On("FindById", int64(1)).Return(nil, payment.ErrNotFound)
+
+		result, err := usecases.NewGetPayment(mockPaymentDao).Execute(context.Background(), 10, 1)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
+	})
+	t.Run("should return not found for another merchant's payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		other := payment.NewPaymentBuilder().WithId(1).WithMerchantId(20).WithOrderId(2).WithAmount(10).Build()
+		mockPaymentDao.On("FindById", int64(1)).Return(other, nil)
+
+		result, err := usecases.NewGetPayment(mockPaymentDao).Execute(context.Background(), 10, 1)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+)
+
+type GetPaymentTimeline struct {
+	paymentDao payment.Dao
+}
+
+func NewGetPaymentTimeline(paymentDao payment.Dao) *GetPaymentTimeline {
+	return &GetPaymentTimeline{
+		paymentDao: paymentDao,
+	}
+}
+
+func (g *GetPaymentTimeline) Execute(ctx context.Context, merchantId int64, paymentId int64) (_ []payment.Transition, err error) {
+	ctx, span := startSpan(ctx, "GetPaymentTimeline")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := FindMerchantPayment(ctx, g.paymentDao, merchantId, paymentId); err != nil {
+		return nil, err
+	}
+
+	return g.paymentDao.FindTransitions(ctx, paymentId)
+}
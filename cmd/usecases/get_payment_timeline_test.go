@@ -0,0 +1,48 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPaymentTimeline_Execute(t *testing.T) {
+	t.Run("should list the status transitions of the payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		transitions := []payment.Transition{{Id: 1, PaymentId: 3, From: "pending", To: "approved", RequestId: "req-1"}}
+		mockPaymentDao.On("FindById", int64(3)).Return(payment.NewPaymentBuilder().WithId(3).WithMerchantId(10).Build(), nil)
+		mockPaymentDao.On("FindTransitions", int64(3)).Return(transitions, nil)
+
+		result, err := usecases.NewGetPaymentTimeline(mockPaymentDao).Execute(context.Background(), 10, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transitions, result)
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for a missing payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockPaymentDao.On("FindById", int64(3)).Return(nil, payment.ErrNotFound)
+
+		result, err := usecases.NewGetPaymentTimeline(mockPaymentDao).Execute(context.Background(), 10, 3)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "FindTransitions", int64(3))
+	})
+
+	t.Run("should return not found for another merchant's payment", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockPaymentDao.On("FindById", int64(3)).Return(payment.NewPaymentBuilder().WithId(3).WithMerchantId(20).Build(), nil)
+
+		result, err := usecases.NewGetPaymentTimeline(mockPaymentDao).Execute(context.Background(), 10, 3)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "FindTransitions", int64(3))
+	})
+}
@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+)
+
+type ListApiKeys struct {
+	apiKeyDao apikey.Dao
+}
+
+func NewListApiKeys(apiKeyDao apikey.Dao) *ListApiKeys {
+	return &ListApiKeys{
+		apiKeyDao: apiKeyDao,
+	}
+}
+
+func (l *ListApiKeys) Execute(ctx context.Context, merchantId int64) (_ []apikey.Entity, err error) {
+	ctx, span := startSpan(ctx, "ListApiKeys")
+	defer func() { endSpan(span, err) }()
+
+	return l.apiKeyDao.FindByMerchantId(ctx, merchantId)
+}
@@ -0,0 +1,41 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListApiKeys_Execute(t *testing.T) {
+	t.Run("should list merchant api keys", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		keys := []apikey.Entity{
+			*apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(1).Build(),
+			*apikey.NewApiKeyBuilder().WithId(2).WithMerchantId(1).Build(),
+		}
+		mockApiKeyDao.On("FindByMerchantId", int64(1)).Return(keys, nil)
+
+		useCase := usecases.NewListApiKeys(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, keys, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when find fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("FindByMerchantId", int64(1)).Return(nil, assert.AnError)
+
+		useCase := usecases.NewListApiKeys(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,30 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+)
+
+type ListOrderCharges struct {
+	orderDao  order.Dao
+	chargeDao charge.Dao
+}
+
+func NewListOrderCharges(orderDao order.Dao, chargeDao charge.Dao) *ListOrderCharges {
+	return &ListOrderCharges{
+		orderDao:  orderDao,
+		chargeDao: chargeDao,
+	}
+}
+
+func (l *ListOrderCharges) Execute(ctx context.Context, merchantId int64, orderId int64) (_ []charge.Entity, err error) {
+	ctx, span := startSpan(ctx, "ListOrderCharges")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := FindMerchantOrder(ctx, l.orderDao, merchantId, orderId); err != nil {
+		return nil, err
+	}
+
+	return l.chargeDao.FindByOrderId(ctx, orderId)
+}
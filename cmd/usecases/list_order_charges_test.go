@@ -0,0 +1,51 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOrderCharges_Execute(t *testing.T) {
+	t.Run("should list the charges of the order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		charges := []charge.Entity{*charge.NewChargeBuilder().WithId(1).WithPaymentId(3).Build()}
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(10).Build(), nil)
+		mockChargeDao.On("FindByOrderId", int64(2)).Return(charges, nil)
+
+		result, err := usecases.NewListOrderCharges(mockOrderDao, mockChargeDao).Execute(context.Background(), 10, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, charges, result)
+		mockChargeDao.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for a missing order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao.On("FindById", int64(2)).Return(nil, order.ErrNotFound)
+
+		result, err := usecases.NewListOrderCharges(mockOrderDao, mockChargeDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockChargeDao.AssertNotCalled(t, "FindByOrderId", int64(2))
+	})
+	t.Run("should return not found for another merchant's order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(20).Build(), nil)
+
+		result, err := usecases.NewListOrderCharges(mockOrderDao, mockChargeDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockChargeDao.AssertNotCalled(t, "FindByOrderId", int64(2))
+	})
+}
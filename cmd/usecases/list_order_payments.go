@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+)
+
+type ListOrderPayments struct {
+	orderDao   order.Dao
+	paymentDao payment.Dao
+}
+
+func NewListOrderPayments(orderDao order.Dao, paymentDao payment.Dao) *ListOrderPayments {
+	return &ListOrderPayments{
+		orderDao:   orderDao,
+		paymentDao: paymentDao,
+	}
+}
+
+// Execute looks the order up first, so a missing order is told apart from an
+// order without payments.
+func (l *ListOrderPayments) Execute(ctx context.Context, merchantId int64, orderId int64) (_ []payment.Entity, err error) {
+	ctx, span := startSpan(ctx, "ListOrderPayments")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := FindMerchantOrder(ctx, l.orderDao, merchantId, orderId); err != nil {
+		return nil, err
+	}
+
+	return l.paymentDao.FindByOrderId(ctx, orderId)
+}
@@ -0,0 +1,52 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOrderPayments_Execute(t *testing.T) {
+	t.Run("should list the payments of the order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		payments := []payment.Entity{*payment.NewPaymentBuilder().WithId(1).WithOrderId(2).Build()}
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(10).Build(), nil)
+		mockPaymentDao.On("FindByOrderId", int64(2)).Return(payments, nil)
+
+		result, err := usecases.NewListOrderPayments(mockOrderDao, mockPaymentDao).Execute(context.Background(), 10, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, payments, result)
+		mockOrderDao.AssertExpectations(t)
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should return not found for a missing order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockOrderDao.On("FindById", int64(2)).Return(nil, order.ErrNotFound)
+
+		result, err := usecases.NewListOrderPayments(mockOrderDao, mockPaymentDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "FindByOrderId", int64(2))
+	})
+	t.Run("should return not found for another merchant's order", func(t *testing.T) {
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockOrderDao.On("FindById", int64(2)).Return(order.NewOrderBuilder().WithId(2).WithMerchantId(20).Build(), nil)
+
+		result, err := usecases.NewListOrderPayments(mockOrderDao, mockPaymentDao).Execute(context.Background(), 10, 2)
+
+		assert.ErrorIs(t, err, order.ErrNotFound)
+		assert.Nil(t, result)
+		mockPaymentDao.AssertNotCalled(t, "FindByOrderId", int64(2))
+	})
+}
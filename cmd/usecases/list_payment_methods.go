@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/paymentmethod"
+)
+
+type ListPaymentMethods struct {
+	paymentMethodDao paymentmethod.Dao
+}
+
+func NewListPaymentMethods(paymentMethodDao paymentmethod.Dao) *ListPaymentMethods {
+	return &ListPaymentMethods{
+		paymentMethodDao: paymentMethodDao,
+	}
+}
+
+func (l *ListPaymentMethods) Execute(ctx context.Context, merchantId int64) (_ []paymentmethod.Entity, err error) {
+	ctx, span := startSpan(ctx, "ListPaymentMethods")
+	defer func() { endSpan(span, err) }()
+
+	settings, err := l.paymentMethodDao.FindSettingsByMerchantId(ctx, merchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make([]paymentmethod.Entity, 0)
+	for _, method := range paymentmethod.All() {
+		if enabled, ok := settings[method.Code()]; ok {
+			method.SetEnabled(enabled)
+		}
+
+		if method.Enabled() {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods, nil
+}
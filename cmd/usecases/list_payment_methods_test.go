@@ -0,0 +1,36 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPaymentMethods_Execute(t *testing.T) {
+	t.Run("should list methods enabled for the merchant", func(t *testing.T) {
+		mockPaymentMethodDao := new(testhelpers.MockPaymentMethodDao)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", int64(10)).Return(map[string]bool{"CashSlip": false}, nil)
+
+		methods, err := usecases.NewListPaymentMethods(mockPaymentMethodDao).Execute(context.Background(), 10)
+
+		assert.NoError(t, err)
+		if assert.Len(t, methods, 2) {
+			assert.Equal(t, "CreditCard", methods[0].Code())
+			assert.Equal(t, "Cash", methods[1].Code())
+		}
+		mockPaymentMethodDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when settings cannot be loaded", func(t *testing.T) {
+		mockPaymentMethodDao := new(testhelpers.MockPaymentMethodDao)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", int64(10)).Return(nil, assert.AnError)
+
+		methods, err := usecases.NewListPaymentMethods(mockPaymentMethodDao).Execute(context.Background(), 10)
+
+		assert.Error(t, err)
+		assert.Nil(t, methods)
+	})
+}
@@ -0,0 +1,14 @@
+package usecases
+
+// PaymentMetrics records the business events of the payment flow.
+type PaymentMetrics interface {
+	PaymentCreated(paymentType string)
+	PaymentProcessed(paymentType string, status string)
+	ChargeCreated(category string, amount float64)
+	OrderPaid()
+}
+
+// BalanceMetrics records the outcome of the order balance check.
+type BalanceMetrics interface {
+	BalanceDrift(orders int)
+}
@@ -0,0 +1,170 @@
+package usecases_test
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/infra/dao/memory"
+	helpers_test "payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentFlow(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.DiscardHandler)
+	merchantID := int64(10)
+	setup := func(t *testing.T, amount float64) (int64, *usecases.CreatePayment, *usecases.ProcessPayment, *usecases.GetCashout, *usecases.CheckOrderBalances) {
+		store := memory.NewStore()
+		orderDao := memory.NewOrderDao(store)
+		paymentDao := memory.NewPaymentDao(store)
+		chargeDao := memory.NewChargeDao(store)
+		paymentMethodDao := new(helpers_test.MockPaymentMethodDao)
+		paymentMethodDao.On("FindSettingsByMerchantId", mock.Anything).Return(map[string]bool{}, nil)
+		balanceMetrics := new(helpers_test.MockBalanceMetrics)
+		balanceMetrics.On("BalanceDrift", mock.Anything).Maybe()
+
+		or, err := orderDao.Insert(ctx, order.NewOrderBuilder().WithMerchantId(merchantID).WithStatus("pending").WithAmount(amount).Build())
+		require.NoError(t, err)
+
+		return or.Id(),
+			usecases.NewCreatePayment(paymentDao, orderDao, paymentMethodDao, logger, helpers_test.NewNopPaymentMetrics()),
+			usecases.NewProcessPayment(paymentDao, chargeDao, orderDao, store, logger, helpers_test.NewNopPaymentMetrics()),
+			usecases.NewGetCashout(orderDao, paymentDao, chargeDao),
+			usecases.NewCheckOrderBalances(orderDao, 100, logger, balanceMetrics)
+	}
+
+	t.Run("should pay the order across approved and reproved payments", func(t *testing.T) {
+		orderID, create, process, cashout, check := setup(t, 300)
+
+		card, err := create.Execute(ctx, merchantID, orderID, 100, "CreditCard")
+		require.NoError(t, err)
+		require.NoError(t, process.Execute(ctx, merchantID, card.Id(), "Success", ""))
+
+		afterCard := time.Now()
+
+		reproved, err := create.Execute(ctx, merchantID, orderID, 200, "CashSlip")
+		require.NoError(t, err)
+		require.NoError(t, process.Execute(ctx, merchantID, reproved.Id(), "Failure", "insufficient funds"))
+
+		slip, err := create.Execute(ctx, merchantID, orderID, 200, "CashSlip")
+		require.NoError(t, err)
+		require.NoError(t, process.Execute(ctx, merchantID, slip.Id(), "Success", ""))
+
+		or, view, err := cashout.Execute(ctx, merchantID, orderID)
+
+		require.NoError(t, err)
+		assert.True(t, or.IsPaid())
+		assert.Equal(t, 300.0, view.CashedDebt)
+		assert.Equal(t, 0.0, view.RemainingDebt)
+		assert.InDelta(t, 50.0, view.Charges, 0.001)
+		assert.True(t, view.IsPaid)
+		assert.InDelta(t, 250.0, view.NetAmount, 0.001)
+		assert.InDelta(t, 10.0, view.ChargesByCategory["financial_fee"], 0.001)
+		assert.InDelta(t, 40.0, view.ChargesByCategory["process_fee"], 0.001)
+		assert.Equal(t, map[string]float64{"CreditCard": 100, "CashSlip": 200}, view.PaidByType)
+		assert.Equal(t, map[string]int{"approved": 2, "reproved": 1}, view.PaymentsByStatus)
+
+		past, pastView, err := cashout.ExecuteAsOf(ctx, merchantID, orderID, afterCard)
+		require.NoError(t, err)
+		assert.False(t, past.IsPaid())
+		assert.Equal(t, 100.0, pastView.CashedDebt)
+		assert.Equal(t, 200.0, pastView.RemainingDebt)
+		assert.Equal(t, map[string]float64{"financial_fee": 10}, pastView.ChargesByCategory)
+		assert.Equal(t, map[string]int{"approved": 1}, pastView.PaymentsByStatus)
+
+		drifts, err := check.Execute(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("should keep the balance when payments are processed concurrently", func(t *testing.T) {
+		orderID, create, process, cashout, check := setup(t, 200)
+		var ids []int64
+		for range 20 {
+			pay, err := create.Execute(ctx, merchantID, orderID, 10, "Cash")
+			require.NoError(t, err)
+			ids = append(ids, pay.Id())
+		}
+
+		var wg sync.WaitGroup
+		for _, id := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, process.Execute(ctx, merchantID, id, "Success", ""))
+			}()
+		}
+		wg.Wait()
+
+		or, view, err := cashout.Execute(ctx, merchantID, orderID)
+		require.NoError(t, err)
+		assert.True(t, or.IsPaid())
+		assert.Equal(t, 200.0, view.CashedDebt)
+		drifts, err := check.Execute(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("should reject a payment above the remaining debt", func(t *testing.T) {
+		orderID, create, process, cashout, _ := setup(t, 100)
+
+		first, err := create.Execute(ctx, merchantID, orderID, 80, "Cash")
+		require.NoError(t, err)
+		require.NoError(t, process.Execute(ctx, merchantID, first.Id(), "Success", ""))
+
+		_, err = create.Execute(ctx, merchantID, orderID, 30, "Cash")
+		assert.Equal(t, "Payment exceeds debt", err.Error())
+
+		_, view, err := cashout.Execute(ctx, merchantID, orderID)
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, view.RemainingDebt)
+		assert.Equal(t, 0.0, view.Charges)
+	})
+
+	t.Run("should not process a payment twice", func(t *testing.T) {
+		orderID, create, process, _, _ := setup(t, 100)
+
+		pay, err := create.Execute(ctx, merchantID, orderID, 50, "CreditCard")
+		require.NoError(t, err)
+		require.NoError(t, process.Execute(ctx, merchantID, pay.Id(), "Success", ""))
+
+		err = process.Execute(ctx, merchantID, pay.Id(), "Success", "")
+		assert.Equal(t, "Payment was already processed", err.Error())
+	})
+
+	t.Run("should return not found for unknown orders and payments", func(t *testing.T) {
+		_, create, process, cashout, _ := setup(t, 100)
+
+		_, err := create.Execute(ctx, merchantID, 999, 10, "Cash")
+		assert.Equal(t, "Order not found", err.Error())
+		err = process.Execute(ctx, merchantID, 999, "Success", "")
+		assert.Equal(t, "Payment not found", err.Error())
+		_, _, err = cashout.Execute(ctx, merchantID, 999)
+		assert.Equal(t, "Order not found", err.Error())
+	})
+
+	t.Run("should keep other merchants out of the order and its payments", func(t *testing.T) {
+		orderID, create, process, cashout, _ := setup(t, 100)
+		pay, err := create.Execute(ctx, merchantID, orderID, 10, "Cash")
+		require.NoError(t, err)
+		otherID := merchantID + 1
+
+		_, err = create.Execute(ctx, otherID, orderID, 10, "Cash")
+		assert.Equal(t, "Order not found", err.Error())
+		err = process.Execute(ctx, otherID, pay.Id(), "Success", "")
+		assert.Equal(t, "Payment not found", err.Error())
+		_, _, err = cashout.Execute(ctx, otherID, orderID)
+		assert.Equal(t, "Order not found", err.Error())
+
+		_, view, err := cashout.Execute(ctx, merchantID, orderID)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, view.RemainingDebt)
+	})
+}
@@ -1,6 +1,8 @@
 package usecases
 
 import (
+	"context"
+	"log/slog"
 	"payment-gateway/cmd/domain/charge"
 	"payment-gateway/cmd/domain/order"
 	"payment-gateway/cmd/domain/payment"
@@ -10,54 +12,95 @@ type ProcessPayment struct {
 	paymentDao payment.Dao
 	chargeDao  charge.Dao
 	orderDao   order.Dao
+	transactor Transactor
+	logger     *slog.Logger
+	metrics    PaymentMetrics
 }
 
-func NewProcessPayment(paymentDao payment.Dao, chargeDao charge.Dao, orderDao order.Dao) *ProcessPayment {
+func NewProcessPayment(paymentDao payment.Dao, chargeDao charge.Dao, orderDao order.Dao, transactor Transactor, logger *slog.Logger, metrics PaymentMetrics) *ProcessPayment {
 	return &ProcessPayment{
 		paymentDao: paymentDao,
 		chargeDao:  chargeDao,
 		orderDao:   orderDao,
+		transactor: transactor,
+		logger:     logger,
+		metrics:    metrics,
 	}
 }
 
-func (p *ProcessPayment) Execute(paymentID int64, processType string, details string) error {
-	pay, err := p.paymentDao.FindById(paymentID)
-	if err != nil {
-		return err
-	}
+func (p *ProcessPayment) Execute(ctx context.Context, merchantId int64, paymentID int64, processType string, details string) (err error) {
+	ctx, span := startSpan(ctx, "ProcessPayment")
+	defer func() { endSpan(span, err) }()
 
-	or, err := p.orderDao.FindById(pay.OrderID())
-	if err != nil {
-		return err
-	}
+	var pay *payment.Entity
+	var or *order.Entity
+	var newCharge *charge.Entity
+	var charged, wasPaid bool
+	// The payment is locked before its order so concurrent calls for the
+	// same order queue up instead of overwriting each other's balance.
+	err = p.transactor.Transaction(ctx, func(ctx context.Context) (err error) {
+		pay, err = p.paymentDao.FindByIdForUpdate(ctx, paymentID)
+		if err != nil {
+			return err
+		}
+		if pay.MerchantId() != merchantId {
+			return payment.ErrNotFound
+		}
 
-	paidAmount, err := GetPaidAmount(p.paymentDao, or.Id())
-	if err != nil {
-		return err
-	}
+		or, err = p.orderDao.FindByIdForUpdate(ctx, pay.OrderID())
+		if err != nil {
+			return err
+		}
 
-	pay.Process(processType, details)
-	err = or.ProcessPayment(or.Amount()-paidAmount, *pay)
-	if err != nil {
-		return err
-	}
+		err = pay.Process(processType, details)
+		if err != nil {
+			return err
+		}
 
-	_, err = p.paymentDao.Update(pay)
-	if err != nil {
+		wasPaid = or.IsPaid()
+		err = or.ProcessPayment(*pay)
+		if err != nil {
+			return err
+		}
+
+		newCharge, charged = charge.NewCharge(*pay, charge.WithCreatedAt(pay.UpdatedAt()))
+		if charged {
+			or.AddCharge(newCharge.Amount())
+		}
+
+		_, err = p.paymentDao.Update(ctx, pay)
+		if err != nil {
+			return err
+		}
+		_, err = p.orderDao.Update(ctx, or)
+		if err != nil {
+			return err
+		}
+
+		if charged {
+			newCharge, err = p.chargeDao.Insert(ctx, newCharge)
+		}
 		return err
-	}
-	_, err = p.orderDao.Update(or)
+	})
 	if err != nil {
 		return err
 	}
 
-	newCharge, ok := charge.NewCharge(*pay)
-	if ok {
-		newCharge, err = p.chargeDao.Insert(newCharge)
-		if err != nil {
-			return err
-		}
+	if charged {
+		p.metrics.ChargeCreated(newCharge.Category(), newCharge.Amount())
 	}
+	p.metrics.PaymentProcessed(pay.Type(), pay.Status())
+	if or.IsPaid() && !wasPaid {
+		p.metrics.OrderPaid()
+	}
+
+	p.logger.InfoContext(ctx, "payment processed",
+		slog.Int64("payment_id", pay.Id()),
+		slog.Int64("order_id", or.Id()),
+		slog.String("status", pay.Status()),
+		slog.String("order_status", or.Status()),
+		slog.String("details", details),
+	)
 
 	return nil
 }
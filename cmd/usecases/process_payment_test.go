@@ -1,6 +1,9 @@
 package usecases_test
 
 import (
+	"context"
+	"log/slog"
+	exceptions "payment-gateway/cmd/domain/err"
 	"payment-gateway/cmd/domain/order"
 	"testing"
 
@@ -13,32 +16,39 @@ import (
 )
 
 func TestProcessPayment_Execute(t *testing.T) {
+	merchantID := int64(7)
 	paymentID := int64(123)
 	orderID := int64(456)
 	processType := "Success"
 	details := "payment processed"
 
-	expectedOrder := order.NewOrderBuilder().WithId(orderID).WithAmount(100.5).Build()
-	existingPayment := payment.NewPayment(orderID, 100.5, "credit_card")
-	existingPayment.SetId(paymentID)
+	newOrder := func() *order.Entity {
+		return order.NewOrderBuilder().WithId(orderID).WithAmount(100.5).Build()
+	}
+	newExistingPayment := func() *payment.Entity {
+		existingPayment := payment.NewPayment(orderID, 100.5, "credit_card")
+		existingPayment.SetId(paymentID)
+		existingPayment.SetMerchantId(merchantID)
+		return existingPayment
+	}
 
 	t.Run("should process payment successfully", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
 
-		mockChargeDao.On("Insert", mock.Anything).Return(&charge.Entity{}, nil)
+		mockChargeDao.On("Insert", mock.MatchedBy(func(c *charge.Entity) bool {
+			return c.CreatedAt().Equal(existingPayment.UpdatedAt())
+		})).Return(&charge.Entity{}, nil)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
-		mockOrderDao.On("Update", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+		mockOrderDao.On("Update", mock.Anything).Return(newOrder(), nil)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.NoError(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -51,10 +61,10 @@ func TestProcessPayment_Execute(t *testing.T) {
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
 
-		mockPaymentDao.On("FindById", paymentID).Return(nil, assert.AnError)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(nil, assert.AnError)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.Error(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -63,22 +73,20 @@ func TestProcessPayment_Execute(t *testing.T) {
 	})
 
 	t.Run("should process payment successfully", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
 
 		mockChargeDao.On("Insert", mock.Anything).Return(&charge.Entity{}, nil)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
-		mockOrderDao.On("Update", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+		mockOrderDao.On("Update", mock.Anything).Return(newOrder(), nil)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.NoError(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -87,19 +95,17 @@ func TestProcessPayment_Execute(t *testing.T) {
 	})
 
 	t.Run("should throw error when payment update fails", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, assert.AnError)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.Error(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -108,20 +114,18 @@ func TestProcessPayment_Execute(t *testing.T) {
 	})
 
 	t.Run("should throw error when order update fails", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
-		mockOrderDao.On("Update", mock.Anything).Return(expectedOrder, assert.AnError)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+		mockOrderDao.On("Update", mock.Anything).Return(newOrder(), assert.AnError)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.Error(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -130,22 +134,20 @@ func TestProcessPayment_Execute(t *testing.T) {
 	})
 
 	t.Run("should throw error when charge insert fails", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
-
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
 
 		mockChargeDao.On("Insert", mock.Anything).Return(&charge.Entity{}, assert.AnError)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
-		mockOrderDao.On("Update", mock.Anything).Return(expectedOrder, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+		mockOrderDao.On("Update", mock.Anything).Return(newOrder(), nil)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.Error(t, err)
 		mockPaymentDao.AssertExpectations(t)
@@ -153,41 +155,157 @@ func TestProcessPayment_Execute(t *testing.T) {
 		mockOrderDao.AssertExpectations(t)
 	})
 
-	t.Run("should return error when charge creation fails", func(t *testing.T) {
+	t.Run("should return error when the payment exceeds the remaining debt", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
-		var existingPayments []payment.Entity
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
-		mockPaymentDao.On("FindByOrderId", mock.Anything, mock.Anything).Return(existingPayments, assert.AnError)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(order.NewOrderBuilder().WithId(orderID).WithAmount(100.5).WithPaidAmount(50).Build(), nil)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, nil)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		assert.Equal(t, "Payment exceeds debt", err.Error())
+		mockPaymentDao.AssertNotCalled(t, "Update", mock.Anything)
+		mockOrderDao.AssertNotCalled(t, "Update", mock.Anything)
+	})
 
-		assert.Error(t, err)
-		mockPaymentDao.AssertExpectations(t)
-		mockChargeDao.AssertExpectations(t)
+	t.Run("should add the payment and its charge to the order balance", func(t *testing.T) {
+		existingPayment := newExistingPayment()
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockTransactor := new(testhelpers.MockTransactor)
+		partiallyPaid := order.NewOrderBuilder().WithId(orderID).WithAmount(200).WithPaidAmount(50).WithChargesAmount(1).Build()
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
+		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
+		mockOrderDao.On("FindByIdForUpdate", orderID).Return(partiallyPaid, nil)
+		mockOrderDao.On("Update", mock.MatchedBy(func(or *order.Entity) bool {
+			fee, _ := charge.NewCharge(*existingPayment)
+			return or.PaidAmount() == 150.5 && or.ChargesAmount() == 1+fee.Amount() && !or.IsPaid()
+		})).Return(partiallyPaid, nil)
+		mockChargeDao.On("Insert", mock.Anything).Return(&charge.Entity{}, nil)
+		mockTransactor.On("Transaction").Return(nil).Once()
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, mockTransactor, slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
+
+		assert.NoError(t, err)
 		mockOrderDao.AssertExpectations(t)
+		mockTransactor.AssertExpectations(t)
 	})
 
 	t.Run("should return error when find order fails", func(t *testing.T) {
+		existingPayment := newExistingPayment()
 		mockPaymentDao := new(testhelpers.MockPaymentDao)
 		mockChargeDao := new(testhelpers.MockChargeDao)
 		mockOrderDao := new(testhelpers.MockOrderDao)
 
-		mockPaymentDao.On("FindById", paymentID).Return(existingPayment, nil)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
 
-		mockOrderDao.On("FindById", mock.Anything).Return(expectedOrder, assert.AnError)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), assert.AnError)
 
-		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao)
-		err := useCase.Execute(paymentID, processType, details)
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
 
 		assert.Error(t, err)
 		mockPaymentDao.AssertExpectations(t)
 		mockChargeDao.AssertExpectations(t)
 		mockOrderDao.AssertExpectations(t)
 	})
+
+	t.Run("should not process payment of another merchant", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao := new(testhelpers.MockOrderDao)
+
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(newExistingPayment(), nil)
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID+1, paymentID, processType, details)
+
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+		mockPaymentDao.AssertNotCalled(t, "Update", mock.Anything)
+		mockOrderDao.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything)
+	})
+
+	t.Run("should return invalid transition when payment was already processed", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		existingPayment := newExistingPayment()
+		existingPayment.Process("Success", "first")
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
+
+		var ex *exceptions.DomainError
+		assert.ErrorAs(t, err, &ex)
+		assert.Equal(t, exceptions.CodeInvalidTransition, ex.Code())
+		mockPaymentDao.AssertNotCalled(t, "Update", mock.Anything)
+		mockChargeDao.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should record approval, charge and paid order metrics", func(t *testing.T) {
+		existingPayment := newExistingPayment()
+		unpaidOrder := order.NewOrderBuilder().WithId(orderID).WithAmount(100.5).Build()
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockMetrics := new(testhelpers.MockPaymentMetrics)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
+		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
+		mockChargeDao.On("Insert", mock.Anything).Return(charge.NewChargeBuilder().WithCategory("financial_fee").WithAmount(10.05).Build(), nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(unpaidOrder, nil)
+		mockOrderDao.On("Update", mock.Anything).Return(unpaidOrder, nil)
+
+		mockMetrics.On("ChargeCreated", "financial_fee", 10.05).Once()
+		mockMetrics.On("PaymentProcessed", "credit_card", "approved").Once()
+		mockMetrics.On("OrderPaid").Once()
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), mockMetrics)
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
+
+		assert.NoError(t, err)
+		mockMetrics.AssertExpectations(t)
+	})
+
+	t.Run("should not record metrics when processing fails", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockMetrics := new(testhelpers.MockPaymentMetrics)
+
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(nil, assert.AnError)
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, new(testhelpers.MockChargeDao), new(testhelpers.MockOrderDao), testhelpers.NewNopTransactor(), slog.New(slog.DiscardHandler), mockMetrics)
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
+
+		assert.Error(t, err)
+		mockMetrics.AssertNotCalled(t, "PaymentProcessed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not record metrics when the transaction fails to commit", func(t *testing.T) {
+		existingPayment := newExistingPayment()
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockChargeDao := new(testhelpers.MockChargeDao)
+		mockOrderDao := new(testhelpers.MockOrderDao)
+		mockTransactor := new(testhelpers.MockTransactor)
+		mockMetrics := new(testhelpers.MockPaymentMetrics)
+		mockPaymentDao.On("FindByIdForUpdate", paymentID).Return(existingPayment, nil)
+		mockPaymentDao.On("Update", mock.Anything).Return(existingPayment, nil)
+		mockOrderDao.On("FindByIdForUpdate", mock.Anything).Return(newOrder(), nil)
+		mockOrderDao.On("Update", mock.Anything).Return(newOrder(), nil)
+		mockChargeDao.On("Insert", mock.Anything).Return(&charge.Entity{}, nil)
+		mockTransactor.On("Transaction").Return(assert.AnError)
+
+		useCase := usecases.NewProcessPayment(mockPaymentDao, mockChargeDao, mockOrderDao, mockTransactor, slog.New(slog.DiscardHandler), mockMetrics)
+		err := useCase.Execute(context.Background(), merchantID, paymentID, processType, details)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		mockMetrics.AssertNotCalled(t, "PaymentProcessed", mock.Anything, mock.Anything)
+		mockMetrics.AssertNotCalled(t, "OrderPaid")
+	})
 }
@@ -0,0 +1,24 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"time"
+)
+
+type PurgeExpiredNonces struct {
+	nonceDao apikey.NonceDao
+}
+
+func NewPurgeExpiredNonces(nonceDao apikey.NonceDao) *PurgeExpiredNonces {
+	return &PurgeExpiredNonces{
+		nonceDao: nonceDao,
+	}
+}
+
+func (p *PurgeExpiredNonces) Execute(ctx context.Context) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "PurgeExpiredNonces")
+	defer func() { endSpan(span, err) }()
+
+	return p.nonceDao.PurgeExpired(ctx, time.Now())
+}
@@ -0,0 +1,33 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPurgeExpiredNonces_Execute(t *testing.T) {
+	t.Run("should purge nonces expired by now", func(t *testing.T) {
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockNonceDao.On("PurgeExpired", mock.Anything).Return(int64(3), nil)
+
+		purged, err := usecases.NewPurgeExpiredNonces(mockNonceDao).Execute(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		mockNonceDao.AssertExpectations(t)
+	})
+
+	t.Run("should return dao errors", func(t *testing.T) {
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockNonceDao.On("PurgeExpired", mock.Anything).Return(int64(0), assert.AnError)
+
+		_, err := usecases.NewPurgeExpiredNonces(mockNonceDao).Execute(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
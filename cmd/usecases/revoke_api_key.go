@@ -0,0 +1,33 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+)
+
+type RevokeApiKey struct {
+	apiKeyDao apikey.Dao
+}
+
+func NewRevokeApiKey(apiKeyDao apikey.Dao) *RevokeApiKey {
+	return &RevokeApiKey{
+		apiKeyDao: apiKeyDao,
+	}
+}
+
+func (r *RevokeApiKey) Execute(ctx context.Context, merchantId int64, keyId int64) (_ *apikey.Entity, err error) {
+	ctx, span := startSpan(ctx, "RevokeApiKey")
+	defer func() { endSpan(span, err) }()
+
+	key, err := FindMerchantApiKey(ctx, r.apiKeyDao, merchantId, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.Revoke()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apiKeyDao.Update(ctx, key)
+}
@@ -0,0 +1,68 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRevokeApiKey_Execute(t *testing.T) {
+	t.Run("should revoke api key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+		mockApiKeyDao.On("Update", key).Return(key, nil)
+
+		useCase := usecases.NewRevokeApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsRevoked())
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should not revoke an already revoked key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithRevokedAt(time.Now()).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+
+		useCase := usecases.NewRevokeApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Equal(t, "Api key is already revoked", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when key is not found", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("FindById", int64(1)).Return(nil, assert.AnError)
+
+		useCase := usecases.NewRevokeApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when update fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+		mockApiKeyDao.On("Update", mock.Anything).Return(nil, assert.AnError)
+
+		useCase := usecases.NewRevokeApiKey(mockApiKeyDao)
+		result, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+)
+
+type RotateApiKey struct {
+	apiKeyDao apikey.Dao
+}
+
+func NewRotateApiKey(apiKeyDao apikey.Dao) *RotateApiKey {
+	return &RotateApiKey{
+		apiKeyDao: apiKeyDao,
+	}
+}
+
+func (r *RotateApiKey) Execute(ctx context.Context, merchantId int64, keyId int64) (_ *apikey.Entity, _ string, err error) {
+	ctx, span := startSpan(ctx, "RotateApiKey")
+	defer func() { endSpan(span, err) }()
+
+	key, err := FindMerchantApiKey(ctx, r.apiKeyDao, merchantId, keyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := key.Rotate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err = r.apiKeyDao.Update(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
@@ -0,0 +1,72 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRotateApiKey_Execute(t *testing.T) {
+	t.Run("should rotate api key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithHash("old").Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+		mockApiKeyDao.On("Update", key).Return(key, nil)
+
+		useCase := usecases.NewRotateApiKey(mockApiKeyDao)
+		result, token, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, apikey.Hash(token), result.Hash())
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should not rotate revoked key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithRevokedAt(time.Now()).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+
+		useCase := usecases.NewRotateApiKey(mockApiKeyDao)
+		result, token, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Equal(t, "Api key is already revoked", err.Error())
+		assert.Nil(t, result)
+		assert.Empty(t, token)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should not rotate key of another merchant", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(20).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+
+		useCase := usecases.NewRotateApiKey(mockApiKeyDao)
+		result, _, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Equal(t, "Api key not found", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when update fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).Build()
+		mockApiKeyDao.On("FindById", int64(1)).Return(key, nil)
+		mockApiKeyDao.On("Update", mock.Anything).Return(nil, assert.AnError)
+
+		useCase := usecases.NewRotateApiKey(mockApiKeyDao)
+		result, token, err := useCase.Execute(context.Background(), 10, 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Empty(t, token)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+}
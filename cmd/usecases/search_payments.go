@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+)
+
+const defaultSearchLimit = 20
+
+type SearchPayments struct {
+	paymentDao payment.Dao
+}
+
+type PaymentPage struct {
+	Payments []payment.Entity
+	// Next points at the last payment of the page, or is nil on the last
+	// page.
+	Next *payment.Cursor
+}
+
+func NewSearchPayments(paymentDao payment.Dao) *SearchPayments {
+	return &SearchPayments{
+		paymentDao: paymentDao,
+	}
+}
+
+// Execute searches the payments of the merchant only, and asks for one
+// payment more than the page holds to know whether another page follows.
+func (s *SearchPayments) Execute(ctx context.Context, merchantId int64, search payment.Search) (_ PaymentPage, err error) {
+	ctx, span := startSpan(ctx, "SearchPayments")
+	defer func() { endSpan(span, err) }()
+
+	search.MerchantId = merchantId
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	search.Limit = limit + 1
+
+	payments, err := s.paymentDao.Search(ctx, search)
+	if err != nil {
+		return PaymentPage{}, err
+	}
+
+	page := PaymentPage{Payments: payments}
+	if len(payments) > limit {
+		page.Payments = payments[:limit]
+		next := payment.CursorOf(page.Payments[limit-1])
+		page.Next = &next
+	}
+
+	return page, nil
+}
@@ -0,0 +1,69 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchPayments_Execute(t *testing.T) {
+	payments := func(ids ...int64) []payment.Entity {
+		var result []payment.Entity
+		for _, id := range ids {
+			result = append(result, *payment.NewPaymentBuilder().WithId(id).WithAmount(float64(id)).Build())
+		}
+		return result
+	}
+
+	t.Run("should point the next cursor at the last payment when more follow", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		found := payments(1, 2, 3)
+		mockPaymentDao.On("Search", payment.Search{MerchantId: 10, Status: "approved", Limit: 3}).Return(found, nil)
+
+		page, err := usecases.NewSearchPayments(mockPaymentDao).Execute(context.Background(), 10, payment.Search{Status: "approved", Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, found[:2], page.Payments)
+		if assert.NotNil(t, page.Next) {
+			assert.Equal(t, payment.CursorOf(found[1]), *page.Next)
+		}
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should search the payments of the merchant whatever the search asks", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockPaymentDao.On("Search", payment.Search{MerchantId: 10, Limit: 21}).Return([]payment.Entity{}, nil)
+
+		_, err := usecases.NewSearchPayments(mockPaymentDao).Execute(context.Background(), 10, payment.Search{MerchantId: 20})
+
+		assert.NoError(t, err)
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should end on a short page", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		found := payments(1, 2)
+		mockPaymentDao.On("Search", payment.Search{MerchantId: 10, Limit: 21}).Return(found, nil)
+
+		page, err := usecases.NewSearchPayments(mockPaymentDao).Execute(context.Background(), 10, payment.Search{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, found, page.Payments)
+		assert.Nil(t, page.Next)
+		mockPaymentDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when search fails", func(t *testing.T) {
+		mockPaymentDao := new(testhelpers.MockPaymentDao)
+		mockPaymentDao.On("Search", payment.Search{MerchantId: 10, Limit: 21}).Return(nil, assert.AnError)
+
+		page, err := usecases.NewSearchPayments(mockPaymentDao).Execute(context.Background(), 10, payment.Search{})
+
+		assert.Error(t, err)
+		assert.Equal(t, usecases.PaymentPage{}, page)
+	})
+}
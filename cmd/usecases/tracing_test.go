@@ -0,0 +1,36 @@
+package usecases_test
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestUseCaseTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	t.Run("should open a span per use case nested under the caller", func(t *testing.T) {
+		mockPaymentMethodDao := new(testhelpers.MockPaymentMethodDao)
+		mockPaymentMethodDao.On("FindSettingsByMerchantId", int64(10)).Return(nil, assert.AnError)
+
+		useCase := usecases.NewCreatePayment(new(testhelpers.MockPaymentDao), new(testhelpers.MockOrderDao), mockPaymentMethodDao, slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		_, err := useCase.Execute(context.Background(), 10, 2, 10, "CreditCard")
+
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 2) {
+			assert.Equal(t, "FindMerchantPaymentMethod", spans[0].Name())
+			assert.Equal(t, "CreatePayment", spans[1].Name())
+			assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+			assert.Equal(t, "Error", spans[1].Status().Code.String())
+		}
+	})
+}
@@ -0,0 +1,9 @@
+package usecases
+
+import "context"
+
+// Transactor runs fn atomically: every DAO call made with the context it
+// receives commits or rolls back together.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
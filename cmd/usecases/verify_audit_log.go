@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+)
+
+// auditPageSize bounds how many entries VerifyAuditLog reads at a time.
+const auditPageSize = 1000
+
+// VerifyAuditLog walks the audit log from its first entry up to the head of
+// the chain read when it starts, and stops at the first entry that does not
+// link to the one before it. Once the chain holds, it compares every entity
+// the log records with the state its last entry left it in, so rows changed
+// without going through the gateway show up as drifts. Entities changed by
+// entries appended while it runs are left out.
+type VerifyAuditLog struct {
+	auditDao audit.Dao
+	states   audit.StateReader
+	key      []byte
+}
+
+func NewVerifyAuditLog(auditDao audit.Dao, states audit.StateReader, key []byte) *VerifyAuditLog {
+	return &VerifyAuditLog{
+		auditDao: auditDao,
+		states:   states,
+		key:      key,
+	}
+}
+
+func (v *VerifyAuditLog) Execute(ctx context.Context) (_ *audit.Verification, err error) {
+	ctx, span := startSpan(ctx, "VerifyAuditLog")
+	defer func() { endSpan(span, err) }()
+
+	headSeq, headHash, err := v.auditDao.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := audit.NewVerification(v.key)
+	for verification.LastSeq < headSeq {
+		entries, err := v.auditDao.FindAfter(ctx, verification.LastSeq, auditPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		walked := 0
+		for _, entry := range entries {
+			if entry.Seq > headSeq {
+				break
+			}
+			if !verification.Add(entry) {
+				return verification, nil
+			}
+			walked++
+		}
+		if walked < auditPageSize {
+			break
+		}
+	}
+	verification.End(headSeq, headHash)
+	if verification.Broken() {
+		return verification, nil
+	}
+
+	for _, entry := range verification.Latest() {
+		current, found, err := v.states.State(ctx, entry.Entity, entry.EntityId)
+		if err != nil {
+			return nil, err
+		}
+		if err := verification.Compare(entry, current, found); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read past the head only after the rows, so a change made while they
+	// were compared is either in the state read or in an entry found here.
+	for seq := headSeq; verification.Drifted(); {
+		entries, err := v.auditDao.FindAfter(ctx, seq, auditPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			verification.Superseded(entry)
+			seq = entry.Seq
+		}
+		if len(entries) < auditPageSize {
+			break
+		}
+	}
+
+	return verification, nil
+}
@@ -0,0 +1,181 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/audit"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var auditKey = []byte("0123456789abcdef0123456789abcdef")
+
+// unchangedStates reads every entity in the state auditChain leaves it in.
+func unchangedStates() *testhelpers.MockStateReader {
+	states := new(testhelpers.MockStateReader)
+	states.On("State", mock.Anything, mock.Anything).Return(`{"status":"pending"}`, true, nil)
+	return states
+}
+
+func auditChain(n int) []audit.Entry {
+	entries := make([]audit.Entry, n)
+	prevSeq, prevHash := int64(0), audit.Genesis
+	for i := range entries {
+		entries[i] = audit.Entry{
+			Entity:    audit.EntityPayment,
+			EntityId:  int64(i + 1),
+			Action:    audit.ActionInsert,
+			After:     `{"status":"pending"}`,
+			CreatedAt: time.Now(),
+		}
+		entries[i].Link(prevSeq, prevHash, auditKey)
+		prevSeq, prevHash = entries[i].Seq, entries[i].Hash
+	}
+
+	return entries
+}
+
+func TestVerifyAuditLog_Execute(t *testing.T) {
+	t.Run("should walk every entry up to the head", func(t *testing.T) {
+		entries := auditChain(3)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(3), entries[2].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, result.Broken())
+		assert.Equal(t, int64(3), result.Entries)
+		assert.Equal(t, entries[2].Hash, result.LastHash)
+		mockAuditDao.AssertExpectations(t)
+	})
+
+	t.Run("should stop at the first broken link", func(t *testing.T) {
+		entries := auditChain(3)
+		entries[1].After = `{"status":"approved"}`
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(3), entries[2].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, result.Broken())
+		assert.Equal(t, int64(2), result.BrokenAt)
+		assert.Equal(t, int64(1), result.Entries)
+	})
+
+	t.Run("should leave out entries appended after the head was read", func(t *testing.T) {
+		entries := auditChain(3)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(2), entries[1].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, result.Broken())
+		assert.Equal(t, int64(2), result.LastSeq)
+	})
+
+	t.Run("should report entries missing from the end", func(t *testing.T) {
+		entries := auditChain(3)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(3), entries[2].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries[:2], nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.BrokenAt)
+	})
+
+	t.Run("should not read entries from an empty log", func(t *testing.T) {
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(0), audit.Genesis, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, result.Broken())
+		mockAuditDao.AssertNotCalled(t, "FindAfter", int64(0), 1000)
+	})
+
+	t.Run("should reject a chain sealed with another key", func(t *testing.T) {
+		entries := auditChain(2)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(2), entries[1].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), []byte("another key")).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.BrokenAt)
+	})
+
+	t.Run("should report the rows that drifted from their last entry", func(t *testing.T) {
+		entries := auditChain(3)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(3), entries[2].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+		mockAuditDao.On("FindAfter", int64(3), 1000).Return(nil, nil)
+		states := new(testhelpers.MockStateReader)
+		states.On("State", audit.EntityPayment, int64(1)).Return(`{"status":"pending"}`, true, nil)
+		states.On("State", audit.EntityPayment, int64(2)).Return(`{"status":"approved"}`, true, nil)
+		states.On("State", audit.EntityPayment, int64(3)).Return("", false, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, states, auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, result.Broken())
+		assert.Equal(t, []audit.Drift{
+			{Entity: audit.EntityPayment, EntityId: 2, Seq: 2, Reason: "status differs from entry 2"},
+			{Entity: audit.EntityPayment, EntityId: 3, Seq: 3, Reason: "no longer exists"},
+		}, result.Drifts)
+		mockAuditDao.AssertExpectations(t)
+	})
+
+	t.Run("should not report rows changed by entries appended after the head", func(t *testing.T) {
+		entries := auditChain(3)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(2), entries[1].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries[:2], nil)
+		mockAuditDao.On("FindAfter", int64(2), 1000).Return([]audit.Entry{{Seq: 3, Entity: audit.EntityPayment, EntityId: 2}}, nil)
+		states := new(testhelpers.MockStateReader)
+		states.On("State", audit.EntityPayment, int64(1)).Return(`{"status":"pending"}`, true, nil)
+		states.On("State", audit.EntityPayment, int64(2)).Return(`{"status":"approved"}`, true, nil)
+
+		result, err := usecases.NewVerifyAuditLog(mockAuditDao, states, auditKey).Execute(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, result.Drifted())
+	})
+
+	t.Run("should fail when a row cannot be read", func(t *testing.T) {
+		entries := auditChain(1)
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(1), entries[0].Hash, nil)
+		mockAuditDao.On("FindAfter", int64(0), 1000).Return(entries, nil)
+		states := new(testhelpers.MockStateReader)
+		states.On("State", audit.EntityPayment, int64(1)).Return("", false, assert.AnError)
+
+		_, err := usecases.NewVerifyAuditLog(mockAuditDao, states, auditKey).Execute(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("should fail when the head cannot be read", func(t *testing.T) {
+		mockAuditDao := new(testhelpers.MockAuditDao)
+		mockAuditDao.On("Head").Return(int64(0), "", assert.AnError)
+
+		_, err := usecases.NewVerifyAuditLog(mockAuditDao, unchangedStates(), auditKey).Execute(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
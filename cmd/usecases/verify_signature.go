@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"payment-gateway/cmd/domain/apikey"
+	exceptions "payment-gateway/cmd/domain/err"
+	"payment-gateway/pkg/signature"
+	"time"
+)
+
+const (
+	errInvalidSignature      = "Invalid request signature"
+	errSignatureOutOfWindow  = "Request timestamp is outside the allowed window"
+	errSignatureReplayed     = "Request nonce was already used"
+	errSignatureMissingNonce = "Request nonce is missing or too long"
+
+	maxNonceLength = 64
+)
+
+type SignedRequest struct {
+	KeyId     string
+	Timestamp int64
+	Nonce     string
+	Signature string
+	Method    string
+	URI       string
+	Body      []byte
+}
+
+type VerifySignature struct {
+	apiKeyDao apikey.Dao
+	nonceDao  apikey.NonceDao
+	tolerance time.Duration
+}
+
+func NewVerifySignature(apiKeyDao apikey.Dao, nonceDao apikey.NonceDao, tolerance time.Duration) *VerifySignature {
+	return &VerifySignature{
+		apiKeyDao: apiKeyDao,
+		nonceDao:  nonceDao,
+		tolerance: tolerance,
+	}
+}
+
+func (v *VerifySignature) Execute(ctx context.Context, req SignedRequest) (_ *apikey.Entity, err error) {
+	ctx, span := startSpan(ctx, "VerifySignature")
+	defer func() { endSpan(span, err) }()
+
+	if req.Nonce == "" || len(req.Nonce) > maxNonceLength {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errSignatureMissingNonce)
+	}
+
+	now := time.Now()
+	signedAt := time.Unix(req.Timestamp, 0)
+	if signedAt.Before(now.Add(-v.tolerance)) || signedAt.After(now.Add(v.tolerance)) {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errSignatureOutOfWindow)
+	}
+
+	key, err := v.apiKeyDao.FindByPrefix(ctx, req.KeyId)
+	if errors.Is(err, apikey.ErrNotFound) {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errInvalidSignature)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.IsRevoked() || key.SigningSecret() == "" {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errInvalidSignature)
+	}
+
+	if !signature.Verify(key.SigningSecret(), req.Signature, req.Method, req.URI, req.Timestamp, req.Nonce, req.Body) {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errInvalidSignature)
+	}
+
+	registered, err := v.nonceDao.Register(ctx, key.Id(), req.Nonce, signedAt.Add(v.tolerance))
+	if err != nil {
+		return nil, err
+	}
+
+	if !registered {
+		return nil, exceptions.NewDomainError(exceptions.CodeInvalidSignature, errSignatureReplayed)
+	}
+
+	if key.UseIsStale(now) {
+		err = v.apiKeyDao.TouchLastUsed(ctx, key.Id(), now)
+		if err != nil {
+			return nil, err
+		}
+		key.SetLastUsedAt(now)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,149 @@
+package usecases_test
+
+import (
+	"context"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"payment-gateway/pkg/signature"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func signedRequest(secret string, timestamp int64, nonce string) usecases.SignedRequest {
+	body := []byte(`{"order_id":1}`)
+
+	return usecases.SignedRequest{
+		KeyId:     "abc123",
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		Signature: signature.Sign(secret, "POST", "/payments", timestamp, nonce, body),
+		Method:    "POST",
+		URI:       "/payments",
+		Body:      body,
+	}
+}
+
+func TestVerifySignature_Execute(t *testing.T) {
+	tolerance := 5 * time.Minute
+	key := apikey.NewApiKeyBuilder().WithId(1).WithMerchantId(10).WithPrefix("abc123").WithSigningSecret("secret").Build()
+
+	t.Run("should authenticate a correctly signed request", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(key, nil)
+		mockApiKeyDao.On("TouchLastUsed", int64(1), mock.Anything).Return(nil)
+		mockNonceDao.On("Register", int64(1), "nonce-1", mock.Anything).Return(true, nil)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, mockNonceDao, tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), result.MerchantId())
+		mockApiKeyDao.AssertExpectations(t)
+		mockNonceDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject a replayed nonce", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(key, nil)
+		mockNonceDao.On("Register", int64(1), "nonce-1", mock.Anything).Return(false, nil)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, mockNonceDao, tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+
+		assert.Equal(t, "Request nonce was already used", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+		mockNonceDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject a signature made with another secret", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(key, nil)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, mockNonceDao, tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("wrong", time.Now().Unix(), "nonce-1"))
+
+		assert.Equal(t, "Invalid request signature", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+		mockNonceDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject a stale timestamp before looking up the key", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockNonceDao := new(testhelpers.MockNonceDao)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, mockNonceDao, tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Add(-10*time.Minute).Unix(), "nonce-1"))
+
+		assert.Equal(t, "Request timestamp is outside the allowed window", err.Error())
+		assert.Nil(t, result)
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should reject a timestamp in the future beyond tolerance", func(t *testing.T) {
+		useCase := usecases.NewVerifySignature(new(testhelpers.MockApiKeyDao), new(testhelpers.MockNonceDao), tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Add(10*time.Minute).Unix(), "nonce-1"))
+
+		assert.Equal(t, "Request timestamp is outside the allowed window", err.Error())
+		assert.Nil(t, result)
+	})
+
+	t.Run("should reject missing or oversized nonce", func(t *testing.T) {
+		useCase := usecases.NewVerifySignature(new(testhelpers.MockApiKeyDao), new(testhelpers.MockNonceDao), tolerance)
+
+		_, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), ""))
+		assert.Equal(t, "Request nonce is missing or too long", err.Error())
+
+		_, err = useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), strings.Repeat("a", 65)))
+		assert.Equal(t, "Request nonce is missing or too long", err.Error())
+	})
+
+	t.Run("should reject unknown or revoked keys", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		revoked := apikey.NewApiKeyBuilder().WithId(2).WithSigningSecret("secret").WithRevokedAt(time.Now()).Build()
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(nil, apikey.ErrNotFound).Once()
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(revoked, nil).Once()
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, new(testhelpers.MockNonceDao), tolerance)
+
+		_, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+		assert.Equal(t, "Invalid request signature", err.Error())
+
+		_, err = useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+		assert.Equal(t, "Invalid request signature", err.Error())
+		mockApiKeyDao.AssertExpectations(t)
+	})
+
+	t.Run("should return error when nonce cannot be stored", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockNonceDao := new(testhelpers.MockNonceDao)
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(key, nil)
+		mockNonceDao.On("Register", int64(1), "nonce-1", mock.Anything).Return(false, assert.AnError)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, mockNonceDao, tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when key lookup fails", func(t *testing.T) {
+		mockApiKeyDao := new(testhelpers.MockApiKeyDao)
+		mockApiKeyDao.On("FindByPrefix", "abc123").Return(nil, assert.AnError)
+
+		useCase := usecases.NewVerifySignature(mockApiKeyDao, new(testhelpers.MockNonceDao), tolerance)
+		result, err := useCase.Execute(context.Background(), signedRequest("secret", time.Now().Unix(), "nonce-1"))
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+}
@@ -1,17 +1,286 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"payment-gateway/cmd/infra"
 	"payment-gateway/cmd/infra/conf"
+	"payment-gateway/cmd/infra/config"
+	"payment-gateway/cmd/infra/logging"
+	"payment-gateway/cmd/infra/server"
+	"payment-gateway/cmd/infra/tracing"
 )
 
+const usage = `usage:
+  payment-gateway [serve] [flags]
+  payment-gateway config print [--redacted] [flags]
+  payment-gateway migrate up|status [flags]
+  payment-gateway migrate down [steps] [flags]
+  payment-gateway audit verify [flags]`
+
 func main() {
-	r := gin.Default()
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 && !isFlag(args[0]) {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		return serve(args)
+	case "migrate":
+		if len(args) > 0 {
+			return migrate(args[0], args[1:])
+		}
+	case "audit":
+		if len(args) > 0 && args[0] == "verify" {
+			return verifyAudit(args[1:])
+		}
+	case "config":
+		if len(args) > 0 && args[0] == "print" {
+			return printConfig(args[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+func serve(args []string) int {
+	c, logger, ok := setup(args)
+	if !ok {
+		return 1
+	}
+	if err := c.RequireAuditSecret(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	provider, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  "payment-gateway",
+		Exporter:     c.Tracing.Exporter,
+		File:         c.Tracing.File,
+		OTLPEndpoint: c.Tracing.OTLPEndpoint,
+		OTLPInsecure: c.Tracing.OTLPInsecure,
+		SampleRatio:  c.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Error("failed to set up tracing", slog.String("error", err.Error()))
+		return 1
+	}
+	defer provider.Shutdown(context.Background())
 
-	c := infra.NewConfiguration()
-	run := conf.NewRuntime(c)
+	run, err := conf.NewRuntime(c, logger, provider)
+	if err != nil {
+		logger.Error("failed to start runtime", slog.String("error", err.Error()))
+		return 1
+	}
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(c.Server.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies", slog.String("error", err.Error()))
+		return 1
+	}
+	r.Use(gin.Recovery())
 	conf.Routes(r, run)
 
-	r.Run(":8080")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              c.Server.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: c.Server.ReadHeaderTimeout,
+		ReadTimeout:       c.Server.ReadTimeout,
+		WriteTimeout:      c.Server.WriteTimeout,
+		IdleTimeout:       c.Server.IdleTimeout,
+	}
+	err = server.Run(ctx, srv, run, server.Options{
+		ShutdownTimeout: c.Server.ShutdownTimeout,
+		PreStopDelay:    c.Server.PreStopDelay,
+		CertFile:        c.Server.TLS.CertFile,
+		KeyFile:         c.Server.TLS.KeyFile,
+	}, logger)
+	if err != nil {
+		logger.Error("server stopped with error", slog.String("error", err.Error()))
+		return 1
+	}
+
+	return 0
+}
+
+func migrate(command string, args []string) int {
+	steps := 1
+	if command == "down" && len(args) > 0 && !isFlag(args[0]) {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Fprintln(os.Stderr, "steps must be a positive integer")
+			return 2
+		}
+		steps, args = n, args[1:]
+	}
+	if command != "up" && command != "down" && command != "status" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	c, logger, ok := setup(args)
+	if !ok {
+		return 1
+	}
+
+	db, dialect, err := conf.OpenDatabase(c.Database, logger)
+	if err != nil {
+		logger.Error("failed to connect to database", slog.String("error", err.Error()))
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := conf.NewMigrator(db, dialect, c.Migrate, logger)
+	if err != nil {
+		logger.Error("failed to load migrations", slog.String("error", err.Error()))
+		return 1
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Error("migration failed", slog.String("error", err.Error()))
+			return 1
+		}
+		fmt.Printf("applied %d migration(s)\n", len(applied))
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Error("migration failed", slog.String("error", err.Error()))
+			return 1
+		}
+		fmt.Printf("reverted %d migration(s)\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("failed to read migration status", slog.String("error", err.Error()))
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tKIND\tAPPLIED AT")
+		for _, status := range statuses {
+			kind, appliedAt := "schema", "pending"
+			if status.Dev {
+				kind = "dev"
+			}
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, kind, appliedAt)
+		}
+		w.Flush()
+	}
+
+	return 0
+}
+
+// verifyAudit walks the audit log chain and compares the rows it records with
+// their last entries. It exits with 1 when a link is broken, printing the
+// first entry that does not follow the one before it, or when a row drifted
+// from its last entry.
+func verifyAudit(args []string) int {
+	c, logger, ok := setup(args)
+	if !ok {
+		return 1
+	}
+	if err := c.RequireAuditSecret(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db, dialect, err := conf.OpenDatabase(c.Database, logger)
+	if err != nil {
+		logger.Error("failed to connect to database", slog.String("error", err.Error()))
+		return 1
+	}
+	defer db.Close()
+
+	verification, err := conf.NewVerifyAuditLog(db, dialect, c.Audit.Secret).Execute(context.Background())
+	if err != nil {
+		logger.Error("failed to verify the audit log", slog.String("error", err.Error()))
+		return 1
+	}
+
+	if verification.Broken() {
+		fmt.Printf("audit log broken at entry %d: %s\n", verification.BrokenAt, verification.Reason)
+		fmt.Printf("%d entries verified before it\n", verification.Entries)
+		return 1
+	}
+	if verification.Drifted() {
+		for _, drift := range verification.Drifts {
+			fmt.Printf("%s %d changed outside the audit log: %s\n", drift.Entity, drift.EntityId, drift.Reason)
+		}
+		fmt.Printf("audit log chain intact: %d entries, but %d rows drifted from it\n", verification.Entries, len(verification.Drifts))
+		return 1
+	}
+	fmt.Printf("audit log intact: %d entries, head %d %s\n", verification.Entries, verification.LastSeq, verification.LastHash)
+
+	return 0
+}
+
+// setup loads the configuration and builds the logger, reporting problems on
+// stderr.
+func setup(args []string) (*config.Configuration, *slog.Logger, bool) {
+	c, err := config.Load(args, os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+
+	logger, err := logging.New(os.Stdout, c.Log.Level, c.Log.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+
+	return c, logger, true
+}
+
+func printConfig(args []string) int {
+	redacted := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--redacted" || arg == "-redacted" {
+			redacted = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+
+	c, err := config.Load(remaining, os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := config.Print(os.Stdout, c, redacted); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func isFlag(arg string) bool {
+	return len(arg) > 0 && arg[0] == '-'
 }
@@ -0,0 +1,73 @@
+package signature_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"payment-gateway/pkg/signature"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	t.Run("should produce a stable signature for the same payload", func(t *testing.T) {
+		first := signature.Sign("secret", "POST", "/payments", 1700000000, "nonce", []byte(`{"amount":10}`))
+		second := signature.Sign("secret", "POST", "/payments", 1700000000, "nonce", []byte(`{"amount":10}`))
+
+		assert.Equal(t, first, second)
+		assert.Len(t, first, 64)
+	})
+
+	t.Run("should change when any signed field changes", func(t *testing.T) {
+		base := signature.Sign("secret", "POST", "/payments", 1700000000, "nonce", []byte("body"))
+
+		assert.NotEqual(t, base, signature.Sign("other", "POST", "/payments", 1700000000, "nonce", []byte("body")))
+		assert.NotEqual(t, base, signature.Sign("secret", "GET", "/payments", 1700000000, "nonce", []byte("body")))
+		assert.NotEqual(t, base, signature.Sign("secret", "POST", "/payments/1/process", 1700000000, "nonce", []byte("body")))
+		assert.NotEqual(t, base, signature.Sign("secret", "POST", "/payments", 1700000001, "nonce", []byte("body")))
+		assert.NotEqual(t, base, signature.Sign("secret", "POST", "/payments", 1700000000, "other", []byte("body")))
+		assert.NotEqual(t, base, signature.Sign("secret", "POST", "/payments", 1700000000, "nonce", []byte("tampered")))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	sig := signature.Sign("secret", "POST", "/payments", 1700000000, "nonce", []byte("body"))
+
+	t.Run("should accept a valid signature", func(t *testing.T) {
+		assert.True(t, signature.Verify("secret", sig, "POST", "/payments", 1700000000, "nonce", []byte("body")))
+	})
+
+	t.Run("should reject a tampered body", func(t *testing.T) {
+		assert.False(t, signature.Verify("secret", sig, "POST", "/payments", 1700000000, "nonce", []byte("other")))
+	})
+}
+
+func TestSignRequest(t *testing.T) {
+	t.Run("should set headers and keep the body readable", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/payments", bytes.NewBufferString(`{"amount":10}`))
+
+		err := signature.SignRequest(req, "abc123", "secret")
+
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, `{"amount":10}`, string(body))
+		assert.Equal(t, "abc123", req.Header.Get(signature.HeaderKeyId))
+		assert.NotEmpty(t, req.Header.Get(signature.HeaderNonce))
+
+		timestamp, err := strconv.ParseInt(req.Header.Get(signature.HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.True(t, signature.Verify("secret", req.Header.Get(signature.HeaderSignature), http.MethodPost, "/payments",
+			timestamp, req.Header.Get(signature.HeaderNonce), body))
+	})
+
+	t.Run("should sign requests without body", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/payments/1/process", nil)
+
+		err := signature.SignRequest(req, "abc123", "secret")
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, req.Header.Get(signature.HeaderSignature))
+	})
+}
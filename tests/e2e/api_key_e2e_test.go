@@ -0,0 +1,104 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ApiKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	Mode   string   `json:"mode"`
+}
+
+type ApiKeyResponse struct {
+	ID      int64    `json:"id"`
+	Key     string   `json:"key"`
+	Prefix  string   `json:"prefix"`
+	Scopes  []string `json:"scopes"`
+	Mode    string   `json:"mode"`
+	Revoked bool     `json:"revoked"`
+}
+
+func requestWithKey(method string, url string, key string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	client := &http.Client{}
+	return client.Do(req)
+}
+
+func TestApiKeyFlow(t *testing.T) {
+	var created ApiKeyResponse
+
+	t.Run("should reject requests without api key", func(t *testing.T) {
+		resp, err := requestWithKey(http.MethodGet, fmt.Sprintf("%s/orders/4", baseURL), "")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should create a read only api key", func(t *testing.T) {
+		reqBody, err := json.Marshal(ApiKeyRequest{Name: "storefront", Scopes: []string{"read"}, Mode: "test"})
+		require.NoError(t, err)
+
+		resp, err := doRequest(http.MethodPost, fmt.Sprintf("%s/api-keys", baseURL), reqBody)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		err = json.NewDecoder(resp.Body).Decode(&created)
+		require.NoError(t, err)
+
+		assert.NotZero(t, created.ID)
+		assert.NotEmpty(t, created.Key)
+		assert.Equal(t, []string{"read"}, created.Scopes)
+	})
+
+	t.Run("should read an order with the new key", func(t *testing.T) {
+		resp, err := requestWithKey(http.MethodGet, fmt.Sprintf("%s/orders/4", baseURL), created.Key)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should forbid writes with a read only key", func(t *testing.T) {
+		resp, err := requestWithKey(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), created.Key)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("should revoke the key", func(t *testing.T) {
+		resp, err := doRequest(http.MethodDelete, fmt.Sprintf("%s/api-keys/%d", baseURL, created.ID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should reject the revoked key", func(t *testing.T) {
+		resp, err := requestWithKey(http.MethodGet, fmt.Sprintf("%s/orders/4", baseURL), created.Key)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,58 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFound(t *testing.T) {
+	missingID := int64(999999)
+
+	assertProblem := func(t *testing.T, resp *http.Response, code string) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		var problem ProblemResponse
+		err := json.NewDecoder(resp.Body).Decode(&problem)
+		require.NoError(t, err)
+
+		assert.Equal(t, code, problem.Code)
+	}
+
+	t.Run("should return 404 for a missing order", func(t *testing.T) {
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d", baseURL, missingID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assertProblem(t, resp, "order_not_found")
+	})
+
+	t.Run("should return 404 when paying a missing order", func(t *testing.T) {
+		reqBody, err := json.Marshal(PaymentRequest{OrderID: missingID, Amount: 10, PaymentType: "Cash"})
+		require.NoError(t, err)
+
+		resp, err := doRequest(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), reqBody)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assertProblem(t, resp, "order_not_found")
+	})
+
+	t.Run("should return 404 when processing a missing payment", func(t *testing.T) {
+		reqBody, err := json.Marshal(ProcessPaymentRequest{Type: "Success", Details: "approved details"})
+		require.NoError(t, err)
+
+		resp, err := doRequest(http.MethodPost, fmt.Sprintf("%s/payments/%d/process", baseURL, missingID), reqBody)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assertProblem(t, resp, "payment_not_found")
+	})
+}
@@ -10,18 +10,37 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 var baseURL string
+var apiKey string
 
 func init() {
 	baseURL = os.Getenv("API_BASE_URL")
 	if baseURL == "" {
 		baseURL = "http://localhost:8080"
 	}
+
+	apiKey = os.Getenv("API_KEY")
+	if apiKey == "" {
+		apiKey = "pgw_test_devbootstrap000000000000000000000000000000000000"
+	}
+}
+
+func doRequest(method string, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	client := &http.Client{}
+	return client.Do(req)
 }
 
 type PaymentRequest struct {
@@ -37,6 +56,15 @@ type PaymentResponse struct {
 	PaymentType string `json:"type"`
 }
 
+type PaymentDetailsResponse struct {
+	ID      int64   `json:"id"`
+	OrderID int64   `json:"order_id"`
+	Status  string  `json:"status"`
+	Type    string  `json:"type"`
+	Amount  float64 `json:"amount"`
+	Details string  `json:"details"`
+}
+
 type PaymentProcessedResponse struct {
 	ID      int64  `json:"payment_id"`
 	Type    string `json:"type"`
@@ -57,14 +85,33 @@ type OrderResponse struct {
 }
 
 type CashoutResponse struct {
-	CashedDebt    float64 `json:"cashed_debt"`
-	RemainingDebt float64 `json:"remaining_debt"`
-	Charges       float64 `json:"charges"`
-	IsPaid        bool    `json:"is_paid"`
+	CashedDebt        float64            `json:"cashed_debt"`
+	RemainingDebt     float64            `json:"remaining_debt"`
+	Charges           float64            `json:"charges"`
+	IsPaid            bool               `json:"is_paid"`
+	NetAmount         float64            `json:"net_amount"`
+	ChargesByCategory map[string]float64 `json:"charges_by_category"`
+	PaidByType        map[string]float64 `json:"paid_by_type"`
+	PaymentsByStatus  map[string]int     `json:"payments_by_status"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+type ProblemResponse struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+	Code     string `json:"code"`
+}
+
+type TimelineResponse struct {
+	Timeline []struct {
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Reason    string `json:"reason"`
+		Actor     string `json:"actor"`
+		RequestID string `json:"request_id"`
+	} `json:"timeline"`
 }
 
 func TestPaymentTotalPaidFlow(t *testing.T) {
@@ -72,6 +119,7 @@ func TestPaymentTotalPaidFlow(t *testing.T) {
 	amount := 120.5
 	paymentType := "CreditCard"
 	var paymentID int64
+	beforePayments := time.Now().UTC()
 
 	t.Run("should create a payment", func(t *testing.T) {
 		paymentReq := PaymentRequest{
@@ -84,7 +132,7 @@ func TestPaymentTotalPaidFlow(t *testing.T) {
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("%s/payments", baseURL)
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+		resp, err := doRequest(http.MethodPost, url, reqBody)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -110,12 +158,7 @@ func TestPaymentTotalPaidFlow(t *testing.T) {
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("%s/payments/%d/process", baseURL, paymentID)
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(reqBody))
-		require.NoError(t, err)
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := doRequest(http.MethodPost, url, reqBody)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -135,7 +178,7 @@ func TestPaymentTotalPaidFlow(t *testing.T) {
 		require.NotZero(t, orderID, "orderID should be set")
 
 		url := fmt.Sprintf("%s/orders/%d", baseURL, orderID)
-		resp, err := http.Get(url)
+		resp, err := doRequest(http.MethodGet, url, nil)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -151,8 +194,141 @@ func TestPaymentTotalPaidFlow(t *testing.T) {
 		assert.Equal(t, 0.0, orderResp.Cashout.RemainingDebt)
 		assert.Equal(t, 12.05, orderResp.Cashout.Charges)
 		assert.Equal(t, true, orderResp.Cashout.IsPaid)
+		assert.InDelta(t, 108.45, orderResp.Cashout.NetAmount, 0.001)
+		assert.Equal(t, map[string]float64{"financial_fee": 12.05}, orderResp.Cashout.ChargesByCategory)
+		assert.Equal(t, map[string]float64{"CreditCard": 120.5}, orderResp.Cashout.PaidByType)
+		assert.Equal(t, map[string]int{"approved": 1}, orderResp.Cashout.PaymentsByStatus)
 		assert.Equal(t, "paid", orderResp.Status)
 	})
+
+	t.Run("should get the order as it was before its payments", func(t *testing.T) {
+		url := fmt.Sprintf("%s/orders/%d?as_of=%s", baseURL, orderID, beforePayments.Format(time.RFC3339Nano))
+		resp, err := doRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var orderResp OrderResponse
+		err = json.NewDecoder(resp.Body).Decode(&orderResp)
+		require.NoError(t, err)
+
+		assert.Equal(t, "pending", orderResp.Status)
+		assert.Equal(t, 0.0, orderResp.Cashout.CashedDebt)
+		assert.Equal(t, 120.5, orderResp.Cashout.RemainingDebt)
+		assert.Equal(t, 0.0, orderResp.Cashout.Charges)
+		assert.Empty(t, orderResp.Cashout.PaymentsByStatus)
+	})
+
+	t.Run("should not find the order before it was created", func(t *testing.T) {
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d?as_of=2000-01-01T00:00:00Z", baseURL, orderID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should read the processed payment", func(t *testing.T) {
+		require.NotZero(t, paymentID, "paymentID should be set from create test")
+
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/payments/%d", baseURL, paymentID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var paymentResp PaymentDetailsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&paymentResp))
+		assert.Equal(t, paymentID, paymentResp.ID)
+		assert.Equal(t, "approved", paymentResp.Status)
+		assert.Equal(t, amount, paymentResp.Amount)
+	})
+
+	t.Run("should find the payment searching by order and status", func(t *testing.T) {
+		require.NotZero(t, paymentID, "paymentID should be set from create test")
+
+		url := fmt.Sprintf("%s/payments?order_id=%d&status=approved&type=%s&limit=1", baseURL, orderID, paymentType)
+		resp, err := doRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page struct {
+			Payments   []PaymentDetailsResponse `json:"payments"`
+			NextCursor *string                  `json:"next_cursor"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		if assert.Len(t, page.Payments, 1) {
+			assert.Equal(t, paymentID, page.Payments[0].ID)
+		}
+		assert.Nil(t, page.NextCursor)
+	})
+
+	t.Run("should list the payments and charges of the order", func(t *testing.T) {
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d/payments", baseURL, orderID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var payments struct {
+			Payments []PaymentDetailsResponse `json:"payments"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payments))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		if assert.Len(t, payments.Payments, 1) {
+			assert.Equal(t, paymentID, payments.Payments[0].ID)
+		}
+
+		resp, err = doRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d/charges", baseURL, orderID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var charges struct {
+			Charges []struct {
+				PaymentID int64   `json:"payment_id"`
+				Amount    float64 `json:"amount"`
+			} `json:"charges"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&charges))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		if assert.Len(t, charges.Charges, 1) {
+			assert.Equal(t, paymentID, charges.Charges[0].PaymentID)
+			assert.Equal(t, 12.05, charges.Charges[0].Amount)
+		}
+	})
+
+	t.Run("should record who approved the payment and paid the order", func(t *testing.T) {
+		require.NotZero(t, paymentID, "paymentID should be set from create test")
+
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/payments/%d/timeline", baseURL, paymentID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var payment TimelineResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payment))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		if assert.Len(t, payment.Timeline, 1) {
+			assert.Equal(t, "pending", payment.Timeline[0].From)
+			assert.Equal(t, "approved", payment.Timeline[0].To)
+			assert.Equal(t, "approved details", payment.Timeline[0].Reason)
+			assert.Contains(t, payment.Timeline[0].Actor, "api_key:")
+			assert.NotEmpty(t, payment.Timeline[0].RequestID)
+		}
+
+		resp, err = doRequest(http.MethodGet, fmt.Sprintf("%s/orders/%d/timeline", baseURL, orderID), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var order TimelineResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&order))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		if assert.NotEmpty(t, order.Timeline) {
+			last := order.Timeline[len(order.Timeline)-1]
+			assert.Equal(t, "pending", last.From)
+			assert.Equal(t, "paid", last.To)
+			assert.Equal(t, fmt.Sprintf("payment %d approved", paymentID), last.Reason)
+		}
+	})
 }
 
 func TestPaymentNonTotalPaidFlow(t *testing.T) {
@@ -172,7 +348,7 @@ func TestPaymentNonTotalPaidFlow(t *testing.T) {
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("%s/payments", baseURL)
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+		resp, err := doRequest(http.MethodPost, url, reqBody)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -198,12 +374,7 @@ func TestPaymentNonTotalPaidFlow(t *testing.T) {
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("%s/payments/%d/process", baseURL, paymentID)
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(reqBody))
-		require.NoError(t, err)
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := doRequest(http.MethodPost, url, reqBody)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -223,7 +394,7 @@ func TestPaymentNonTotalPaidFlow(t *testing.T) {
 		require.NotZero(t, orderID, "orderID should be set")
 
 		url := fmt.Sprintf("%s/orders/%d", baseURL, orderID)
-		resp, err := http.Get(url)
+		resp, err := doRequest(http.MethodGet, url, nil)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
@@ -259,16 +430,19 @@ func TestPaymentFlowError(t *testing.T) {
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("%s/payments", baseURL)
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
+		resp, err := doRequest(http.MethodPost, url, reqBody)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
 
-		var errorResponse ErrorResponse
-		err = json.NewDecoder(resp.Body).Decode(&errorResponse)
+		var problem ProblemResponse
+		err = json.NewDecoder(resp.Body).Decode(&problem)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Payment exceeds debt", errorResponse.Error)
+		assert.Equal(t, "payment_exceeds_debt", problem.Code)
+		assert.Equal(t, "Payment exceeds debt", problem.Detail)
+		assert.Equal(t, "/payments", problem.Instance)
 	})
 }
@@ -0,0 +1,57 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentMethods(t *testing.T) {
+	t.Run("should list the payment methods enabled for the merchant", func(t *testing.T) {
+		resp, err := doRequest(http.MethodGet, fmt.Sprintf("%s/payment-methods", baseURL), nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			PaymentMethods []struct {
+				Code        string  `json:"code"`
+				FeeCategory string  `json:"fee_category"`
+				MinTicket   float64 `json:"min_ticket"`
+				MaxTicket   float64 `json:"max_ticket"`
+			} `json:"payment_methods"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		require.NoError(t, err)
+
+		codes := []string{}
+		for _, method := range body.PaymentMethods {
+			codes = append(codes, method.Code)
+		}
+		assert.ElementsMatch(t, []string{"CreditCard", "CashSlip", "Cash"}, codes)
+	})
+
+	t.Run("should reject a payment below the method minimum ticket", func(t *testing.T) {
+		reqBody, err := json.Marshal(PaymentRequest{OrderID: 2, Amount: 1, PaymentType: "CashSlip"})
+		require.NoError(t, err)
+
+		resp, err := doRequest(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), reqBody)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var problem ProblemResponse
+		err = json.NewDecoder(resp.Body).Decode(&problem)
+		require.NoError(t, err)
+		assert.Equal(t, "payment_method_not_eligible", problem.Code)
+	})
+}
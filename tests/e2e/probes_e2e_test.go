@@ -0,0 +1,43 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbes(t *testing.T) {
+	t.Run("should report the process as alive", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/livez")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should report every readiness check as ok", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/readyz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var report struct {
+			Status string `json:"status"`
+			Checks map[string]struct {
+				Status string `json:"status"`
+			} `json:"checks"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ok", report.Status)
+		for _, name := range []string{"database", "schema", "purge-expired-nonces"} {
+			assert.Equal(t, "ok", report.Checks[name].Status, name)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"payment-gateway/pkg/signature"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signingCredentials() (string, string) {
+	keyId := os.Getenv("API_KEY_ID")
+	if keyId == "" {
+		keyId = "devbootstrap"
+	}
+
+	secret := os.Getenv("API_SIGNING_SECRET")
+	if secret == "" {
+		secret = "pgw_sig_devbootstrap"
+	}
+
+	return keyId, secret
+}
+
+func TestSignedPaymentFlow(t *testing.T) {
+	keyId, secret := signingCredentials()
+	reqBody, err := json.Marshal(PaymentRequest{OrderID: 5, Amount: 10, PaymentType: "Cash"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), bytes.NewBuffer(reqBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	require.NoError(t, signature.SignRequest(req, keyId, secret))
+
+	t.Run("should create a payment with a signed request", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("should reject a replay of the same signed request", func(t *testing.T) {
+		replay, err := http.NewRequest(http.MethodPost, req.URL.String(), bytes.NewBuffer(reqBody))
+		require.NoError(t, err)
+		replay.Header = req.Header.Clone()
+
+		resp, err := http.DefaultClient.Do(replay)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should reject a tampered body", func(t *testing.T) {
+		tamperedBody, err := json.Marshal(PaymentRequest{OrderID: 5, Amount: 20, PaymentType: "Cash"})
+		require.NoError(t, err)
+
+		signed, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), bytes.NewBuffer(reqBody))
+		require.NoError(t, err)
+		require.NoError(t, signature.SignRequest(signed, keyId, secret))
+
+		tampered, err := http.NewRequest(http.MethodPost, signed.URL.String(), bytes.NewBuffer(tamperedBody))
+		require.NoError(t, err)
+		tampered.Header = signed.Header.Clone()
+
+		resp, err := http.DefaultClient.Do(tampered)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,48 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentValidation(t *testing.T) {
+	t.Run("should reject an invalid payment listing every field", func(t *testing.T) {
+		reqBody, err := json.Marshal(PaymentRequest{OrderID: 0, Amount: -1, PaymentType: "credit_card"})
+		require.NoError(t, err)
+
+		resp, err := doRequest(http.MethodPost, fmt.Sprintf("%s/payments", baseURL), reqBody)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var problem struct {
+			ProblemResponse
+			Errors []struct {
+				Field string `json:"field"`
+				Code  string `json:"code"`
+			} `json:"errors"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&problem)
+		require.NoError(t, err)
+
+		assert.Equal(t, "validation_failed", problem.Code)
+		fields := map[string]string{}
+		for _, fieldError := range problem.Errors {
+			fields[fieldError.Field] = fieldError.Code
+		}
+		assert.Equal(t, map[string]string{
+			"order_id":     "required",
+			"amount":       "gt",
+			"payment_type": "payment_type",
+		}, fields)
+	})
+}
@@ -0,0 +1,27 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"payment-gateway/cmd/infra/dao"
+	"payment-gateway/cmd/infra/dao/daotest"
+	dbclient "payment-gateway/cmd/infra/db"
+	"testing"
+)
+
+func TestDaoConformance(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		daotest.Run(t, func(t *testing.T) daotest.Fixture {
+			return daotest.Fixture{
+				Payments:    dao.NewPaymentDao(e.client(), e.dialect),
+				Orders:      dao.NewOrderDao(e.client(), e.dialect),
+				Charges:     dao.NewChargeDao(e.client(), e.dialect),
+				Audit:       dao.NewAuditDao(e.client(), e.dialect, daotest.AuditKey),
+				Transaction: dbclient.NewTransactor(e.db).Transaction,
+				NewMerchant: e.insertMerchant,
+				NewOrder:    e.insertOrder,
+			}
+		})
+	})
+}
@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"payment-gateway/cmd/domain/apikey"
+	"payment-gateway/cmd/domain/charge"
+	"payment-gateway/cmd/domain/order"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/dao"
+	"payment-gateway/cmd/infra/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentDao(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		orderId := e.insertOrder(t, e.insertMerchant(t), 300)
+		paymentDao := dao.NewPaymentDao(e.client(), e.dialect)
+
+		created, err := paymentDao.Insert(ctx, payment.NewPayment(orderId, 120.5, "CreditCard"))
+		require.NoError(t, err)
+		assert.NotZero(t, created.Id())
+
+		require.NoError(t, created.Process("Success", ""))
+		_, err = paymentDao.Update(ctx, created)
+		require.NoError(t, err)
+
+		found, err := paymentDao.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, orderId, found.OrderID())
+		assert.Equal(t, 120.5, found.Amount())
+		assert.Equal(t, "approved", found.Status())
+
+		byOrder, err := paymentDao.FindByOrderId(ctx, orderId)
+		require.NoError(t, err)
+		assert.Len(t, byOrder, 1)
+
+		_, err = paymentDao.FindById(ctx, 999999)
+		assert.ErrorIs(t, err, payment.ErrNotFound)
+	})
+}
+
+func TestChargeDao(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		orderId := e.insertOrder(t, e.insertMerchant(t), 100)
+		pay, err := dao.NewPaymentDao(e.client(), e.dialect).Insert(ctx, payment.NewPayment(orderId, 100, "CashSlip"))
+		require.NoError(t, err)
+		require.NoError(t, pay.Process("Success", ""))
+		chargeDao := dao.NewChargeDao(e.client(), e.dialect)
+
+		fee, ok := charge.NewCharge(*pay)
+		require.True(t, ok)
+		created, err := chargeDao.Insert(ctx, fee)
+		require.NoError(t, err)
+
+		found, err := chargeDao.FindById(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, pay.Id(), found.PaymentId())
+		assert.Equal(t, fee.Amount(), found.Amount())
+
+		byOrder, err := chargeDao.FindByOrderId(ctx, orderId)
+		require.NoError(t, err)
+		assert.Len(t, byOrder, 1)
+
+		_, err = chargeDao.FindById(ctx, 999999)
+		assert.ErrorIs(t, err, charge.ErrNotFound)
+	})
+}
+
+func TestOrderDao(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		orderDao := dao.NewOrderDao(e.client(), e.dialect)
+		id := e.insertOrder(t, e.insertMerchant(t), 80)
+
+		found, err := orderDao.FindById(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 80.0, found.Amount())
+
+		found.SetStatus("paid")
+		_, err = orderDao.Update(ctx, found)
+		require.NoError(t, err)
+
+		updated, err := orderDao.FindById(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, updated.IsPaid())
+
+		_, err = orderDao.FindById(ctx, 999999)
+		assert.ErrorIs(t, err, order.ErrNotFound)
+	})
+}
+
+func TestApiKeyDao(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		merchantId := e.insertMerchant(t)
+		apiKeyDao := dao.NewApiKeyDao(e.client(), e.dialect)
+		prefix := fmt.Sprintf("%012d", time.Now().UnixNano()%1e12)
+
+		key := apikey.NewApiKeyBuilder().
+			WithMerchantId(merchantId).
+			WithName("integration").
+			WithPrefix(prefix).
+			WithHash(fmt.Sprintf("%064s", prefix)).
+			WithSigningSecret("secret").
+			WithScopes("read", "write").
+			WithMode("test").
+			WithCreatedAt(time.Now()).
+			WithUpdatedAt(time.Now()).
+			Build()
+		created, err := apiKeyDao.Insert(ctx, key)
+		require.NoError(t, err)
+
+		require.NoError(t, apiKeyDao.TouchLastUsed(ctx, created.Id(), time.Now()))
+
+		found, err := apiKeyDao.FindByPrefix(ctx, prefix)
+		require.NoError(t, err)
+		assert.Equal(t, merchantId, found.MerchantId())
+		assert.False(t, found.LastUsedAt().IsZero())
+
+		_, err = apiKeyDao.FindByPrefix(ctx, "missing")
+		assert.ErrorIs(t, err, apikey.ErrNotFound)
+
+		nonceDao := dao.NewNonceDao(e.client(), e.dialect)
+		first, err := nonceDao.Register(ctx, created.Id(), "nonce-1", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		replayed, err := nonceDao.Register(ctx, created.Id(), "nonce-1", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		assert.True(t, first)
+		assert.False(t, replayed)
+
+		purged, err := nonceDao.PurgeExpired(ctx, time.Now())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		store := ratelimit.NewSQLStore(e.db, e.dialect)
+		limit := ratelimit.Limit{Rate: 1, Burst: 2}
+		key := fmt.Sprintf("integration:%d", time.Now().UnixNano())
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		buckets := []ratelimit.Bucket{{Key: key, Limit: limit}}
+
+		first, err := store.Take(context.Background(), buckets, now)
+		require.NoError(t, err)
+		second, err := store.Take(context.Background(), buckets, now)
+		require.NoError(t, err)
+		third, err := store.Take(context.Background(), buckets, now)
+		require.NoError(t, err)
+
+		assert.True(t, first[0].Allowed)
+		assert.True(t, second[0].Allowed)
+		assert.False(t, third[0].Allowed)
+	})
+}
@@ -0,0 +1,181 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"payment-gateway/cmd/infra/conf"
+	"payment-gateway/cmd/infra/config"
+	dbclient "payment-gateway/cmd/infra/db"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// engine is a database started for the tests, migrated to the latest schema.
+type engine struct {
+	name    string
+	db      *sql.DB
+	dialect dbclient.Dialect
+}
+
+var engines []engine
+
+type container struct {
+	driver     string
+	repository string
+	tag        string
+	env        []string
+	port       string
+	user       string
+	password   string
+	name       string
+}
+
+var containers = []container{
+	{
+		driver:     dbclient.DriverMySQL,
+		repository: "mysql",
+		tag:        "8.0",
+		env:        []string{"MYSQL_ROOT_PASSWORD=secret", "MYSQL_DATABASE=payment_gateway"},
+		port:       "3306/tcp",
+		user:       "root",
+		password:   "secret",
+		name:       "payment_gateway",
+	},
+	{
+		driver:     dbclient.DriverPostgres,
+		repository: "postgres",
+		tag:        "16-alpine",
+		env:        []string{"POSTGRES_PASSWORD=secret", "POSTGRES_DB=payment_gateway"},
+		port:       "5432/tcp",
+		user:       "postgres",
+		password:   "secret",
+		name:       "payment_gateway",
+	},
+}
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("could not connect to docker: %v", err)
+	}
+	pool.MaxWait = 2 * time.Minute
+
+	logger := slog.New(slog.DiscardHandler)
+	var resources []*dockertest.Resource
+	purge := func() {
+		for _, resource := range resources {
+			pool.Purge(resource)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "payment-gateway")
+	if err != nil {
+		log.Fatalf("could not create the sqlite directory: %v", err)
+	}
+	cfg := config.Defaults().Database
+	cfg.Driver = dbclient.DriverSQLite
+	cfg.Path = filepath.Join(dir, "payment-gateway.db")
+	db, dialect, err := conf.OpenDatabase(cfg, logger)
+	if err == nil {
+		err = migrate(db, dialect, logger)
+	}
+	if err != nil {
+		log.Fatalf("could not prepare sqlite: %v", err)
+	}
+	engines = append(engines, engine{name: cfg.Driver, db: db, dialect: dialect})
+
+	for _, c := range containers {
+		resource, err := pool.RunWithOptions(&dockertest.RunOptions{Repository: c.repository, Tag: c.tag, Env: c.env},
+			func(hc *docker.HostConfig) {
+				hc.AutoRemove = true
+				hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+			})
+		if err != nil {
+			purge()
+			log.Fatalf("could not start %s: %v", c.repository, err)
+		}
+		resources = append(resources, resource)
+
+		port, _ := strconv.Atoi(resource.GetPort(c.port))
+		cfg := config.Defaults().Database
+		cfg.Driver = c.driver
+		cfg.Host = "localhost"
+		cfg.Port = port
+		cfg.User = c.user
+		cfg.Password = c.password
+		cfg.Name = c.name
+		cfg.Retry = config.Retry{Attempts: 60, Interval: 2 * time.Second}
+
+		db, dialect, err := conf.OpenDatabase(cfg, logger)
+		if err == nil {
+			err = migrate(db, dialect, logger)
+		}
+		if err != nil {
+			purge()
+			log.Fatalf("could not prepare %s: %v", c.driver, err)
+		}
+		engines = append(engines, engine{name: c.driver, db: db, dialect: dialect})
+	}
+
+	code := m.Run()
+	for _, e := range engines {
+		e.db.Close()
+	}
+	purge()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func migrate(db *sql.DB, dialect dbclient.Dialect, logger *slog.Logger) error {
+	migrator, err := conf.NewMigrator(db, dialect, config.Migrate{LockTimeout: time.Minute}, logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(context.Background())
+	return err
+}
+
+// each runs the test once for every engine.
+func each(t *testing.T, test func(t *testing.T, e engine)) {
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) { test(t, e) })
+	}
+}
+
+func (e engine) client() dbclient.Client {
+	return dbclient.NewReboundClient(dbclient.NewTxClient(e.db), e.dialect)
+}
+
+func (e engine) insertOrder(t *testing.T, merchantId int64, amount float64) int64 {
+	id, err := e.dialect.Insert(context.Background(), e.client(),
+		"INSERT INTO orders (merchant_id, status, amount, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		merchantId, "pending", amount, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("insert order: %v", err)
+	}
+
+	return id
+}
+
+func (e engine) insertMerchant(t *testing.T) int64 {
+	id, err := e.dialect.Insert(context.Background(), e.client(), "INSERT INTO merchants (name) VALUES (?)",
+		fmt.Sprintf("merchant-%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatalf("insert merchant: %v", err)
+	}
+
+	return id
+}
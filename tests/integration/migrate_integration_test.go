@@ -0,0 +1,49 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/infra/conf"
+	"payment-gateway/cmd/infra/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		logger := slog.New(slog.DiscardHandler)
+		migrator, err := conf.NewMigrator(e.db, e.dialect, config.Migrate{LockTimeout: time.Minute}, logger)
+		require.NoError(t, err)
+
+		require.NoError(t, migrator.Check(ctx))
+
+		reverted, err := migrator.Down(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, reverted, 1)
+		assert.Error(t, migrator.Check(ctx))
+
+		var wg sync.WaitGroup
+		applied := make([]int, 3)
+		for i := range applied {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				migrations, err := migrator.Up(ctx)
+				assert.NoError(t, err)
+				applied[i] = len(migrations)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, applied[0]+applied[1]+applied[2])
+		assert.NoError(t, migrator.Check(ctx))
+	})
+}
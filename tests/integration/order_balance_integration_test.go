@@ -0,0 +1,83 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"payment-gateway/cmd/domain/payment"
+	"payment-gateway/cmd/infra/dao"
+	dbclient "payment-gateway/cmd/infra/db"
+	"payment-gateway/cmd/testhelpers"
+	"payment-gateway/cmd/usecases"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBalance(t *testing.T) {
+	each(t, func(t *testing.T, e engine) {
+		ctx := context.Background()
+		paymentDao := dao.NewPaymentDao(e.client(), e.dialect)
+		orderDao := dao.NewOrderDao(e.client(), e.dialect)
+		process := usecases.NewProcessPayment(paymentDao, dao.NewChargeDao(e.client(), e.dialect), orderDao,
+			dbclient.NewTransactor(e.db), slog.New(slog.DiscardHandler), testhelpers.NewNopPaymentMetrics())
+		insertPayment := func(t *testing.T, merchantId int64, orderId int64, amount float64) *payment.Entity {
+			pay := payment.NewPayment(orderId, amount, "CreditCard")
+			pay.SetMerchantId(merchantId)
+			pay, err := paymentDao.Insert(ctx, pay)
+			require.NoError(t, err)
+
+			return pay
+		}
+
+		t.Run("should keep the balance when payments are processed concurrently", func(t *testing.T) {
+			merchantId := e.insertMerchant(t)
+			orderId := e.insertOrder(t, merchantId, 200)
+			var ids []int64
+			for range 20 {
+				ids = append(ids, insertPayment(t, merchantId, orderId, 10).Id())
+			}
+
+			var wg sync.WaitGroup
+			for _, id := range ids {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.NoError(t, process.Execute(ctx, merchantId, id, "Success", ""))
+				}()
+			}
+			wg.Wait()
+
+			or, err := orderDao.FindById(ctx, orderId)
+			require.NoError(t, err)
+			assert.True(t, or.IsPaid())
+			assert.Equal(t, 200.0, or.PaidAmount())
+
+			drifts, _, err := orderDao.FindBalanceDrift(ctx, orderId-1, 1)
+			require.NoError(t, err)
+			assert.Empty(t, drifts)
+		})
+
+		t.Run("should leave the payment pending when it exceeds the remaining debt", func(t *testing.T) {
+			merchantId := e.insertMerchant(t)
+			orderId := e.insertOrder(t, merchantId, 50)
+			pay := insertPayment(t, merchantId, orderId, 30)
+			other := insertPayment(t, merchantId, orderId, 30)
+			require.NoError(t, process.Execute(ctx, merchantId, pay.Id(), "Success", ""))
+
+			err := process.Execute(ctx, merchantId, other.Id(), "Success", "")
+
+			assert.Equal(t, "Payment exceeds debt", err.Error())
+			stored, err := paymentDao.FindById(ctx, other.Id())
+			require.NoError(t, err)
+			assert.Equal(t, "pending", stored.Status())
+			or, err := orderDao.FindById(ctx, orderId)
+			require.NoError(t, err)
+			assert.Equal(t, 30.0, or.PaidAmount())
+		})
+	})
+}